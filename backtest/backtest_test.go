@@ -0,0 +1,148 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/dfmanager/dffile"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/streammanager"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// sliceFeed 按顺序异步推送固定成交数据
+type sliceFeed struct {
+	dfmanager.DataFeedManager
+	trades []*exchange.TradeEvent
+}
+
+func (s *sliceFeed) AddDataFeed(req *dfmanager.DataFeedRequest) error {
+	go func() {
+		for _, te := range s.trades {
+			evt := *te
+			req.Event(&evt)
+		}
+		req.ErrorHandler(dffile.ErrCsvFileFinished)
+	}()
+	return nil
+}
+
+func (s *sliceFeed) CloseDataFeed(id string) error {
+	return nil
+}
+
+func trades(start int64, prices ...int64) []*exchange.TradeEvent {
+	list := make([]*exchange.TradeEvent, 0, len(prices))
+	for i, p := range prices {
+		list = append(list, &exchange.TradeEvent{
+			TradedAt: start + int64(i)*time.Hour.Milliseconds(),
+			Price:    decimal.NewFromInt(p),
+			Size:     decimal.NewFromInt(100),
+		})
+	}
+	return list
+}
+
+// buyThenSell 首笔成交买入，价格达到 110 后卖出
+type buyThenSell struct {
+	env    *Env
+	seen   []string
+	events []*exchange.OrderResultEvent
+	sold   bool
+}
+
+func (b *buyThenSell) Start(ctx context.Context, env *Env) error {
+	b.env = env
+	_, err := env.Stream.AddStream(&streammanager.StreamRequest{
+		MarketType: exchange.MarketTypeSpot,
+		OrderEvent: func(evt *exchange.OrderResultEvent) {
+			b.events = append(b.events, evt)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return env.DataFeed.AddDataFeed(&dfmanager.DataFeedRequest{
+		Symbol:     "BTCUSDT",
+		MarketType: exchange.MarketTypeSpot,
+		Event: func(te *exchange.TradeEvent) {
+			b.seen = append(b.seen, te.Symbol)
+			if len(b.seen) == 1 {
+				b.order("buy", exchange.SideTypeBuy)
+			}
+			if te.Price.GreaterThanOrEqual(decimal.NewFromInt(110)) && !b.sold {
+				b.sold = true
+				b.order("sell", exchange.SideTypeSell)
+			}
+		},
+	})
+}
+
+func (b *buyThenSell) order(id string, side exchange.SideType) {
+	_, _ = b.env.Exchange.CreateOrder(context.Background(), &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: id,
+		Side:          side,
+		OrderType:     exchange.OrderTypeMarket,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+	})
+}
+
+func (b *buyThenSell) Stop() error {
+	return nil
+}
+
+func TestEngineRun(t *testing.T) {
+	start := int64(1700000000000)
+	e := NewEngine(
+		WithDataFeed("BTCUSDT", exchange.MarketTypeSpot, &sliceFeed{trades: trades(start, 100, 90, 110, 105)}),
+		WithDataFeed("ETHUSDT", exchange.MarketTypeSpot, &sliceFeed{trades: trades(start+1, 10, 11)}),
+		WithBalance(exchange.MarketTypeSpot, "USDT", decimal.NewFromInt(1000)),
+		WithExchangeOptions(),
+	)
+	s := &buyThenSell{}
+	report, err := e.Run(context.Background(), s)
+	assert.NoError(t, err)
+
+	assert.Len(t, s.seen, 4)
+	assert.Len(t, s.events, 4)
+	assert.Len(t, report.Trades, 2)
+	assert.Equal(t, 1, report.ClosedTrades)
+	assert.Equal(t, float64(1), report.WinRate)
+	assert.Len(t, report.EquityCurve, 4)
+	assert.True(t, decimal.NewFromInt(1000).Equal(report.InitialEquity))
+	// 盈利 10，手续费 (100 + 110) * 0.0005
+	assert.True(t, decimal.NewFromFloat(1009.895).Equal(report.FinalEquity), report.FinalEquity.String())
+	assert.True(t, decimal.NewFromFloat(0.105).Equal(report.Fees))
+	assert.InDelta(t, 0.01, report.MaxDrawdown, 0.0001)
+	assert.Equal(t, start+3*time.Hour.Milliseconds(), e.Env().Clock.Now().UnixMilli())
+}
+
+func TestEngineNoDataFeed(t *testing.T) {
+	_, err := NewEngine().Run(context.Background(), &buyThenSell{})
+	assert.True(t, errors.Is(err, ErrNoDataFeed))
+}
+
+func TestEngineEquityGap(t *testing.T) {
+	// 第 2 笔和第 3 笔成交之间间隔 3 小时，中间的采样点沿用上一笔成交的价格
+	start := int64(1699999200000)
+	list := trades(start, 100, 90, 110)
+	list[2].TradedAt = start + 4*time.Hour.Milliseconds()
+	e := NewEngine(
+		WithDataFeed("BTCUSDT", exchange.MarketTypeSpot, &sliceFeed{trades: list}),
+		WithBalance(exchange.MarketTypeSpot, "USDT", decimal.NewFromInt(1000)),
+	)
+	report, err := e.Run(context.Background(), &buyThenSell{})
+	assert.NoError(t, err)
+
+	assert.Len(t, report.EquityCurve, 5)
+	for i, p := range report.EquityCurve {
+		assert.Equal(t, start+int64(i)*time.Hour.Milliseconds(), p.Time)
+	}
+	assert.True(t, report.EquityCurve[1].Equity.Equal(report.EquityCurve[3].Equity))
+}
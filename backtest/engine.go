@@ -0,0 +1,327 @@
+package backtest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/dfmanager/dffile"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/paexc"
+	"github.com/go-gotop/kit/streammanager"
+	"github.com/go-gotop/kit/streammanager/streampaper"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNoDataFeed = errors.New("no data feed")
+)
+
+// NewEngine 回测引擎：按时间顺序回放数据源，驱动模拟交易所撮合，并把行情与订单推送给策略
+func NewEngine(opts ...Option) *Engine {
+	o := &options{
+		logger:         log.NewHelper(log.DefaultLogger),
+		fundingRates:   make(map[string][]FundingRate),
+		quoteAsset:     "USDT",
+		equityInterval: time.Hour,
+		bufferSize:     1024,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ex := paexc.NewPaperExchange(o.exchangeOpts...)
+	clock := &virtualClock{}
+	clock.set(o.startTime)
+	return &Engine{
+		opts:   o,
+		ex:     ex,
+		feed:   newReplayFeed(),
+		stream: streampaper.NewPaperStream(ex),
+		clock:  clock,
+	}
+}
+
+type Engine struct {
+	opts   *options
+	ex     *paexc.PaperExchange
+	feed   *replayFeed
+	stream streammanager.StreamManager
+	clock  *virtualClock
+}
+
+// cursor 单个数据源的回放游标
+type cursor struct {
+	id     string
+	source *source
+	events chan *exchange.TradeEvent
+	done   chan struct{}
+	once   sync.Once
+	err    error
+	head   *exchange.TradeEvent
+}
+
+func (c *cursor) finish(err error) {
+	c.once.Do(func() {
+		if !errors.Is(err, dffile.ErrCsvFileFinished) {
+			c.err = err
+		}
+		close(c.done)
+	})
+}
+
+// next 读取下一条数据，数据源结束返回 false
+func (c *cursor) next(ctx context.Context) (bool, error) {
+	select {
+	case te := <-c.events:
+		c.head = te
+		return true, nil
+	case <-c.done:
+		select {
+		case te := <-c.events:
+			c.head = te
+			return true, nil
+		default:
+		}
+		return false, c.err
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// Env 策略运行环境
+func (e *Engine) Env() *Env {
+	return &Env{
+		Exchange: e.ex,
+		DataFeed: e.feed,
+		Stream:   e.stream,
+		Clock:    e.clock,
+	}
+}
+
+// Exchange 回测使用的模拟交易所
+func (e *Engine) Exchange() *paexc.PaperExchange {
+	return e.ex
+}
+
+// Run 执行回测，直到所有数据源回放完毕
+func (e *Engine) Run(ctx context.Context, s Strategy) (*Report, error) {
+	if len(e.opts.sources) == 0 {
+		return nil, ErrNoDataFeed
+	}
+	for _, b := range e.opts.balances {
+		if err := e.ex.SetBalance(b.marketType, b.asset, b.amount); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.Start(ctx, e.Env()); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cursors := make([]*cursor, 0, len(e.opts.sources))
+	defer func() {
+		for _, c := range cursors {
+			_ = c.source.dataFeed.CloseDataFeed(c.id)
+		}
+	}()
+	for _, src := range e.opts.sources {
+		c := &cursor{
+			id:     uuid.New().String(),
+			source: src,
+			events: make(chan *exchange.TradeEvent, e.opts.bufferSize),
+			done:   make(chan struct{}),
+		}
+		err := src.dataFeed.AddDataFeed(&dfmanager.DataFeedRequest{
+			ID:         c.id,
+			Symbol:     src.symbol,
+			StartTime:  e.opts.startTime,
+			EndTime:    e.opts.endTime,
+			MarketType: src.marketType,
+			Event: func(data *exchange.TradeEvent) {
+				data.Symbol = src.symbol
+				data.MarketType = src.marketType
+				select {
+				case c.events <- data:
+				case <-ctx.Done():
+				}
+			},
+			ErrorHandler: c.finish,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cursors = append(cursors, c)
+	}
+
+	active := make([]*cursor, 0, len(cursors))
+	for _, c := range cursors {
+		ok, err := c.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			active = append(active, c)
+		}
+	}
+
+	r := &runner{
+		engine:   e,
+		fundings: e.pendingFundings(),
+	}
+	for len(active) > 0 {
+		// 多数据源按时间归并
+		idx := 0
+		for i, c := range active {
+			if c.head.TradedAt < active[idx].head.TradedAt {
+				idx = i
+			}
+		}
+		c := active[idx]
+		te := c.head
+		c.head = nil
+		r.process(te)
+
+		ok, err := c.next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			active = append(active[:idx], active[idx+1:]...)
+		}
+	}
+
+	if err := s.Stop(); err != nil {
+		e.opts.logger.Errorf("strategy stop error: %v", err)
+	}
+	return r.report(), nil
+}
+
+type pendingFunding struct {
+	symbol string
+	FundingRate
+}
+
+func (e *Engine) pendingFundings() []pendingFunding {
+	list := make([]pendingFunding, 0)
+	for symbol, rates := range e.opts.fundingRates {
+		for _, rate := range rates {
+			if e.opts.startTime > 0 && rate.Time < e.opts.startTime {
+				continue
+			}
+			list = append(list, pendingFunding{symbol: symbol, FundingRate: rate})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Time < list[j].Time
+	})
+	return list
+}
+
+// runner 单次回测的运行状态
+type runner struct {
+	engine        *Engine
+	fundings      []pendingFunding
+	started       bool
+	startTime     int64
+	lastTime      int64
+	nextSample    int64
+	initialEquity decimal.Decimal
+	curve         []EquityPoint
+}
+
+func (r *runner) process(te *exchange.TradeEvent) {
+	e := r.engine
+	// 结算到期的资金费
+	for len(r.fundings) > 0 && r.fundings[0].Time <= te.TradedAt {
+		f := r.fundings[0]
+		r.fundings = r.fundings[1:]
+		r.fillSamples(f.Time)
+		e.clock.set(f.Time)
+		mp := &exchange.MarkPriceEvent{
+			Time:            f.Time,
+			Symbol:          f.symbol,
+			LastFundingRate: f.Rate,
+			IsSettlement:    true,
+		}
+		e.ex.OnMarkPrice(mp)
+		e.feed.dispatchMarkPrice(mp)
+	}
+
+	r.fillSamples(te.TradedAt)
+	e.clock.set(te.TradedAt)
+	e.ex.OnTrade(te)
+	if !r.started {
+		r.started = true
+		r.startTime = te.TradedAt
+		r.initialEquity = e.ex.Equity(e.opts.quoteAsset)
+	}
+	e.feed.dispatchTrade(te)
+	r.lastTime = te.TradedAt
+
+	if te.TradedAt >= r.nextSample {
+		r.sample(te.TradedAt)
+		r.nextSample = te.TradedAt + 1
+		if interval := e.opts.equityInterval.Milliseconds(); interval > 0 {
+			r.nextSample = (te.TradedAt/interval + 1) * interval
+		}
+	}
+}
+
+// fillSamples 补齐 until 之前没有任何成交的采样区间，采样时间为区间起点，权益沿用最近的价格，
+// 保证相邻采样点的间隔与年化夏普比率使用的间隔一致
+func (r *runner) fillSamples(until int64) {
+	interval := r.engine.opts.equityInterval.Milliseconds()
+	if !r.started || interval <= 0 {
+		return
+	}
+	for r.nextSample+interval <= until {
+		r.sample(r.nextSample)
+		r.nextSample += interval
+	}
+}
+
+func (r *runner) sample(ts int64) {
+	r.curve = append(r.curve, EquityPoint{
+		Time:   ts,
+		Equity: r.engine.ex.Equity(r.engine.opts.quoteAsset),
+	})
+}
+
+func (r *runner) report() *Report {
+	e := r.engine
+	if r.started && r.curve[len(r.curve)-1].Time != r.lastTime {
+		r.sample(r.lastTime)
+	}
+
+	report := &Report{
+		StartTime:     r.startTime,
+		EndTime:       r.lastTime,
+		InitialEquity: r.initialEquity,
+		FinalEquity:   e.ex.Equity(e.opts.quoteAsset),
+		EquityCurve:   r.curve,
+		Trades:        e.ex.Fills(),
+		Fundings:      e.ex.Fundings(),
+		Fees:          decimal.Zero,
+		FundingFees:   decimal.Zero,
+	}
+	report.Pnl = report.FinalEquity.Sub(report.InitialEquity)
+	for _, f := range report.Trades {
+		report.Fees = report.Fees.Add(f.Fee)
+	}
+	for _, f := range report.Fundings {
+		report.FundingFees = report.FundingFees.Add(f.Amount)
+	}
+	report.ClosedTrades, report.WinRate = winRate(report.Trades)
+	report.SharpeRatio = sharpeRatio(report.EquityCurve, e.opts.equityInterval)
+	report.MaxDrawdown = maxDrawdown(report.EquityCurve)
+	return report
+}
@@ -0,0 +1,62 @@
+package backtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/paexc"
+	"github.com/go-gotop/kit/streammanager"
+	"github.com/shopspring/decimal"
+)
+
+// Strategy 策略只依赖 Env 中的接口，实盘与回测可使用同一份代码
+type Strategy interface {
+	// Start 回放开始前调用，在此订阅行情与订单推送
+	Start(ctx context.Context, env *Env) error
+	// Stop 回放结束后调用
+	Stop() error
+}
+
+// Env 策略运行环境，回测时由 Engine 提供，实盘时替换为真实实现
+type Env struct {
+	Exchange exchange.Exchange
+	DataFeed dfmanager.DataFeedManager
+	Stream   streammanager.StreamManager
+	Clock    Clock
+}
+
+// Clock 时钟，回测时为回放数据的时间
+type Clock interface {
+	Now() time.Time
+}
+
+// FundingRate 历史资金费率
+type FundingRate struct {
+	Time int64
+	Rate decimal.Decimal
+}
+
+type EquityPoint struct {
+	Time   int64
+	Equity decimal.Decimal
+}
+
+// Report 回测结果
+type Report struct {
+	StartTime     int64
+	EndTime       int64
+	InitialEquity decimal.Decimal
+	FinalEquity   decimal.Decimal
+	Pnl           decimal.Decimal
+	EquityCurve   []EquityPoint
+	Trades        []paexc.Fill    // 成交记录
+	Fundings      []paexc.Funding // 资金费记录
+	Fees          decimal.Decimal // 手续费合计
+	FundingFees   decimal.Decimal // 资金费合计，正数为支付
+	ClosedTrades  int             // 有实现盈亏的成交次数
+	WinRate       float64         // 胜率，扣除手续费后盈利的平仓成交占比
+	SharpeRatio   float64         // 按采样间隔年化的夏普比率
+	MaxDrawdown   float64         // 最大回撤比例
+}
@@ -0,0 +1,251 @@
+package backtest
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/google/uuid"
+)
+
+var _ dfmanager.DataFeedManager = (*replayFeed)(nil)
+
+// replayFeed 回测中提供给策略的行情订阅，数据由 Engine 按时间顺序分发
+type replayFeed struct {
+	tradeSubs     map[string]*dfmanager.DataFeedRequest
+	markPriceSubs map[string]*dfmanager.MarkPriceRequest
+	fundingSubs   map[string]*dfmanager.FundingRateRequest
+	mux           sync.Mutex
+}
+
+func newReplayFeed() *replayFeed {
+	return &replayFeed{
+		tradeSubs:     make(map[string]*dfmanager.DataFeedRequest),
+		markPriceSubs: make(map[string]*dfmanager.MarkPriceRequest),
+		fundingSubs:   make(map[string]*dfmanager.FundingRateRequest),
+	}
+}
+
+func (r *replayFeed) Name() string {
+	return "Backtest"
+}
+
+func (r *replayFeed) AddDataFeed(req *dfmanager.DataFeedRequest) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	id := req.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if _, ok := r.tradeSubs[id]; ok {
+		return errors.New("stream already exists")
+	}
+	r.tradeSubs[id] = req
+	return nil
+}
+
+func (r *replayFeed) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	id := req.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if _, ok := r.markPriceSubs[id]; ok {
+		return errors.New("stream already exists")
+	}
+	r.markPriceSubs[id] = req
+	return nil
+}
+
+// AddFundingRateDataFeed 资金费率由回放的标记价格生成
+func (r *replayFeed) AddFundingRateDataFeed(req *dfmanager.FundingRateRequest) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	id := req.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	if _, ok := r.fundingSubs[id]; ok {
+		return errors.New("stream already exists")
+	}
+	r.fundingSubs[id] = req
+	return nil
+}
+
+// AddDepthDataFeed 回放数据没有订单簿
+func (r *replayFeed) AddDepthDataFeed(req *dfmanager.DepthRequest) error {
+	return errors.New("not implemented")
+}
+
+// AddBookTickerDataFeed 回放数据没有最优挂单
+func (r *replayFeed) AddBookTickerDataFeed(req *dfmanager.BookTickerRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddMultiDataFeed(req *dfmanager.MultiDataFeedRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) SubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) UnsubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddLiquidationDataFeed(req *dfmanager.LiquidationRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddOpenInterestDataFeed(req *dfmanager.OpenInterestRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddMarketKlineDataFeed(req *dfmanager.KlineMarketRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) AddSymbolUpdateDataFeed(req *dfmanager.SymbolUpdateRequest) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) CloseDataFeed(id string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	_, ok1 := r.tradeSubs[id]
+	_, ok2 := r.markPriceSubs[id]
+	_, ok3 := r.fundingSubs[id]
+	if !ok1 && !ok2 && !ok3 {
+		return errors.New("stream not found")
+	}
+	delete(r.tradeSubs, id)
+	delete(r.markPriceSubs, id)
+	delete(r.fundingSubs, id)
+	return nil
+}
+
+func (r *replayFeed) DataFeedList() []dfmanager.Stream {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	list := make([]dfmanager.Stream, 0, len(r.tradeSubs)+len(r.markPriceSubs)+len(r.fundingSubs))
+	for id, req := range r.tradeSubs {
+		list = append(list, dfmanager.Stream{
+			UUID:        id,
+			MarketType:  req.MarketType,
+			DataType:    "trade",
+			Symbol:      req.Symbol,
+			IsConnected: true,
+		})
+	}
+	for id, req := range r.markPriceSubs {
+		list = append(list, dfmanager.Stream{
+			UUID:        id,
+			MarketType:  req.MarketType,
+			DataType:    "markPrice",
+			Symbol:      req.Symbol,
+			IsConnected: true,
+		})
+	}
+	for id, req := range r.fundingSubs {
+		list = append(list, dfmanager.Stream{
+			UUID:        id,
+			MarketType:  req.MarketType,
+			DataType:    "fundingRate",
+			Symbol:      req.Symbol,
+			IsConnected: true,
+		})
+	}
+	return list
+}
+
+func (r *replayFeed) WriteMessage(id string, message []byte) error {
+	return errors.New("not implemented")
+}
+
+func (r *replayFeed) Shutdown() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.tradeSubs = make(map[string]*dfmanager.DataFeedRequest)
+	r.markPriceSubs = make(map[string]*dfmanager.MarkPriceRequest)
+	r.fundingSubs = make(map[string]*dfmanager.FundingRateRequest)
+	return nil
+}
+
+func (r *replayFeed) dispatchTrade(te *exchange.TradeEvent) {
+	r.mux.Lock()
+	subs := make([]*dfmanager.DataFeedRequest, 0, len(r.tradeSubs))
+	for _, req := range r.tradeSubs {
+		if req.Symbol == te.Symbol && (req.MarketType == "" || req.MarketType == te.MarketType) {
+			subs = append(subs, req)
+		}
+	}
+	r.mux.Unlock()
+
+	for _, req := range subs {
+		evt := *te
+		req.Event(&evt)
+	}
+}
+
+func (r *replayFeed) dispatchMarkPrice(mp *exchange.MarkPriceEvent) {
+	r.mux.Lock()
+	subs := make([]*dfmanager.MarkPriceRequest, 0, len(r.markPriceSubs))
+	for _, req := range r.markPriceSubs {
+		if req.Symbol == "" || req.Symbol == mp.Symbol {
+			subs = append(subs, req)
+		}
+	}
+	fundingSubs := make([]*dfmanager.FundingRateRequest, 0, len(r.fundingSubs))
+	for _, req := range r.fundingSubs {
+		if req.Symbol == "" || req.Symbol == mp.Symbol {
+			fundingSubs = append(fundingSubs, req)
+		}
+	}
+	r.mux.Unlock()
+
+	for _, req := range subs {
+		evt := *mp
+		req.Event(&evt)
+	}
+	for _, req := range fundingSubs {
+		req.Event(&exchange.FundingRateEvent{
+			Symbol:          mp.Symbol,
+			MarketType:      req.MarketType,
+			MarkPrice:       mp.MarkPrice,
+			IndexPrice:      mp.IndexPrice,
+			FundingRate:     mp.LastFundingRate,
+			NextFundingTime: mp.NextFundingTime,
+			Time:            mp.Time,
+		})
+	}
+}
+
+// virtualClock 以回放数据时间推进的时钟
+type virtualClock struct {
+	ms atomic.Int64
+}
+
+func (c *virtualClock) Now() time.Time {
+	return time.UnixMilli(c.ms.Load())
+}
+
+func (c *virtualClock) set(ms int64) {
+	if ms > c.ms.Load() {
+		c.ms.Store(ms)
+	}
+}
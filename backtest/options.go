@@ -0,0 +1,115 @@
+package backtest
+
+import (
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/dfmanager/dffile"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/paexc"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*options)
+
+type source struct {
+	symbol     string
+	marketType exchange.MarketType
+	dataFeed   dfmanager.DataFeedManager
+}
+
+type initialBalance struct {
+	marketType exchange.MarketType
+	asset      string
+	amount     decimal.Decimal
+}
+
+type options struct {
+	logger         *log.Helper
+	sources        []*source
+	balances       []*initialBalance
+	fundingRates   map[string][]FundingRate
+	exchangeOpts   []paexc.Option
+	startTime      int64
+	endTime        int64
+	quoteAsset     string        // 权益计价资产
+	equityInterval time.Duration // 权益曲线采样间隔
+	bufferSize     int           // 每个数据源的缓冲大小
+}
+
+func WithLogger(logger *log.Helper) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithDataFeed 添加回放数据源，数据源需在结束时以 dffile.ErrCsvFileFinished 调用 ErrorHandler
+func WithDataFeed(symbol string, marketType exchange.MarketType, df dfmanager.DataFeedManager) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, &source{
+			symbol:     symbol,
+			marketType: marketType,
+			dataFeed:   df,
+		})
+	}
+}
+
+// WithFileDataFeed 添加 dffile 逐笔成交数据源
+func WithFileDataFeed(symbol string, marketType exchange.MarketType, path string) Option {
+	return WithDataFeed(symbol, marketType, dffile.NewFileDataFeed(dffile.WithPath(path)))
+}
+
+// WithBalance 设置初始余额
+func WithBalance(marketType exchange.MarketType, asset string, amount decimal.Decimal) Option {
+	return func(o *options) {
+		o.balances = append(o.balances, &initialBalance{
+			marketType: marketType,
+			asset:      asset,
+			amount:     amount,
+		})
+	}
+}
+
+// WithFundingRates 设置合约历史资金费率，用于回放中结算资金费
+func WithFundingRates(symbol string, rates []FundingRate) Option {
+	return func(o *options) {
+		o.fundingRates[symbol] = append(o.fundingRates[symbol], rates...)
+	}
+}
+
+func WithExchangeOptions(opts ...paexc.Option) Option {
+	return func(o *options) {
+		o.exchangeOpts = append(o.exchangeOpts, opts...)
+	}
+}
+
+func WithStartTime(startTime int64) Option {
+	return func(o *options) {
+		o.startTime = startTime
+	}
+}
+
+func WithEndTime(endTime int64) Option {
+	return func(o *options) {
+		o.endTime = endTime
+	}
+}
+
+func WithQuoteAsset(asset string) Option {
+	return func(o *options) {
+		o.quoteAsset = asset
+	}
+}
+
+func WithEquityInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.equityInterval = interval
+	}
+}
+
+func WithBufferSize(size int) Option {
+	return func(o *options) {
+		o.bufferSize = size
+	}
+}
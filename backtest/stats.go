@@ -0,0 +1,77 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-gotop/kit/exchange/paexc"
+)
+
+// winRate 平仓成交的胜率
+func winRate(fills []paexc.Fill) (int, float64) {
+	closed, wins := 0, 0
+	for _, f := range fills {
+		if f.RealizedPnl.IsZero() {
+			continue
+		}
+		closed++
+		if f.RealizedPnl.Sub(f.Fee).IsPositive() {
+			wins++
+		}
+	}
+	if closed == 0 {
+		return 0, 0
+	}
+	return closed, float64(wins) / float64(closed)
+}
+
+// sharpeRatio 按权益曲线采样间隔计算的年化夏普比率（无风险利率取 0）
+func sharpeRatio(curve []EquityPoint, interval time.Duration) float64 {
+	if len(curve) < 3 || interval <= 0 {
+		return 0
+	}
+	returns := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		prev := curve[i-1].Equity.InexactFloat64()
+		if prev == 0 {
+			continue
+		}
+		returns = append(returns, curve[i].Equity.InexactFloat64()/prev-1)
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	periods := float64(365*24*time.Hour) / float64(interval)
+	return mean / std * math.Sqrt(periods)
+}
+
+// maxDrawdown 最大回撤比例
+func maxDrawdown(curve []EquityPoint) float64 {
+	peak, mdd := 0.0, 0.0
+	for _, p := range curve {
+		equity := p.Equity.InexactFloat64()
+		if equity > peak {
+			peak = equity
+		}
+		if peak > 0 {
+			if dd := (peak - equity) / peak; dd > mdd {
+				mdd = dd
+			}
+		}
+	}
+	return mdd
+}
@@ -0,0 +1,414 @@
+package dfbinance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/websocket"
+	"github.com/go-gotop/kit/wsmanager"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	gwebsocket "github.com/gorilla/websocket"
+)
+
+const (
+	bnSpotStreamEndpoint = "wss://stream.binance.com:9443/stream"
+
+	bnSpotMaxStreams    = 1024 // 现货单连接最多订阅的数据流
+	bnFuturesMaxStreams = 200  // 合约单连接最多订阅的数据流
+)
+
+var errFeedNotFound = errors.New("data feed not found")
+
+// AddMultiDataFeed 使用组合数据流订阅多个交易对的成交，超过单连接上限时自动新建连接
+func (d *df) AddMultiDataFeed(req *dfmanager.MultiDataFeedRequest) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, ok := d.combined[req.ID]; ok {
+		return errors.New("data feed already exists")
+	}
+
+	f := newBnCombinedFeed(d, req)
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		f.endpoint, f.channel, f.maxStreams, f.fn = bnSpotStreamEndpoint, "trade", bnSpotMaxStreams, spotToTradeEvent
+	case exchange.MarketTypeMargin:
+		f.endpoint, f.channel, f.maxStreams, f.fn = bnSpotStreamEndpoint, "trade", bnSpotMaxStreams, marginToTradeEvent
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		f.endpoint, f.channel, f.maxStreams, f.fn = bnFunturesStreamEndpoint, "aggTrade", bnFuturesMaxStreams, futuresToTradeEvent
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		f.endpoint, f.channel, f.maxStreams, f.fn = bnDeliveryStreamEndpoint, "aggTrade", bnFuturesMaxStreams, coinToTradeEvent(req.MarketType)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+
+	if err := f.subscribe(req.Symbols); err != nil {
+		f.close()
+		return err
+	}
+	d.combined[req.ID] = f
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      f.symbolList(),
+		MarketType:  req.MarketType,
+		DataType:    "trade",
+		IsConnected: true,
+	}
+	return nil
+}
+
+// SubscribeSymbols 在已有连接上订阅交易对，连接已满时新建连接
+func (d *df) SubscribeSymbols(id string, symbols ...string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	f, ok := d.combined[id]
+	if !ok {
+		return errFeedNotFound
+	}
+	err := f.subscribe(symbols)
+	d.updateCombinedStream(id, f)
+	return err
+}
+
+// UnsubscribeSymbols 取消订阅交易对，连接上没有数据流时关闭连接
+func (d *df) UnsubscribeSymbols(id string, symbols ...string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	f, ok := d.combined[id]
+	if !ok {
+		return errFeedNotFound
+	}
+	err := f.unsubscribe(symbols)
+	d.updateCombinedStream(id, f)
+	return err
+}
+
+func (d *df) updateCombinedStream(id string, f *bnCombinedFeed) {
+	if s, ok := d.streams[id]; ok {
+		s.Symbol = f.symbolList()
+		d.streams[id] = s
+	}
+}
+
+// bnCombinedFeed 组合数据流订阅，每个连接为一个分片，交易对按顺序填满分片
+type bnCombinedFeed struct {
+	d          *df
+	req        *dfmanager.MultiDataFeedRequest
+	endpoint   string
+	channel    string
+	maxStreams int
+	fn         func(message []byte) (*exchange.TradeEvent, error)
+
+	// 分片连接操作，测试时替换
+	dial   func(shard *bnStreamShard) error
+	write  func(id string, message []byte) error
+	hangup func(id string)
+
+	shards  []*bnStreamShard
+	symbols map[string]*bnStreamShard // 交易对所在的分片
+	seq     int                       // 分片连接 ID 序号
+	msgID   int64                     // 订阅请求 ID
+	mux     sync.Mutex
+}
+
+func newBnCombinedFeed(d *df, req *dfmanager.MultiDataFeedRequest) *bnCombinedFeed {
+	f := &bnCombinedFeed{
+		d:       d,
+		req:     req,
+		symbols: make(map[string]*bnStreamShard),
+	}
+	f.dial, f.write, f.hangup = f.connect, f.writeShard, f.closeShard
+	return f
+}
+
+type bnStreamShard struct {
+	id      string
+	symbols map[string]struct{}
+}
+
+// bnCombinedMessage 组合数据流推送为 {"stream":..., "data":...}，订阅请求的响应为 {"result":..., "id":...}
+type bnCombinedMessage struct {
+	Stream string          `json:"stream"`
+	Data   json.RawMessage `json:"data"`
+	Error  *struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
+
+// subscribe 分配交易对到分片并订阅，出错时回滚未订阅成功的交易对，已订阅的交易对保持不变
+func (f *bnCombinedFeed) subscribe(symbols []string) error {
+	f.mux.Lock()
+	created := make([]*bnStreamShard, 0)
+	added := make(map[*bnStreamShard][]string)
+	for _, symbol := range symbols {
+		symbol = strings.ToLower(symbol)
+		if _, ok := f.symbols[symbol]; ok {
+			continue
+		}
+		shard := f.availableShard()
+		if shard == nil {
+			shard = &bnStreamShard{
+				id:      fmt.Sprintf("%s-%d", f.req.ID, f.seq),
+				symbols: make(map[string]struct{}),
+			}
+			f.seq++
+			f.shards = append(f.shards, shard)
+			created = append(created, shard)
+		}
+		shard.symbols[symbol] = struct{}{}
+		f.symbols[symbol] = shard
+		added[shard] = append(added[shard], symbol)
+	}
+	f.mux.Unlock()
+
+	// 新建的连接在连接成功回调中订阅，已有连接直接发送订阅请求
+	var err error
+	for i, shard := range created {
+		delete(added, shard)
+		if err = f.dial(shard); err != nil {
+			for _, v := range created[i:] {
+				delete(added, v)
+				f.removeShard(v)
+			}
+			break
+		}
+	}
+	for shard, list := range added {
+		if err == nil {
+			if err = f.send(shard, "SUBSCRIBE", list); err == nil {
+				continue
+			}
+		}
+		f.removeSymbols(shard, list)
+	}
+	return err
+}
+
+// unsubscribe 取消订阅交易对，发送失败时恢复未取消的交易对
+func (f *bnCombinedFeed) unsubscribe(symbols []string) error {
+	f.mux.Lock()
+	removed := make(map[*bnStreamShard][]string)
+	for _, symbol := range symbols {
+		symbol = strings.ToLower(symbol)
+		shard, ok := f.symbols[symbol]
+		if !ok {
+			continue
+		}
+		delete(shard.symbols, symbol)
+		delete(f.symbols, symbol)
+		removed[shard] = append(removed[shard], symbol)
+	}
+	empty := make(map[*bnStreamShard]bool, len(removed))
+	for shard := range removed {
+		empty[shard] = len(shard.symbols) == 0
+	}
+	f.mux.Unlock()
+
+	var err error
+	for shard, list := range removed {
+		if empty[shard] {
+			f.removeShard(shard)
+			continue
+		}
+		if err == nil {
+			if err = f.send(shard, "UNSUBSCRIBE", list); err == nil {
+				continue
+			}
+		}
+		f.restoreSymbols(shard, list)
+	}
+	return err
+}
+
+// removeSymbols 从分片移除未订阅成功的交易对
+func (f *bnCombinedFeed) removeSymbols(shard *bnStreamShard, symbols []string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, symbol := range symbols {
+		delete(shard.symbols, symbol)
+		if f.symbols[symbol] == shard {
+			delete(f.symbols, symbol)
+		}
+	}
+}
+
+// restoreSymbols 恢复未取消订阅的交易对
+func (f *bnCombinedFeed) restoreSymbols(shard *bnStreamShard, symbols []string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, symbol := range symbols {
+		shard.symbols[symbol] = struct{}{}
+		f.symbols[symbol] = shard
+	}
+}
+
+// availableShard 调用方需持有锁
+func (f *bnCombinedFeed) availableShard() *bnStreamShard {
+	for _, shard := range f.shards {
+		if len(shard.symbols) < f.maxStreams {
+			return shard
+		}
+	}
+	return nil
+}
+
+func (f *bnCombinedFeed) connect(shard *bnStreamShard) error {
+	if !f.d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+	conf := &wsmanager.WebsocketConfig{
+		PingHandler: pingHandler,
+		PongHandler: pongHandler,
+	}
+	return f.d.addWebsocket(&websocket.WebsocketRequest{
+		ID:             shard.id,
+		Endpoint:       f.endpoint,
+		MessageHandler: f.onMessage,
+		ErrorHandler:   f.req.ErrorHandler,
+		// 重连后重新订阅分片上的全部交易对
+		ConnectedHandler: func(id string, conn websocket.WebSocketConn) {
+			message, err := f.message("SUBSCRIBE", f.shardSymbols(shard))
+			if err == nil {
+				err = conn.WriteMessage(gwebsocket.TextMessage, message)
+			}
+			if err != nil {
+				f.onError(err)
+			}
+		},
+	}, conf)
+}
+
+// removeShard 关闭分片连接并移除分片上的交易对
+func (f *bnCombinedFeed) removeShard(shard *bnStreamShard) {
+	f.mux.Lock()
+	for i, v := range f.shards {
+		if v == shard {
+			f.shards = append(f.shards[:i], f.shards[i+1:]...)
+			break
+		}
+	}
+	for symbol := range shard.symbols {
+		delete(f.symbols, symbol)
+	}
+	f.mux.Unlock()
+
+	f.hangup(shard.id)
+}
+
+func (f *bnCombinedFeed) closeShard(id string) {
+	if f.d.wsm.GetWebsocket(id) != nil {
+		f.d.wsm.CloseWebsocket(id)
+	}
+}
+
+func (f *bnCombinedFeed) close() {
+	f.mux.Lock()
+	shards := append([]*bnStreamShard(nil), f.shards...)
+	f.mux.Unlock()
+	for _, shard := range shards {
+		f.removeShard(shard)
+	}
+}
+
+func (f *bnCombinedFeed) isConnected() bool {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for _, shard := range f.shards {
+		if !f.d.wsm.IsConnected(shard.id) {
+			return false
+		}
+	}
+	return len(f.shards) > 0
+}
+
+func (f *bnCombinedFeed) send(shard *bnStreamShard, method string, symbols []string) error {
+	message, err := f.message(method, symbols)
+	if err != nil {
+		return err
+	}
+	return f.write(shard.id, message)
+}
+
+func (f *bnCombinedFeed) writeShard(id string, message []byte) error {
+	conn := f.d.wsm.GetWebsocket(id)
+	if conn == nil {
+		return errors.New("websocket not found")
+	}
+	return conn.WriteMessage(gwebsocket.TextMessage, message)
+}
+
+func (f *bnCombinedFeed) message(method string, symbols []string) ([]byte, error) {
+	f.mux.Lock()
+	f.msgID++
+	id := f.msgID
+	f.mux.Unlock()
+
+	params := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		params = append(params, fmt.Sprintf("%s@%s", symbol, f.channel))
+	}
+	return json.Marshal(map[string]interface{}{
+		"method": method,
+		"params": params,
+		"id":     id,
+	})
+}
+
+func (f *bnCombinedFeed) onMessage(message []byte) {
+	e := &bnCombinedMessage{}
+	if err := json.Unmarshal(message, e); err != nil {
+		f.onError(err)
+		return
+	}
+	if e.Error != nil {
+		f.onError(fmt.Errorf("binance subscribe error, code: %d, message: %s", e.Error.Code, e.Error.Msg))
+		return
+	}
+	if e.Stream == "" {
+		return
+	}
+	te, err := f.fn(e.Data)
+	if err != nil {
+		f.onError(err)
+		return
+	}
+	f.req.Event(te)
+}
+
+func (f *bnCombinedFeed) onError(err error) {
+	if f.req.ErrorHandler != nil {
+		f.req.ErrorHandler(err)
+	}
+}
+
+func (f *bnCombinedFeed) shardSymbols(shard *bnStreamShard) []string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	list := make([]string, 0, len(shard.symbols))
+	for symbol := range shard.symbols {
+		list = append(list, symbol)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// symbolList 已订阅的交易对，逗号分隔
+func (f *bnCombinedFeed) symbolList() string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	list := make([]string, 0, len(f.symbols))
+	for symbol := range f.symbols {
+		list = append(list, symbol)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
@@ -0,0 +1,116 @@
+package dfbinance
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeShardConn struct {
+	dialed   []string
+	closed   []string
+	messages map[string][]string
+	dialErr  error
+	writeErr error
+}
+
+func newTestCombinedFeed(maxStreams int) (*bnCombinedFeed, *fakeShardConn) {
+	conn := &fakeShardConn{messages: make(map[string][]string)}
+	f := newBnCombinedFeed(nil, &dfmanager.MultiDataFeedRequest{ID: "feed"})
+	f.channel, f.maxStreams = "trade", maxStreams
+	f.dial = func(shard *bnStreamShard) error {
+		if conn.dialErr != nil {
+			return conn.dialErr
+		}
+		conn.dialed = append(conn.dialed, shard.id)
+		return nil
+	}
+	f.write = func(id string, message []byte) error {
+		if conn.writeErr != nil {
+			return conn.writeErr
+		}
+		var m struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+		}
+		_ = json.Unmarshal(message, &m)
+		for _, p := range m.Params {
+			conn.messages[id] = append(conn.messages[id], m.Method+" "+p)
+		}
+		return nil
+	}
+	f.hangup = func(id string) {
+		conn.closed = append(conn.closed, id)
+	}
+	return f, conn
+}
+
+func TestCombinedFeedSharding(t *testing.T) {
+	f, conn := newTestCombinedFeed(2)
+
+	// 超过单连接上限时新建分片，新分片在连接成功回调中订阅
+	assert.NoError(t, f.subscribe([]string{"BTCUSDT", "ETHUSDT", "BNBUSDT"}))
+	assert.Equal(t, []string{"feed-0", "feed-1"}, conn.dialed)
+	assert.Len(t, f.shards, 2)
+	assert.Equal(t, []string{"btcusdt", "ethusdt"}, f.shardSymbols(f.shards[0]))
+	assert.Equal(t, []string{"bnbusdt"}, f.shardSymbols(f.shards[1]))
+	assert.Empty(t, conn.messages)
+
+	// 已有分片有空位时直接发送订阅请求，重复订阅忽略
+	assert.NoError(t, f.subscribe([]string{"SOLUSDT", "btcusdt"}))
+	assert.Equal(t, []string{"SUBSCRIBE solusdt@trade"}, conn.messages["feed-1"])
+	assert.Equal(t, "bnbusdt,btcusdt,ethusdt,solusdt", f.symbolList())
+
+	// 分片取消全部交易对后关闭连接
+	assert.NoError(t, f.unsubscribe([]string{"BNBUSDT", "SOLUSDT", "XRPUSDT"}))
+	assert.Equal(t, []string{"feed-1"}, conn.closed)
+	assert.Len(t, f.shards, 1)
+
+	assert.NoError(t, f.unsubscribe([]string{"ETHUSDT"}))
+	assert.Equal(t, []string{"UNSUBSCRIBE ethusdt@trade"}, conn.messages["feed-0"])
+	assert.Equal(t, "btcusdt", f.symbolList())
+
+	// 空出的位置优先使用
+	assert.NoError(t, f.subscribe([]string{"XRPUSDT"}))
+	assert.Equal(t, []string{"UNSUBSCRIBE ethusdt@trade", "SUBSCRIBE xrpusdt@trade"}, conn.messages["feed-0"])
+	assert.Len(t, f.shards, 1)
+
+	f.close()
+	assert.Empty(t, f.shards)
+	assert.Equal(t, "", f.symbolList())
+}
+
+func TestCombinedFeedSubscribeRollback(t *testing.T) {
+	f, conn := newTestCombinedFeed(2)
+	assert.NoError(t, f.subscribe([]string{"BTCUSDT"}))
+
+	// 新建分片失败时，分配到已有分片但未发送订阅的交易对一并回滚
+	conn.dialErr = errors.New("dial failed")
+	assert.Error(t, f.subscribe([]string{"ETHUSDT", "BNBUSDT", "SOLUSDT"}))
+	assert.Equal(t, "btcusdt", f.symbolList())
+	assert.Len(t, f.shards, 1)
+	assert.Equal(t, []string{"btcusdt"}, f.shardSymbols(f.shards[0]))
+	assert.Equal(t, []string{"feed-1"}, conn.closed)
+	assert.Empty(t, conn.messages)
+
+	// 发送订阅失败时回滚
+	conn.dialErr = nil
+	conn.writeErr = errors.New("write failed")
+	assert.Error(t, f.subscribe([]string{"ETHUSDT"}))
+	assert.Equal(t, "btcusdt", f.symbolList())
+
+	// 回滚后可以重新订阅
+	conn.writeErr = nil
+	assert.NoError(t, f.subscribe([]string{"ETHUSDT"}))
+	assert.Equal(t, []string{"SUBSCRIBE ethusdt@trade"}, conn.messages["feed-0"])
+	assert.Equal(t, "btcusdt,ethusdt", f.symbolList())
+
+	// 取消订阅发送失败时恢复交易对
+	conn.writeErr = errors.New("write failed")
+	assert.Error(t, f.unsubscribe([]string{"ETHUSDT"}))
+	assert.Equal(t, "btcusdt,ethusdt", f.symbolList())
+	assert.Equal(t, []string{"btcusdt", "ethusdt"}, f.shardSymbols(f.shards[0]))
+}
@@ -0,0 +1,304 @@
+package dfbinance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/limiter"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/go-gotop/kit/websocket"
+	"github.com/go-gotop/kit/wsmanager"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+const (
+	bnSpotEndpoint     = "https://api.binance.com"
+	bnFuturesEndpoint  = "https://fapi.binance.com"
+	bnDeliveryEndpoint = "https://dapi.binance.com"
+
+	bnDepthSnapshotLimit    = 1000             // 快照档位数量
+	bnDepthRetryInterval    = time.Second      // 获取快照失败或被限频后的初始重试间隔，之后每次翻倍
+	bnDepthMaxRetryInterval = 30 * time.Second // 最大重试间隔
+	bnRequestTimeout        = 10 * time.Second // 接口请求超时时间
+)
+
+// AddDepthDataFeed 订阅增量深度并使用接口快照同步本地订单簿，更新 ID 不连续时重新获取快照
+func (d *df) AddDepthDataFeed(req *dfmanager.DepthRequest) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	symbol := strings.ToLower(req.Symbol)
+	book := &bnDepthBook{
+		req:    req,
+		symbol: strings.ToUpper(req.Symbol),
+		client: bnhttp.NewClient(),
+		logger: d.opts.logger,
+		book:   exchange.NewOrderBook(),
+	}
+	// 快照权重较高（现货 50，合约 20），自动重新同步前按快照权重通过限频检查
+	limiterReq := &limiter.LimiterReq{LimiterType: limiter.DepthSnapshotLimit}
+	book.allow = func() bool {
+		if book.futures {
+			return d.limiter.FutureAllow(limiterReq)
+		}
+		return d.limiter.SpotAllow(limiterReq)
+	}
+	var endpoint string
+	switch req.MarketType {
+	case exchange.MarketTypeSpot, exchange.MarketTypeMargin:
+		endpoint = fmt.Sprintf("%s/%s@depth@100ms", bnSpotWsEndpoint, symbol)
+		book.path = "/api/v3/depth"
+		book.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		endpoint = fmt.Sprintf("%s/%s@depth@100ms", bnFuturesWsEndpoint, symbol)
+		book.path = "/fapi/v1/depth"
+		book.futures = true
+		book.client.SetApiEndpoint(bnFuturesEndpoint)
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		endpoint = fmt.Sprintf("%s/%s@depth@100ms", bnDeliveryWsEndpoint, symbol)
+		book.path = "/dapi/v1/depth"
+		book.futures = true
+		book.client.SetApiEndpoint(bnDeliveryEndpoint)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+
+	conf := &wsmanager.WebsocketConfig{
+		PingHandler: pingHandler,
+		PongHandler: pongHandler,
+	}
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:             req.ID,
+		Endpoint:       endpoint,
+		MessageHandler: book.onMessage,
+		ErrorHandler:   req.ErrorHandler,
+	}, conf)
+	if err != nil {
+		return err
+	}
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      req.Symbol,
+		MarketType:  req.MarketType,
+		DataType:    "depth",
+		IsConnected: true,
+	}
+	return nil
+}
+
+// bnDepthBook 币安本地订单簿，同步流程：
+// 缓存推送并获取快照，丢弃快照之前的推送，第一条推送需覆盖快照的 lastUpdateId，
+// 之后现货要求 U 等于上一条的 u+1，合约要求 pu 等于上一条的 u
+type bnDepthBook struct {
+	req     *dfmanager.DepthRequest
+	symbol  string
+	futures bool
+	path    string
+	client  *bnhttp.Client
+	logger  *log.Helper
+	allow   func() bool // 获取快照前的限频检查
+
+	book         *exchange.OrderBook
+	buffer       []*binanceDepthEvent
+	lastUpdateID int64
+	synced       bool          // 已应用快照
+	bridged      bool          // 已应用快照后的第一条推送
+	syncing      bool          // 正在获取快照
+	retryDelay   time.Duration // 下一次重试前的等待时间
+	mux          sync.Mutex
+}
+
+func (s *bnDepthBook) onMessage(message []byte) {
+	e := &binanceDepthEvent{}
+	if err := json.Unmarshal(message, e); err != nil {
+		s.onError(err)
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if !s.synced {
+		s.buffer = append(s.buffer, e)
+		s.startSync()
+		return
+	}
+	if !s.apply(e, true) {
+		s.logger.Warnf("binance depth %s out of sequence, resync", s.symbol)
+		s.synced = false
+		s.buffer = append(s.buffer[:0], e)
+		s.startSync()
+	}
+}
+
+// startSync 调用方需持有锁
+func (s *bnDepthBook) startSync() {
+	if s.syncing {
+		return
+	}
+	s.syncing = true
+	go s.sync()
+}
+
+func (s *bnDepthBook) sync() {
+	if !s.allow() {
+		s.logger.Warnf("binance depth %s snapshot rate limited, retry later", s.symbol)
+		s.backoff()
+		return
+	}
+	snapshot, err := s.snapshot()
+	if err != nil {
+		s.onError(err)
+		s.backoff()
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.syncing = false
+	s.retryDelay = 0
+	if err := s.book.Reset(snapshot.Asks, snapshot.Bids); err != nil {
+		s.onError(err)
+		return
+	}
+	s.lastUpdateID = snapshot.LastUpdateID
+	s.synced, s.bridged = true, false
+	buffer := s.buffer
+	s.buffer = nil
+	ts := snapshot.Time
+	for _, e := range buffer {
+		if !s.apply(e, false) {
+			// 快照早于推送或推送不连续，重新获取快照
+			s.synced = false
+			s.startSync()
+			return
+		}
+		ts = e.Time
+	}
+	if ts == 0 {
+		ts = time.Now().UnixMilli()
+	}
+	s.emitSnapshot(ts)
+}
+
+// backoff 等待后由下一条推送触发重试，连续失败时等待时间翻倍
+func (s *bnDepthBook) backoff() {
+	s.mux.Lock()
+	delay := s.retryDelay
+	if delay == 0 {
+		delay = bnDepthRetryInterval
+	}
+	s.retryDelay = min(2*delay, bnDepthMaxRetryInterval)
+	s.mux.Unlock()
+
+	time.Sleep(delay)
+	s.mux.Lock()
+	s.syncing = false
+	s.mux.Unlock()
+}
+
+// apply 应用一条推送，序号不连续时返回 false
+func (s *bnDepthBook) apply(e *binanceDepthEvent, emit bool) bool {
+	next := s.lastUpdateID + 1
+	if s.futures {
+		next = s.lastUpdateID
+	}
+	if e.FinalUpdateID < next {
+		return true
+	}
+	if !s.bridged {
+		if e.FirstUpdateID > next {
+			return false
+		}
+		s.bridged = true
+	} else if (s.futures && e.PrevUpdateID != s.lastUpdateID) || (!s.futures && e.FirstUpdateID != s.lastUpdateID+1) {
+		return false
+	}
+	if err := s.book.Update(e.Asks, e.Bids); err != nil {
+		s.onError(err)
+		return false
+	}
+	s.lastUpdateID = e.FinalUpdateID
+	if emit {
+		s.emit(e)
+	}
+	return true
+}
+
+func (s *bnDepthBook) emit(e *binanceDepthEvent) {
+	if s.req.Levels > 0 {
+		s.emitSnapshot(e.Time)
+		return
+	}
+	asks, err := exchange.ParseDepthLevels(e.Asks)
+	if err != nil {
+		s.onError(err)
+		return
+	}
+	bids, err := exchange.ParseDepthLevels(e.Bids)
+	if err != nil {
+		s.onError(err)
+		return
+	}
+	s.req.Event(&exchange.DepthEvent{
+		Symbol:     s.symbol,
+		MarketType: s.req.MarketType,
+		Asks:       asks,
+		Bids:       bids,
+		UpdateID:   e.FinalUpdateID,
+		Time:       e.Time,
+	})
+}
+
+func (s *bnDepthBook) emitSnapshot(ts int64) {
+	asks, bids := s.book.Depth(s.req.Levels)
+	s.req.Event(&exchange.DepthEvent{
+		Symbol:     s.symbol,
+		MarketType: s.req.MarketType,
+		Asks:       asks,
+		Bids:       bids,
+		IsSnapshot: true,
+		UpdateID:   s.lastUpdateID,
+		Time:       ts,
+	})
+}
+
+func (s *bnDepthBook) snapshot() (*binanceDepthSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bnRequestTimeout)
+	defer cancel()
+
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: s.path,
+		SecType:  bnhttp.SecTypeNone,
+	}
+	r = r.SetParams(bnhttp.Params{"symbol": s.symbol, "limit": bnDepthSnapshotLimit})
+	data, err := s.client.CallAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &binanceDepthSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+func (s *bnDepthBook) onError(err error) {
+	if s.req.ErrorHandler != nil {
+		s.req.ErrorHandler(err)
+	}
+}
@@ -25,6 +25,8 @@ const (
 	bnSpotWsEndpoint         = "wss://stream.binance.com:9443/ws"
 	bnFuturesWsEndpoint      = "wss://fstream.binance.com/ws"
 	bnFunturesStreamEndpoint = "wss://fstream.binance.com/stream"
+	bnDeliveryWsEndpoint     = "wss://dstream.binance.com/ws"
+	bnDeliveryStreamEndpoint = "wss://dstream.binance.com/stream"
 )
 
 func NewBinanceDataFeed(limiter limiter.Limiter, opts ...Option) dfmanager.DataFeedManager {
@@ -39,10 +41,12 @@ func NewBinanceDataFeed(limiter limiter.Limiter, opts ...Option) dfmanager.DataF
 	}
 
 	return &df{
-		name:    exchange.BinanceExchange,
-		opts:    o,
-		limiter: limiter,
-		streams: make(map[string]dfmanager.Stream),
+		name:     exchange.BinanceExchange,
+		opts:     o,
+		limiter:  limiter,
+		streams:  make(map[string]dfmanager.Stream),
+		combined: make(map[string]*bnCombinedFeed),
+		pollers:  make(map[string]chan struct{}),
 		wsm: manager.NewManager(
 			manager.WithMaxConnDuration(o.maxConnDuration),
 		),
@@ -55,6 +59,10 @@ type df struct {
 	limiter limiter.Limiter
 	wsm     wsmanager.WebsocketManager
 	streams map[string]dfmanager.Stream
+	// 组合数据流订阅，一个订阅可能有多个连接
+	combined map[string]*bnCombinedFeed
+	// 轮询接口的订阅，关闭通道时停止轮询
+	pollers map[string]chan struct{}
 	mux     sync.RWMutex
 }
 
@@ -90,6 +98,9 @@ func (d *df) AddDataFeed(req *dfmanager.DataFeedRequest) error {
 	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
 		endpoint = fmt.Sprintf("%s/%s@aggTrade", bnFuturesWsEndpoint, symbol)
 		fn = futuresToTradeEvent
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		endpoint = fmt.Sprintf("%s/%s@aggTrade", bnDeliveryWsEndpoint, symbol)
+		fn = coinToTradeEvent(req.MarketType)
 	}
 	wsHandler := func(message []byte) {
 		te, err := fn(message)
@@ -141,6 +152,10 @@ func (d *df) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
 		symbol := strings.ToLower(req.Symbol)
 		endpoint = fmt.Sprintf("%s?streams=%s@markPrice@1s", bnFunturesStreamEndpoint, symbol)
 		fn = futuresMarkPriceToMarkPrice
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		symbol := strings.ToLower(req.Symbol)
+		endpoint = fmt.Sprintf("%s?streams=%s@markPrice@1s", bnDeliveryStreamEndpoint, symbol)
+		fn = futuresMarkPriceToMarkPrice
 	}
 	wsHandler := func(message []byte) {
 		te, err := fn(message)
@@ -171,6 +186,67 @@ func (d *df) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
 	return nil
 }
 
+// AddFundingRateDataFeed 资金费率来自标记价格推送，Symbol 为空时订阅全市场；
+// 币本位合约没有全市场推送，必须指定 Symbol
+func (d *df) AddFundingRateDataFeed(req *dfmanager.FundingRateRequest) error {
+	var endpoint string
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{
+		PingHandler: pingHandler,
+		PongHandler: pongHandler,
+	}
+	switch req.MarketType {
+	case exchange.MarketTypePerpetualUSDMargined:
+		if req.Symbol == "" {
+			endpoint = fmt.Sprintf("%s?streams=!markPrice@arr@1s", bnFunturesStreamEndpoint)
+		} else {
+			endpoint = fmt.Sprintf("%s?streams=%s@markPrice@1s", bnFunturesStreamEndpoint, strings.ToLower(req.Symbol))
+		}
+	case exchange.MarketTypePerpetualCoinMargined:
+		if req.Symbol == "" {
+			return exchange.ErrInvalidSymbol
+		}
+		endpoint = fmt.Sprintf("%s?streams=%s@markPrice@1s", bnDeliveryStreamEndpoint, strings.ToLower(req.Symbol))
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	wsHandler := func(message []byte) {
+		events, err := futuresMarkPriceToFundingRate(message, req.MarketType, req.Symbol == "")
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		for _, e := range events {
+			req.Event(e)
+		}
+	}
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:             req.ID,
+		Endpoint:       endpoint,
+		MessageHandler: wsHandler,
+		ErrorHandler:   req.ErrorHandler,
+	}, conf)
+	if err != nil {
+		return err
+	}
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      req.Symbol,
+		MarketType:  req.MarketType,
+		DataType:    "fundingRate",
+		IsConnected: true,
+	}
+	return nil
+}
+
 func (d *df) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
 	var (
 		endpoint string
@@ -195,6 +271,9 @@ func (d *df) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
 		endpoint = bnSpotWsEndpoint
 	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
 		endpoint = bnFuturesWsEndpoint
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		// 币本位合约K线成交量为张数，成交额为币的数量
+		endpoint = bnDeliveryWsEndpoint
 	}
 	wsHandler := func(message []byte) {
 		te, err := fn(message, req.MarketType)
@@ -225,6 +304,60 @@ func (d *df) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
 	return nil
 }
 
+// AddBookTickerDataFeed 订阅最优挂单，实时推送
+func (d *df) AddBookTickerDataFeed(req *dfmanager.BookTickerRequest) error {
+	var endpoint string
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	symbol := strings.ToLower(req.Symbol)
+	conf := &wsmanager.WebsocketConfig{
+		PingHandler: pingHandler,
+		PongHandler: pongHandler,
+	}
+	switch req.MarketType {
+	case exchange.MarketTypeSpot, exchange.MarketTypeMargin:
+		endpoint = fmt.Sprintf("%s/%s@bookTicker", bnSpotWsEndpoint, symbol)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		endpoint = fmt.Sprintf("%s/%s@bookTicker", bnFuturesWsEndpoint, symbol)
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		endpoint = fmt.Sprintf("%s/%s@bookTicker", bnDeliveryWsEndpoint, symbol)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	wsHandler := func(message []byte) {
+		te, err := toBookTickerEvent(message, req.MarketType)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:             req.ID,
+		Endpoint:       endpoint,
+		MessageHandler: wsHandler,
+		ErrorHandler:   req.ErrorHandler,
+	}, conf)
+	if err != nil {
+		return err
+	}
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      req.Symbol,
+		MarketType:  req.MarketType,
+		DataType:    "bookTicker",
+		IsConnected: true,
+	}
+	return nil
+}
+
 func (d *df) AddMarketKlineDataFeed(req *dfmanager.KlineMarketRequest) error {
 	return fmt.Errorf("not implemented")
 }
@@ -245,6 +378,19 @@ func (d *df) CloseDataFeed(id string) error {
 	d.mux.Lock()
 	defer d.mux.Unlock()
 
+	if f, ok := d.combined[id]; ok {
+		f.close()
+		delete(d.combined, id)
+		delete(d.streams, id)
+		return nil
+	}
+	if exit, ok := d.pollers[id]; ok {
+		close(exit)
+		delete(d.pollers, id)
+		delete(d.streams, id)
+		return nil
+	}
+
 	err := d.wsm.CloseWebsocket(id)
 	if err != nil {
 		return err
@@ -260,12 +406,24 @@ func (d *df) DataFeedList() []dfmanager.Stream {
 	list := make([]dfmanager.Stream, 0, len(d.streams))
 	for _, v := range d.streams {
 		v.IsConnected = d.wsm.IsConnected(v.UUID)
+		if f, ok := d.combined[v.UUID]; ok {
+			v.IsConnected = f.isConnected()
+		}
+		if _, ok := d.pollers[v.UUID]; ok {
+			v.IsConnected = true
+		}
 		list = append(list, v)
 	}
 	return list
 }
 
 func (d *df) Shutdown() error {
+	d.mux.Lock()
+	for id, exit := range d.pollers {
+		close(exit)
+		delete(d.pollers, id)
+	}
+	d.mux.Unlock()
 	err := d.wsm.Shutdown()
 	if err != nil {
 		return err
@@ -449,6 +607,48 @@ func futuresToTradeEvent(message []byte) (*exchange.TradeEvent, error) {
 	return te, nil
 }
 
+func toBookTickerEvent(message []byte, marketType exchange.MarketType) (*exchange.BookTickerEvent, error) {
+	e := &binanceBookTickerEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]decimal.Decimal, 0, 4)
+	for _, v := range []string{e.BidPrice, e.BidSize, e.AskPrice, e.AskSize} {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, d)
+	}
+	ts := e.TransactionTime
+	if ts == 0 {
+		ts = time.Now().UnixMilli()
+	}
+	return &exchange.BookTickerEvent{
+		Symbol:     e.Symbol,
+		MarketType: marketType,
+		BidPrice:   values[0],
+		BidSize:    values[1],
+		AskPrice:   values[2],
+		AskSize:    values[3],
+		UpdateID:   e.UpdateID,
+		Time:       ts,
+	}, nil
+}
+
+// coinToTradeEvent 币本位合约归集成交与 U 本位格式相同，数量单位为张
+func coinToTradeEvent(marketType exchange.MarketType) func(message []byte) (*exchange.TradeEvent, error) {
+	return func(message []byte) (*exchange.TradeEvent, error) {
+		te, err := futuresToTradeEvent(message)
+		if err != nil {
+			return nil, err
+		}
+		te.MarketType = marketType
+		return te, nil
+	}
+}
+
 func futuresMarkPriceToMarkPrice(message []byte) (*exchange.MarkPriceEvent, error) {
 	var e binanceFuturesMarkPriceSingleStream
 	err := json.Unmarshal(message, &e)
@@ -485,3 +685,48 @@ func futuresMarkPriceToMarkPrice(message []byte) (*exchange.MarkPriceEvent, erro
 
 	return te, nil
 }
+
+func futuresMarkPriceToFundingRate(message []byte, marketType exchange.MarketType, all bool) ([]*exchange.FundingRateEvent, error) {
+	var data []*binanceFuntureMarkPriceEvent
+	if all {
+		var e binanceFuturesMarkPriceStream
+		if err := json.Unmarshal(message, &e); err != nil {
+			return nil, err
+		}
+		data = e.Data
+	} else {
+		var e binanceFuturesMarkPriceSingleStream
+		if err := json.Unmarshal(message, &e); err != nil {
+			return nil, err
+		}
+		data = []*binanceFuntureMarkPriceEvent{e.Data}
+	}
+	events := make([]*exchange.FundingRateEvent, 0, len(data))
+	for _, v := range data {
+		if v == nil {
+			continue
+		}
+		fundingRate, err := decimal.NewFromString(v.LastFundingRate)
+		if err != nil {
+			return nil, err
+		}
+		markPrice, err := decimal.NewFromString(v.MarkPrice)
+		if err != nil {
+			markPrice = decimal.Zero
+		}
+		indexPrice, err := decimal.NewFromString(v.IndexPrice)
+		if err != nil {
+			indexPrice = decimal.Zero
+		}
+		events = append(events, &exchange.FundingRateEvent{
+			Symbol:          v.Symbol,
+			MarketType:      marketType,
+			MarkPrice:       markPrice,
+			IndexPrice:      indexPrice,
+			FundingRate:     fundingRate,
+			NextFundingTime: v.NextFundingTime,
+			Time:            v.Time,
+		})
+	}
+	return events, nil
+}
@@ -71,4 +71,56 @@ type binanceKlineEvent struct {
 		TakerQuote   string `json:"Q"`
 		Ignore       string `json:"B"`
 	} `json:"k"`
-}
\ No newline at end of file
+}
+// binanceDepthEvent 增量深度推送，合约有 pu（上一条推送的 u）
+type binanceDepthEvent struct {
+	Event         string     `json:"e"`
+	Time          int64      `json:"E"`
+	Symbol        string     `json:"s"`
+	FirstUpdateID int64      `json:"U"`
+	FinalUpdateID int64      `json:"u"`
+	PrevUpdateID  int64      `json:"pu"`
+	Bids          [][]string `json:"b"`
+	Asks          [][]string `json:"a"`
+}
+
+type binanceDepthSnapshot struct {
+	LastUpdateID int64      `json:"lastUpdateId"`
+	Time         int64      `json:"E"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// binanceBookTickerEvent 最优挂单推送，现货没有 E、T
+type binanceBookTickerEvent struct {
+	UpdateID        int64  `json:"u"`
+	Time            int64  `json:"E"`
+	TransactionTime int64  `json:"T"`
+	Symbol          string `json:"s"`
+	BidPrice        string `json:"b"`
+	BidSize         string `json:"B"`
+	AskPrice        string `json:"a"`
+	AskSize         string `json:"A"`
+}
+
+type binanceForceOrderEvent struct {
+	Event string `json:"e"`
+	Time  int64  `json:"E"`
+	Order struct {
+		Symbol       string `json:"s"`
+		Side         string `json:"S"`
+		OrderType    string `json:"o"`
+		Quantity     string `json:"q"`
+		Price        string `json:"p"`
+		AveragePrice string `json:"ap"`
+		Status       string `json:"X"`
+		FilledQty    string `json:"z"`
+		TradeTime    int64  `json:"T"`
+	} `json:"o"`
+}
+
+type binanceOpenInterest struct {
+	Symbol       string `json:"symbol"`
+	OpenInterest string `json:"openInterest"`
+	Time         int64  `json:"time"`
+}
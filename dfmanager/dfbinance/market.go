@@ -0,0 +1,208 @@
+package dfbinance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/limiter"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/go-gotop/kit/websocket"
+	"github.com/go-gotop/kit/wsmanager"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	"github.com/shopspring/decimal"
+)
+
+// bnOpenInterestInterval 持仓量默认轮询间隔，币安持仓量约 3 秒更新一次
+const bnOpenInterestInterval = 3 * time.Second
+
+// AddLiquidationDataFeed 订阅合约强平订单，Symbol 为空时订阅全市场，每个交易对每秒最多推送一条
+func (d *df) AddLiquidationDataFeed(req *dfmanager.LiquidationRequest) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	stream := "!forceOrder@arr"
+	if req.Symbol != "" {
+		stream = strings.ToLower(req.Symbol) + "@forceOrder"
+	}
+	var endpoint string
+	switch req.MarketType {
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		endpoint = fmt.Sprintf("%s/%s", bnFuturesWsEndpoint, stream)
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		endpoint = fmt.Sprintf("%s/%s", bnDeliveryWsEndpoint, stream)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+
+	conf := &wsmanager.WebsocketConfig{
+		PingHandler: pingHandler,
+		PongHandler: pongHandler,
+	}
+	wsHandler := func(message []byte) {
+		te, err := toLiquidationEvent(message, req.MarketType)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:             req.ID,
+		Endpoint:       endpoint,
+		MessageHandler: wsHandler,
+		ErrorHandler:   req.ErrorHandler,
+	}, conf)
+	if err != nil {
+		return err
+	}
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      req.Symbol,
+		MarketType:  req.MarketType,
+		DataType:    "liquidation",
+		IsConnected: true,
+	}
+	return nil
+}
+
+// AddOpenInterestDataFeed 币安没有持仓量推送，按 Interval 轮询接口，受普通请求限频时跳过本次轮询
+func (d *df) AddOpenInterestDataFeed(req *dfmanager.OpenInterestRequest) error {
+	if req.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, ok := d.streams[req.ID]; ok {
+		return errors.New("data feed already exists")
+	}
+
+	client := bnhttp.NewClient()
+	var path string
+	switch req.MarketType {
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		path = "/fapi/v1/openInterest"
+		client.SetApiEndpoint(bnFuturesEndpoint)
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		path = "/dapi/v1/openInterest"
+		client.SetApiEndpoint(bnDeliveryEndpoint)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+
+	interval := req.Interval
+	if interval <= 0 {
+		interval = bnOpenInterestInterval
+	}
+	exit := make(chan struct{})
+	poll := func() {
+		if !d.limiter.FutureAllow(&limiter.LimiterReq{LimiterType: limiter.NormalRequestLimit}) {
+			return
+		}
+		te, err := getOpenInterest(client, path, req)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll()
+		for {
+			select {
+			case <-exit:
+				return
+			case <-ticker.C:
+				poll()
+			}
+		}
+	}()
+
+	d.pollers[req.ID] = exit
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		Symbol:      req.Symbol,
+		MarketType:  req.MarketType,
+		DataType:    "openInterest",
+		IsConnected: true,
+	}
+	return nil
+}
+
+func getOpenInterest(client *bnhttp.Client, path string, req *dfmanager.OpenInterestRequest) (*exchange.OpenInterestEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bnRequestTimeout)
+	defer cancel()
+
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: path,
+		SecType:  bnhttp.SecTypeNone,
+	}
+	r = r.SetParams(bnhttp.Params{"symbol": strings.ToUpper(req.Symbol)})
+	data, err := client.CallAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &binanceOpenInterest{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	oi, err := decimal.NewFromString(res.OpenInterest)
+	if err != nil {
+		return nil, err
+	}
+	te := &exchange.OpenInterestEvent{
+		Symbol:       res.Symbol,
+		MarketType:   req.MarketType,
+		OpenInterest: oi,
+		Time:         res.Time,
+	}
+	if !req.MarketType.IsCoinMargined() {
+		te.OpenInterestCcy = oi
+	}
+	return te, nil
+}
+
+func toLiquidationEvent(message []byte, marketType exchange.MarketType) (*exchange.LiquidationEvent, error) {
+	e := &binanceForceOrderEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(e.Order.AveragePrice)
+	if err != nil || price.IsZero() {
+		price, err = decimal.NewFromString(e.Order.Price)
+		if err != nil {
+			return nil, err
+		}
+	}
+	size, err := decimal.NewFromString(e.Order.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.LiquidationEvent{
+		Symbol:     e.Order.Symbol,
+		MarketType: marketType,
+		Side:       exchange.SideType(e.Order.Side),
+		Price:      price,
+		Size:       size,
+		Time:       e.Order.TradeTime,
+	}, nil
+}
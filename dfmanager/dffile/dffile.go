@@ -70,12 +70,13 @@ func (d *df) AddDataFeed(req *dfmanager.DataFeedRequest) error {
 
 	tradeEventHandle := func(data *csv.TradeEvent) error {
 		req.Event(&exchange.TradeEvent{
-			TradeID:  fmt.Sprint(data.TradeID),
-			Size:     data.Size,
-			Price:    data.Price,
-			Side:     exchange.SideType(data.Side),
-			Symbol:   data.Symbol,
-			TradedAt: data.TradedAt,
+			TradeID:    fmt.Sprint(data.TradeID),
+			Size:       data.Size,
+			Price:      data.Price,
+			Side:       exchange.SideType(data.Side),
+			Symbol:     data.Symbol,
+			TradedAt:   data.TradedAt,
+			MarketType: req.MarketType,
 		})
 		return nil
 	}
@@ -107,6 +108,10 @@ func (d *df) AddDataFeed(req *dfmanager.DataFeedRequest) error {
 	return nil
 }
 
+func (d *df) AddFundingRateDataFeed(req *dfmanager.FundingRateRequest) error {
+	return errors.New("not implemented")
+}
+
 func (d *df) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
 	return errors.New("not implemented")
 }
@@ -119,6 +124,34 @@ func (d *df) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
 	return errors.New("not implemented")
 }
 
+func (d *df) AddDepthDataFeed(req *dfmanager.DepthRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddBookTickerDataFeed(req *dfmanager.BookTickerRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddMultiDataFeed(req *dfmanager.MultiDataFeedRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) SubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) UnsubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddLiquidationDataFeed(req *dfmanager.LiquidationRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddOpenInterestDataFeed(req *dfmanager.OpenInterestRequest) error {
+	return errors.New("not implemented")
+}
+
 func (d *df) AddMarketKlineDataFeed(req *dfmanager.KlineMarketRequest) error {
 	return fmt.Errorf("not implemented")
 }
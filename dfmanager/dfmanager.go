@@ -1,6 +1,8 @@
 package dfmanager
 
 import (
+	"time"
+
 	"github.com/go-gotop/kit/exchange"
 )
 
@@ -14,6 +16,15 @@ type DataFeedRequest struct {
 	ErrorHandler func(err error)
 }
 
+// MultiDataFeedRequest 多交易对成交订阅，多个交易对共用连接，可在不重连的情况下增减交易对
+type MultiDataFeedRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbols      []string
+	Event        func(data *exchange.TradeEvent)
+	ErrorHandler func(err error)
+}
+
 type MarkPriceRequest struct {
 	ID           string
 	MarketType   exchange.MarketType
@@ -22,6 +33,54 @@ type MarkPriceRequest struct {
 	ErrorHandler func(err error)
 }
 
+// FundingRateRequest 资金费率订阅，Symbol 为空时订阅全市场（仅币安支持）
+type FundingRateRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbol       string
+	Event        func(data *exchange.FundingRateEvent)
+	ErrorHandler func(err error)
+}
+
+// LiquidationRequest 强平订单订阅，Symbol 为空时订阅该市场类型的全部合约
+type LiquidationRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbol       string
+	Event        func(data *exchange.LiquidationEvent)
+	ErrorHandler func(err error)
+}
+
+// OpenInterestRequest 持仓量订阅，binance 没有持仓量推送，按 Interval 轮询接口，为空时 3 秒
+type OpenInterestRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbol       string
+	Interval     time.Duration
+	Event        func(data *exchange.OpenInterestEvent)
+	ErrorHandler func(err error)
+}
+
+// BookTickerRequest 最优挂单订阅
+type BookTickerRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbol       string
+	Event        func(data *exchange.BookTickerEvent)
+	ErrorHandler func(err error)
+}
+
+// DepthRequest 深度订阅，本地维护订单簿并在序号不连续或校验失败时自动重新同步；
+// Levels 大于零时每次更新推送前 Levels 档快照，否则同步后先推送全量快照，之后推送变化的档位
+type DepthRequest struct {
+	ID           string
+	MarketType   exchange.MarketType
+	Symbol       string
+	Levels       int
+	Event        func(data *exchange.DepthEvent)
+	ErrorHandler func(err error)
+}
+
 type KlineRequest struct {
 	ID           string
 	Symbol       string
@@ -60,10 +119,18 @@ type Stream struct {
 type DataFeedManager interface {
 	Name() string
 	AddDataFeed(req *DataFeedRequest) error
-	AddMarketPriceDataFeed(req *MarkPriceRequest) error   // 全市场最新标记价格
-	AddMarketKlineDataFeed(req *KlineMarketRequest) error // 全市场K线标记数据
+	AddMultiDataFeed(req *MultiDataFeedRequest) error      // 多交易对成交
+	SubscribeSymbols(id string, symbols ...string) error   // 多交易对订阅增加交易对
+	UnsubscribeSymbols(id string, symbols ...string) error // 多交易对订阅移除交易对
+	AddMarketPriceDataFeed(req *MarkPriceRequest) error    // 全市场最新标记价格
+	AddMarketKlineDataFeed(req *KlineMarketRequest) error  // 全市场K线标记数据
 	AddKlineDataFeed(req *KlineRequest) error
+	AddFundingRateDataFeed(req *FundingRateRequest) error   // 实时资金费率
 	AddSymbolUpdateDataFeed(req *SymbolUpdateRequest) error // 产品更新推送
+	AddDepthDataFeed(req *DepthRequest) error               // 订单簿深度
+	AddBookTickerDataFeed(req *BookTickerRequest) error     // 最优挂单
+	AddLiquidationDataFeed(req *LiquidationRequest) error   // 强平订单
+	AddOpenInterestDataFeed(req *OpenInterestRequest) error // 持仓量
 	CloseDataFeed(id string) error
 	DataFeedList() []Stream
 	WriteMessage(id string, message []byte) error
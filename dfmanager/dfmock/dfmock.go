@@ -94,6 +94,10 @@ func (d *df) AddDataFeed(req *dfmanager.DataFeedRequest) error {
 	return nil
 }
 
+func (d *df) AddFundingRateDataFeed(req *dfmanager.FundingRateRequest) error {
+	return errors.New("not implemented")
+}
+
 func (d *df) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
 	return errors.New("not implemented")
 	// var (
@@ -184,6 +188,34 @@ func (d *df) WriteMessage(id string, message []byte) error {
 	return errors.New("not implemented")
 }
 
+func (d *df) AddDepthDataFeed(req *dfmanager.DepthRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddBookTickerDataFeed(req *dfmanager.BookTickerRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddMultiDataFeed(req *dfmanager.MultiDataFeedRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) SubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) UnsubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddLiquidationDataFeed(req *dfmanager.LiquidationRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) AddOpenInterestDataFeed(req *dfmanager.OpenInterestRequest) error {
+	return errors.New("not implemented")
+}
+
 func (d *df) AddMarketKlineDataFeed(req *dfmanager.KlineMarketRequest) error {
 	return fmt.Errorf("not implemented")
 }
@@ -0,0 +1,312 @@
+package dfokx
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/go-gotop/kit/websocket"
+	"github.com/go-gotop/kit/wsmanager"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	"github.com/go-kratos/kratos/v2/log"
+	gwebsocket "github.com/gorilla/websocket"
+)
+
+const (
+	okDepthChecksumLevels = 25 // 校验和使用的档位数量
+	okBooks5Levels        = 5
+)
+
+// AddDepthDataFeed Levels 在 1-5 之间时订阅 books5 全量推送，否则订阅 books 增量推送并维护本地订单簿，
+// seqId 不连续或校验和不一致时重新订阅获取快照
+func (d *df) AddDepthDataFeed(req *dfmanager.DepthRequest) error {
+	if req.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{}
+
+	channel := "books"
+	if req.Levels > 0 && req.Levels <= okBooks5Levels {
+		channel = "books5"
+	}
+	book := &okDepthBook{
+		req:    req,
+		book:   exchange.NewOrderBook(),
+		logger: d.opts.logger,
+		resubscribe: func() {
+			conn := d.wsm.GetWebsocket(req.ID)
+			if conn == nil {
+				return
+			}
+			for _, op := range []string{"unsubscribe", "subscribe"} {
+				str, err := instIdSubMessage(op, channel, req.Symbol)
+				if err == nil {
+					err = conn.WriteMessage(gwebsocket.TextMessage, str)
+				}
+				if err != nil {
+					if req.ErrorHandler != nil {
+						req.ErrorHandler(err)
+					}
+					return
+				}
+			}
+		},
+	}
+
+	endpoint := okWsEndpoint + "/ws/v5/public"
+	wsHandler := func(message []byte) {
+		if string(message) == "pong" {
+			// 每隔20s发送ping过去，预期会收到pong
+			return
+		}
+		j, err := okhttp.NewJSON(message)
+		if err != nil {
+			d.opts.logger.Error("new json error", err)
+			return
+		}
+		if j.Get("event").MustString() == "error" {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(errors.New(j.Get("msg").MustString()))
+			}
+			return
+		}
+
+		if j.Get("event").MustString() != "" {
+			return
+		}
+
+		book.onMessage(message)
+	}
+
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:               req.ID,
+		Endpoint:         endpoint,
+		MessageHandler:   wsHandler,
+		ErrorHandler:     d.errorDepthHandler(req.ID, req),
+		ConnectedHandler: d.connectedDepthHandler(req, channel),
+	}, conf)
+	if err != nil {
+		return err
+	}
+
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		MarketType:  req.MarketType,
+		Symbol:      req.Symbol,
+		DataType:    "depth",
+		IsConnected: true,
+	}
+
+	return nil
+}
+
+// 连接成功后订阅深度
+func (d *df) connectedDepthHandler(req *dfmanager.DepthRequest, channel string) func(id string, conn websocket.WebSocketConn) {
+	return func(id string, conn websocket.WebSocketConn) {
+		str, err := instIdSubMessage("subscribe", channel, req.Symbol)
+		if err == nil {
+			err = conn.WriteMessage(gwebsocket.TextMessage, str)
+		}
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+		}
+	}
+}
+
+func (d *df) errorDepthHandler(id string, req *dfmanager.DepthRequest) func(err error) {
+	return func(err error) {
+		if req.ErrorHandler != nil {
+			req.ErrorHandler(err)
+		}
+		go d.wsm.Reconnect(id)
+		// 开启一个计时器，10秒后再次检查连接状态，如果连接已经关闭，则删除连接
+		time.AfterFunc(10*time.Second, func() {
+			if !d.wsm.GetWebsocket(id).IsConnected() {
+				if req.ErrorHandler != nil {
+					req.ErrorHandler(manager.ErrReconnectFailed)
+				}
+				d.wsm.CloseWebsocket(id)
+			}
+		})
+	}
+}
+
+// instIdSubMessage 按产品 ID 订阅或取消订阅频道
+func instIdSubMessage(op, channel, instId string) ([]byte, error) {
+	sub := wsSub{
+		Op: op,
+		Args: []struct {
+			Channel string `json:"channel"`
+			InstID  string `json:"instId"`
+		}{
+			{
+				Channel: channel,
+				InstID:  instId,
+			},
+		},
+	}
+
+	return json.Marshal(sub)
+}
+
+// okDepthBook okx 本地订单簿，books 频道先推送快照再推送增量，
+// 增量的 prevSeqId 需等于上一条的 seqId，更新后前25档的校验和需与 checksum 一致
+type okDepthBook struct {
+	req         *dfmanager.DepthRequest
+	book        *exchange.OrderBook
+	seqID       int64
+	synced      bool
+	resubscribe func()
+	logger      *log.Helper
+	mux         sync.Mutex
+}
+
+func (s *okDepthBook) onMessage(message []byte) {
+	e := &okxDepthEvent{}
+	if err := json.Unmarshal(message, e); err != nil {
+		s.onError(err)
+		return
+	}
+	if len(e.Data) == 0 {
+		s.onError(errors.New("data is empty"))
+		return
+	}
+	data := e.Data[0]
+	ts, err := strconv.ParseInt(data.Timestamp, 10, 64)
+	if err != nil {
+		s.onError(err)
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	switch e.Action {
+	case "":
+		// books5 每次推送全量档位
+		if err := s.book.Reset(data.Asks, data.Bids); err != nil {
+			s.onError(err)
+			return
+		}
+		s.seqID = data.SeqID
+		s.emitSnapshot(e.Arg.InstID, ts)
+	case "snapshot":
+		if err := s.book.Reset(data.Asks, data.Bids); err != nil {
+			s.onError(err)
+			return
+		}
+		s.seqID = data.SeqID
+		if !s.verify(data.Checksum) {
+			s.resync("checksum mismatch")
+			return
+		}
+		s.synced = true
+		s.emitSnapshot(e.Arg.InstID, ts)
+	case "update":
+		if !s.synced {
+			// 等待重新订阅后的快照
+			return
+		}
+		// 长时间没有变化时推送空的增量，seqId 与 prevSeqId 相同
+		if data.PrevSeqID != s.seqID {
+			s.resync("out of sequence")
+			return
+		}
+		if err := s.book.Update(data.Asks, data.Bids); err != nil {
+			s.onError(err)
+			s.resync("invalid update")
+			return
+		}
+		s.seqID = data.SeqID
+		if !s.verify(data.Checksum) {
+			s.resync("checksum mismatch")
+			return
+		}
+		s.emit(e.Arg.InstID, data, ts)
+	}
+}
+
+// resync 调用方需持有锁
+func (s *okDepthBook) resync(reason string) {
+	s.logger.Warnf("okx depth %s %s, resync", s.req.Symbol, reason)
+	s.synced = false
+	s.resubscribe()
+}
+
+// verify 按 bid1:ask1:bid2:ask2... 拼接前25档的原始价格和数量，crc32 结果与 checksum 比较
+func (s *okDepthBook) verify(checksum int32) bool {
+	asks, bids := s.book.Levels(okDepthChecksumLevels)
+	parts := make([]string, 0, 4*okDepthChecksumLevels)
+	for i := 0; i < okDepthChecksumLevels; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i].RawPrice, bids[i].RawSize)
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i].RawPrice, asks[i].RawSize)
+		}
+	}
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":")))) == checksum
+}
+
+func (s *okDepthBook) emit(symbol string, data okxDepthData, ts int64) {
+	if s.req.Levels > 0 {
+		s.emitSnapshot(symbol, ts)
+		return
+	}
+	if len(data.Asks) == 0 && len(data.Bids) == 0 {
+		return
+	}
+	asks, err := exchange.ParseDepthLevels(data.Asks)
+	if err != nil {
+		s.onError(err)
+		return
+	}
+	bids, err := exchange.ParseDepthLevels(data.Bids)
+	if err != nil {
+		s.onError(err)
+		return
+	}
+	s.req.Event(&exchange.DepthEvent{
+		Symbol:     symbol,
+		MarketType: s.req.MarketType,
+		Asks:       asks,
+		Bids:       bids,
+		UpdateID:   data.SeqID,
+		Time:       ts,
+	})
+}
+
+func (s *okDepthBook) emitSnapshot(symbol string, ts int64) {
+	asks, bids := s.book.Depth(s.req.Levels)
+	s.req.Event(&exchange.DepthEvent{
+		Symbol:     symbol,
+		MarketType: s.req.MarketType,
+		Asks:       asks,
+		Bids:       bids,
+		IsSnapshot: true,
+		UpdateID:   s.seqID,
+		Time:       ts,
+	})
+}
+
+func (s *okDepthBook) onError(err error) {
+	if s.req.ErrorHandler != nil {
+		s.req.ErrorHandler(err)
+	}
+}
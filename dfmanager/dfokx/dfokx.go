@@ -11,6 +11,7 @@ import (
 
 	"github.com/go-gotop/kit/dfmanager"
 	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/okexc"
 	"github.com/go-gotop/kit/limiter"
 	"github.com/go-gotop/kit/requests/okhttp"
 	"github.com/go-gotop/kit/websocket"
@@ -210,6 +211,18 @@ func (d *df) AddMarketPriceDataFeed(req *dfmanager.MarkPriceRequest) error {
 	return nil
 }
 
+func (d *df) AddMultiDataFeed(req *dfmanager.MultiDataFeedRequest) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) SubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
+func (d *df) UnsubscribeSymbols(id string, symbols ...string) error {
+	return errors.New("not implemented")
+}
+
 func (d *df) AddMarketKlineDataFeed(req *dfmanager.KlineMarketRequest) error {
 	d.mux.Lock()
 	defer d.mux.Unlock()
@@ -337,6 +350,142 @@ func (d *df) AddKlineDataFeed(req *dfmanager.KlineRequest) error {
 	return nil
 }
 
+// AddFundingRateDataFeed 订阅永续合约资金费率，okx 约每 30-90 秒推送一次
+func (d *df) AddFundingRateDataFeed(req *dfmanager.FundingRateRequest) error {
+	if req.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{}
+
+	endpoint := okWsEndpoint + "/ws/v5/public"
+	wsHandler := func(message []byte) {
+		if string(message) == "pong" {
+			// 每隔20s发送ping过去，预期会收到pong
+			return
+		}
+		j, err := okhttp.NewJSON(message)
+		if err != nil {
+			d.opts.logger.Error("new json error", err)
+			return
+		}
+		if j.Get("event").MustString() == "error" {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(errors.New(j.Get("msg").MustString()))
+			}
+			return
+		}
+
+		if j.Get("event").MustString() != "" {
+			return
+		}
+
+		te, err := toFundingRateEvent(message)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:               req.ID,
+		Endpoint:         endpoint,
+		MessageHandler:   wsHandler,
+		ErrorHandler:     d.errorFundingRateHandler(req.ID, req),
+		ConnectedHandler: d.connectedFundingRateHandler(req),
+	}, conf)
+	if err != nil {
+		return err
+	}
+
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		MarketType:  req.MarketType,
+		Symbol:      req.Symbol,
+		DataType:    "fundingRate",
+		IsConnected: true,
+	}
+
+	return nil
+}
+
+// AddBookTickerDataFeed 订阅 bbo-tbt 最优挂单，每 10 毫秒推送一次
+func (d *df) AddBookTickerDataFeed(req *dfmanager.BookTickerRequest) error {
+	if req.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{}
+
+	endpoint := okWsEndpoint + "/ws/v5/public"
+	wsHandler := func(message []byte) {
+		if string(message) == "pong" {
+			// 每隔20s发送ping过去，预期会收到pong
+			return
+		}
+		j, err := okhttp.NewJSON(message)
+		if err != nil {
+			d.opts.logger.Error("new json error", err)
+			return
+		}
+		if j.Get("event").MustString() == "error" {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(errors.New(j.Get("msg").MustString()))
+			}
+			return
+		}
+
+		if j.Get("event").MustString() != "" {
+			return
+		}
+
+		te, err := toBookTickerEvent(message, req.MarketType)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:               req.ID,
+		Endpoint:         endpoint,
+		MessageHandler:   wsHandler,
+		ErrorHandler:     d.errorBookTickerHandler(req.ID, req),
+		ConnectedHandler: d.connectedBookTickerHandler(req),
+	}, conf)
+	if err != nil {
+		return err
+	}
+
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		MarketType:  req.MarketType,
+		Symbol:      req.Symbol,
+		DataType:    "bookTicker",
+		IsConnected: true,
+	}
+
+	return nil
+}
+
 func (d *df) AddSymbolUpdateDataFeed(req *dfmanager.SymbolUpdateRequest) error {
 	d.mux.Lock()
 	defer d.mux.Unlock()
@@ -526,10 +675,62 @@ func (d *df) connectedMarketKlineHandler(req *dfmanager.KlineMarketRequest) func
 	}
 }
 
+// 连接成功后订阅资金费率
+func (d *df) connectedFundingRateHandler(req *dfmanager.FundingRateRequest) func(id string, conn websocket.WebSocketConn) {
+	return func(id string, conn websocket.WebSocketConn) {
+		sub := wsSub{
+			Op: "subscribe",
+			Args: []struct {
+				Channel string `json:"channel"`
+				InstID  string `json:"instId"`
+			}{
+				{
+					Channel: "funding-rate",
+					InstID:  req.Symbol,
+				},
+			},
+		}
+
+		str, err := json.Marshal(sub)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+
+		err = conn.WriteMessage(gwebsocket.TextMessage, str)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+		}
+	}
+}
+
+// 连接成功后订阅最优挂单
+func (d *df) connectedBookTickerHandler(req *dfmanager.BookTickerRequest) func(id string, conn websocket.WebSocketConn) {
+	return func(id string, conn websocket.WebSocketConn) {
+		str, err := instIdSubMessage("subscribe", "bbo-tbt", req.Symbol)
+		if err == nil {
+			err = conn.WriteMessage(gwebsocket.TextMessage, str)
+		}
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+		}
+	}
+}
+
 func (d *df) connectedSymbolUpdateHandler(req *dfmanager.SymbolUpdateRequest) func(id string, conn websocket.WebSocketConn) {
 	return func(id string, conn websocket.WebSocketConn) {
 		// ws := d.wsm.GetWebsocket(id)
 		fmt.Println("symbolupdate链接成功回调:", req.MarketType)
+		instType := okexc.OkxInstType(req.MarketType)
+		if instType == "" {
+			instType = string(req.MarketType)
+		}
 		sub := wsInstTypeSub{
 			Op: "subscribe",
 			Args: []struct {
@@ -538,7 +739,7 @@ func (d *df) connectedSymbolUpdateHandler(req *dfmanager.SymbolUpdateRequest) fu
 			}{
 				{
 					Channel:  "instruments",
-					InstType: string(req.MarketType),
+					InstType: instType,
 				},
 			},
 		}
@@ -596,6 +797,42 @@ func (d *df) errorMarkPriceHandler(id string, req *dfmanager.MarkPriceRequest) f
 	}
 }
 
+func (d *df) errorFundingRateHandler(id string, req *dfmanager.FundingRateRequest) func(err error) {
+	return func(err error) {
+		if req.ErrorHandler != nil {
+			req.ErrorHandler(err)
+		}
+		go d.wsm.Reconnect(id)
+		// 开启一个计时器，10秒后再次检查连接状态，如果连接已经关闭，则删除连接
+		time.AfterFunc(10*time.Second, func() {
+			if !d.wsm.GetWebsocket(id).IsConnected() {
+				if req.ErrorHandler != nil {
+					req.ErrorHandler(manager.ErrReconnectFailed)
+				}
+				d.wsm.CloseWebsocket(id)
+			}
+		})
+	}
+}
+
+func (d *df) errorBookTickerHandler(id string, req *dfmanager.BookTickerRequest) func(err error) {
+	return func(err error) {
+		if req.ErrorHandler != nil {
+			req.ErrorHandler(err)
+		}
+		go d.wsm.Reconnect(id)
+		// 开启一个计时器，10秒后再次检查连接状态，如果连接已经关闭，则删除连接
+		time.AfterFunc(10*time.Second, func() {
+			if !d.wsm.GetWebsocket(id).IsConnected() {
+				if req.ErrorHandler != nil {
+					req.ErrorHandler(manager.ErrReconnectFailed)
+				}
+				d.wsm.CloseWebsocket(id)
+			}
+		})
+	}
+}
+
 func (d *df) errorMarkKlineHandler(id string, req *dfmanager.KlineMarketRequest) func(err error) {
 	return func(err error) {
 		if req.ErrorHandler != nil {
@@ -765,6 +1002,83 @@ func toMarkPriceEvent(message []byte, marketType exchange.MarketType) (*exchange
 	return te, nil
 }
 
+// toFundingRateEvent fundingRate 为下一次结算的预测资金费率，fundingTime 为下一次结算时间
+func toFundingRateEvent(message []byte) (*exchange.FundingRateEvent, error) {
+	e := &okxFundingRateEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.Data) == 0 {
+		return nil, errors.New("data is empty")
+	}
+
+	data := e.Data[0]
+
+	ts, err := strconv.ParseInt(data.Timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	fundingTime, err := strconv.ParseInt(data.FundingTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := decimal.NewFromString(data.FundingRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchange.FundingRateEvent{
+		Symbol:          data.InstID,
+		MarketType:      okexc.OkxMarketType(data.InstType, data.InstID),
+		FundingRate:     rate,
+		NextFundingTime: fundingTime,
+		Time:            ts,
+	}, nil
+}
+
+// toBookTickerEvent bbo-tbt 档位为 [价格, 数量, 0, 订单数量]
+func toBookTickerEvent(message []byte, marketType exchange.MarketType) (*exchange.BookTickerEvent, error) {
+	e := &okxDepthEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.Data) == 0 {
+		return nil, errors.New("data is empty")
+	}
+
+	data := e.Data[0]
+	ts, err := strconv.ParseInt(data.Timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := exchange.ParseDepthLevels(data.Asks)
+	if err != nil {
+		return nil, err
+	}
+	bids, err := exchange.ParseDepthLevels(data.Bids)
+	if err != nil {
+		return nil, err
+	}
+
+	te := &exchange.BookTickerEvent{
+		Symbol:     e.Arg.InstID,
+		MarketType: marketType,
+		UpdateID:   data.SeqID,
+		Time:       ts,
+	}
+	if len(bids) > 0 {
+		te.BidPrice, te.BidSize = bids[0][0], bids[0][1]
+	}
+	if len(asks) > 0 {
+		te.AskPrice, te.AskSize = asks[0][0], asks[0][1]
+	}
+	return te, nil
+}
+
 func toMarkKlineEvent(message []byte, marketType exchange.MarketType) (*exchange.KlineMarketEvent, error) {
 	e := &okxMarkKlineEvent{}
 	err := json.Unmarshal(message, e)
@@ -888,6 +1202,14 @@ func toSymbolUpdateEvent(message []byte, marketType exchange.MarketType) ([]*exc
 	result := make([]*exchange.SymbolUpdateEvent, 0, len(e.Data))
 
 	for _, v := range e.Data {
+		mt := marketType
+		if marketType.IsContract() {
+			// SWAP、FUTURES 同时推送 U 本位和币本位合约
+			mt = okexc.OkxMarketType(v.InstType, v.InstID)
+			if mt.IsCoinMargined() != marketType.IsCoinMargined() {
+				continue
+			}
+		}
 		minsz, err := decimal.NewFromString(v.MinSz)
 		if err != nil {
 			return nil, err
@@ -924,24 +1246,26 @@ func toSymbolUpdateEvent(message []byte, marketType exchange.MarketType) ([]*exc
 			expTime = 0
 		}
 
-		pricePrecision, err := findFirstNonZeroDigitAfterDecimal(v.TickSz)
+		tickSize, err := decimal.NewFromString(v.TickSz)
 		if err != nil {
 			return nil, err
 		}
 
-		sizePrecision, err := findFirstNonZeroDigitAfterDecimal(v.LotSz)
+		stepSize, err := decimal.NewFromString(v.LotSz)
 		if err != nil {
 			return nil, err
 		}
 
 		te := &exchange.SymbolUpdateEvent{
-			MarketType:     marketType,
+			MarketType:     mt,
 			OriginalSymbol: v.InstID,
 			OriginalAsset:  v.BaseCcy,
 			MinSize:        minsz,
 			MaxSize:        maxsz,
-			PricePrecision: pricePrecision,
-			SizePrecision:  sizePrecision,
+			PricePrecision: exchange.StepPrecision(v.TickSz),
+			SizePrecision:  exchange.StepPrecision(v.LotSz),
+			TickSize:       tickSize,
+			StepSize:       stepSize,
 			CtVal:          ctval,
 			CtMult:         ctmult,
 			ListTime:       listTime,
@@ -954,27 +1278,3 @@ func toSymbolUpdateEvent(message []byte, marketType exchange.MarketType) ([]*exc
 
 	return result, nil
 }
-
-func findFirstNonZeroDigitAfterDecimal(value string) (int32, error) {
-	// Check if the input is a valid string type
-	if value == "" {
-		return 0, errors.New("unsupported type")
-	}
-
-	// Find the position of the decimal point
-	dotIndex := strings.Index(value, ".")
-	if dotIndex == -1 {
-		return 0, nil // No decimal point, so no digits after it
-	}
-
-	// Traverse the string after the decimal point to find the first non-zero digit
-	for i := dotIndex + 1; i < len(value); i++ {
-		if value[i] != '0' {
-			// Calculate the position of the first non-zero digit after the decimal point
-			return int32(i - dotIndex), nil
-		}
-	}
-
-	// No non-zero digits found after the decimal point
-	return 0, nil
-}
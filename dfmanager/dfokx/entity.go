@@ -10,6 +10,11 @@ type okxMarkPriceEvent struct {
 	Data []okxMarkPriceData `json:"data"`
 }
 
+type okxFundingRateEvent struct {
+	Arg  okxAllTradeArg       `json:"arg"`
+	Data []okxFundingRateData `json:"data"`
+}
+
 type okxKlineEvent struct {
 	Arg  okxAllTradeArg `json:"arg"`
 	Data [][]string     `json:"data"`
@@ -51,6 +56,16 @@ type okxMarkPriceData struct {
 	Timestamp string `json:"ts"`
 }
 
+type okxFundingRateData struct {
+	InstID          string `json:"instId"`
+	InstType        string `json:"instType"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingRate string `json:"nextFundingRate"`
+	FundingTime     string `json:"fundingTime"`
+	NextFundingTime string `json:"nextFundingTime"`
+	Timestamp       string `json:"ts"`
+}
+
 type okxSymbolUpdateData struct {
 	InstType string `json:"instType"`
 	InstID   string `json:"instId"`
@@ -68,3 +83,49 @@ type okxSymbolUpdateData struct {
 	MaxLmtSz string `json:"maxLmtSz"`
 	MaxMktSz string `json:"maxMktSz"`
 }
+
+// okxDepthEvent books 频道 action 为 snapshot 或 update，books5 没有 action，每次推送全量5档
+type okxDepthEvent struct {
+	Arg    okxAllTradeArg `json:"arg"`
+	Action string         `json:"action"`
+	Data   []okxDepthData `json:"data"`
+}
+
+type okxDepthData struct {
+	Asks      [][]string `json:"asks"`
+	Bids      [][]string `json:"bids"`
+	Timestamp string     `json:"ts"`
+	Checksum  int32      `json:"checksum"`
+	PrevSeqID int64      `json:"prevSeqId"`
+	SeqID     int64      `json:"seqId"`
+}
+
+type okxLiquidationEvent struct {
+	Arg  okxInstrumentArg     `json:"arg"`
+	Data []okxLiquidationData `json:"data"`
+}
+
+type okxLiquidationData struct {
+	InstID   string `json:"instId"`
+	InstType string `json:"instType"`
+	Details  []struct {
+		BkPx    string `json:"bkPx"` // 破产价格
+		PosSide string `json:"posSide"`
+		Side    string `json:"side"`
+		Sz      string `json:"sz"`
+		Ts      string `json:"ts"`
+	} `json:"details"`
+}
+
+type okxOpenInterestEvent struct {
+	Arg  okxAllTradeArg        `json:"arg"`
+	Data []okxOpenInterestData `json:"data"`
+}
+
+type okxOpenInterestData struct {
+	InstID    string `json:"instId"`
+	InstType  string `json:"instType"`
+	Oi        string `json:"oi"`
+	OiCcy     string `json:"oiCcy"`
+	Timestamp string `json:"ts"`
+}
@@ -0,0 +1,296 @@
+package dfokx
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/okexc"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/go-gotop/kit/websocket"
+	"github.com/go-gotop/kit/wsmanager"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	gwebsocket "github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// AddLiquidationDataFeed 订阅 liquidation-orders 强平订单，okx 按产品类型推送，Symbol 不为空时只推送该产品
+func (d *df) AddLiquidationDataFeed(req *dfmanager.LiquidationRequest) error {
+	if !req.MarketType.IsContract() {
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{}
+
+	endpoint := okWsEndpoint + "/ws/v5/public"
+	wsHandler := func(message []byte) {
+		if string(message) == "pong" {
+			// 每隔20s发送ping过去，预期会收到pong
+			return
+		}
+		j, err := okhttp.NewJSON(message)
+		if err != nil {
+			d.opts.logger.Error("new json error", err)
+			return
+		}
+		if j.Get("event").MustString() == "error" {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(errors.New(j.Get("msg").MustString()))
+			}
+			return
+		}
+
+		if j.Get("event").MustString() != "" {
+			return
+		}
+
+		events, err := toLiquidationEvents(message, req)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		for _, e := range events {
+			req.Event(e)
+		}
+	}
+
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:               req.ID,
+		Endpoint:         endpoint,
+		MessageHandler:   wsHandler,
+		ErrorHandler:     d.errorMarketHandler(req.ID, req.ErrorHandler),
+		ConnectedHandler: d.connectedLiquidationHandler(req),
+	}, conf)
+	if err != nil {
+		return err
+	}
+
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		MarketType:  req.MarketType,
+		Symbol:      req.Symbol,
+		DataType:    "liquidation",
+		IsConnected: true,
+	}
+
+	return nil
+}
+
+// AddOpenInterestDataFeed 订阅 open-interest 持仓量，每 3 秒推送一次
+func (d *df) AddOpenInterestDataFeed(req *dfmanager.OpenInterestRequest) error {
+	if req.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if !d.limiter.WsAllow() {
+		return manager.ErrLimitExceed
+	}
+
+	conf := &wsmanager.WebsocketConfig{}
+
+	endpoint := okWsEndpoint + "/ws/v5/public"
+	wsHandler := func(message []byte) {
+		if string(message) == "pong" {
+			// 每隔20s发送ping过去，预期会收到pong
+			return
+		}
+		j, err := okhttp.NewJSON(message)
+		if err != nil {
+			d.opts.logger.Error("new json error", err)
+			return
+		}
+		if j.Get("event").MustString() == "error" {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(errors.New(j.Get("msg").MustString()))
+			}
+			return
+		}
+
+		if j.Get("event").MustString() != "" {
+			return
+		}
+
+		te, err := toOpenInterestEvent(message, req.MarketType)
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+			return
+		}
+		req.Event(te)
+	}
+
+	err := d.addWebsocket(&websocket.WebsocketRequest{
+		ID:               req.ID,
+		Endpoint:         endpoint,
+		MessageHandler:   wsHandler,
+		ErrorHandler:     d.errorMarketHandler(req.ID, req.ErrorHandler),
+		ConnectedHandler: d.connectedOpenInterestHandler(req),
+	}, conf)
+	if err != nil {
+		return err
+	}
+
+	d.streams[req.ID] = dfmanager.Stream{
+		UUID:        req.ID,
+		MarketType:  req.MarketType,
+		Symbol:      req.Symbol,
+		DataType:    "openInterest",
+		IsConnected: true,
+	}
+
+	return nil
+}
+
+// 连接成功后按产品类型订阅强平订单
+func (d *df) connectedLiquidationHandler(req *dfmanager.LiquidationRequest) func(id string, conn websocket.WebSocketConn) {
+	return func(id string, conn websocket.WebSocketConn) {
+		sub := wsInstTypeSub{
+			Op: "subscribe",
+			Args: []struct {
+				Channel  string `json:"channel"`
+				InstType string `json:"instType"`
+			}{
+				{
+					Channel:  "liquidation-orders",
+					InstType: okexc.OkxInstType(req.MarketType),
+				},
+			},
+		}
+
+		str, err := json.Marshal(sub)
+		if err == nil {
+			err = conn.WriteMessage(gwebsocket.TextMessage, str)
+		}
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+		}
+	}
+}
+
+// 连接成功后订阅持仓量
+func (d *df) connectedOpenInterestHandler(req *dfmanager.OpenInterestRequest) func(id string, conn websocket.WebSocketConn) {
+	return func(id string, conn websocket.WebSocketConn) {
+		str, err := instIdSubMessage("subscribe", "open-interest", req.Symbol)
+		if err == nil {
+			err = conn.WriteMessage(gwebsocket.TextMessage, str)
+		}
+		if err != nil {
+			if req.ErrorHandler != nil {
+				req.ErrorHandler(err)
+			}
+		}
+	}
+}
+
+func (d *df) errorMarketHandler(id string, errorHandler func(err error)) func(err error) {
+	return func(err error) {
+		if errorHandler != nil {
+			errorHandler(err)
+		}
+		go d.wsm.Reconnect(id)
+		// 开启一个计时器，10秒后再次检查连接状态，如果连接已经关闭，则删除连接
+		time.AfterFunc(10*time.Second, func() {
+			if !d.wsm.GetWebsocket(id).IsConnected() {
+				if errorHandler != nil {
+					errorHandler(manager.ErrReconnectFailed)
+				}
+				d.wsm.CloseWebsocket(id)
+			}
+		})
+	}
+}
+
+// toLiquidationEvents 同一产品类型下 U 本位和币本位合约一起推送，只保留与订阅相同本位的合约
+func toLiquidationEvents(message []byte, req *dfmanager.LiquidationRequest) ([]*exchange.LiquidationEvent, error) {
+	e := &okxLiquidationEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*exchange.LiquidationEvent, 0, len(e.Data))
+	for _, v := range e.Data {
+		if req.Symbol != "" && v.InstID != req.Symbol {
+			continue
+		}
+		marketType := okexc.OkxMarketType(v.InstType, v.InstID)
+		if marketType.IsCoinMargined() != req.MarketType.IsCoinMargined() {
+			continue
+		}
+		for _, detail := range v.Details {
+			price, err := decimal.NewFromString(detail.BkPx)
+			if err != nil {
+				return nil, err
+			}
+			size, err := decimal.NewFromString(detail.Sz)
+			if err != nil {
+				return nil, err
+			}
+			ts, err := strconv.ParseInt(detail.Ts, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, &exchange.LiquidationEvent{
+				Symbol:       v.InstID,
+				MarketType:   marketType,
+				Side:         exchange.SideType(strings.ToUpper(detail.Side)),
+				PositionSide: okexc.OkxTPositionSide(detail.PosSide),
+				Price:        price,
+				Size:         size,
+				Time:         ts,
+			})
+		}
+	}
+	return result, nil
+}
+
+func toOpenInterestEvent(message []byte, marketType exchange.MarketType) (*exchange.OpenInterestEvent, error) {
+	e := &okxOpenInterestEvent{}
+	err := json.Unmarshal(message, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.Data) == 0 {
+		return nil, errors.New("data is empty")
+	}
+
+	data := e.Data[0]
+	oi, err := decimal.NewFromString(data.Oi)
+	if err != nil {
+		return nil, err
+	}
+	oiCcy, err := decimal.NewFromString(data.OiCcy)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := strconv.ParseInt(data.Timestamp, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchange.OpenInterestEvent{
+		Symbol:          data.InstID,
+		MarketType:      marketType,
+		OpenInterest:    oi,
+		OpenInterestCcy: oiCcy,
+		Time:            ts,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package dfmanager
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/wsmanager/manager"
+)
+
+var ErrSubscriptionExists = errors.New("subscription already exists")
+
+// Subscriber 包装 DataFeedManager，提供通道和迭代器形式的订阅，
+// 通过 Subscriber 调用 CloseDataFeed 或 Shutdown 时同时关闭对应的订阅；
+// 数据源重连失败后会关闭连接并推送 manager.ErrReconnectFailed，此时订阅同样关闭
+type Subscriber struct {
+	DataFeedManager
+	subs map[string]func() bool
+	mux  sync.Mutex
+}
+
+func NewSubscriber(m DataFeedManager) *Subscriber {
+	return &Subscriber{
+		DataFeedManager: m,
+		subs:            make(map[string]func() bool),
+	}
+}
+
+// SubscribeTrades 订阅成交，忽略 req.Event
+func (s *Subscriber) SubscribeTrades(req *DataFeedRequest, conf SubscriptionConfig) (*Subscription[*exchange.TradeEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.TradeEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddDataFeed(req)
+	})
+}
+
+// SubscribeMultiTrades 订阅多交易对成交，忽略 req.Event
+func (s *Subscriber) SubscribeMultiTrades(req *MultiDataFeedRequest, conf SubscriptionConfig) (*Subscription[*exchange.TradeEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.TradeEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddMultiDataFeed(req)
+	})
+}
+
+// SubscribeMarkPrice 订阅标记价格，忽略 req.Event
+func (s *Subscriber) SubscribeMarkPrice(req *MarkPriceRequest, conf SubscriptionConfig) (*Subscription[*exchange.MarkPriceEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.MarkPriceEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddMarketPriceDataFeed(req)
+	})
+}
+
+// SubscribeMarketKline 订阅全市场K线，忽略 req.Event
+func (s *Subscriber) SubscribeMarketKline(req *KlineMarketRequest, conf SubscriptionConfig) (*Subscription[*exchange.KlineMarketEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.KlineMarketEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddMarketKlineDataFeed(req)
+	})
+}
+
+// SubscribeKline 订阅K线，忽略 req.Event
+func (s *Subscriber) SubscribeKline(req *KlineRequest, conf SubscriptionConfig) (*Subscription[*exchange.KlineEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.KlineEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddKlineDataFeed(req)
+	})
+}
+
+// SubscribeFundingRate 订阅资金费率，忽略 req.Event
+func (s *Subscriber) SubscribeFundingRate(req *FundingRateRequest, conf SubscriptionConfig) (*Subscription[*exchange.FundingRateEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.FundingRateEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddFundingRateDataFeed(req)
+	})
+}
+
+// SubscribeSymbolUpdate 订阅产品更新，每次推送为一批产品，OverflowConflate 等同于 OverflowDropOldest，忽略 req.Event
+func (s *Subscriber) SubscribeSymbolUpdate(req *SymbolUpdateRequest, conf SubscriptionConfig) (*Subscription[[]*exchange.SymbolUpdateEvent], error) {
+	sub := newSubscription[[]*exchange.SymbolUpdateEvent](s, req.ID, conf, nil)
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddSymbolUpdateDataFeed(req)
+	})
+}
+
+// SubscribeDepth 订阅深度，增量推送（Levels 为空）不能合并，应避免使用 OverflowConflate 和丢弃策略，忽略 req.Event
+func (s *Subscriber) SubscribeDepth(req *DepthRequest, conf SubscriptionConfig) (*Subscription[*exchange.DepthEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.DepthEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddDepthDataFeed(req)
+	})
+}
+
+// SubscribeBookTicker 订阅最优挂单，忽略 req.Event
+func (s *Subscriber) SubscribeBookTicker(req *BookTickerRequest, conf SubscriptionConfig) (*Subscription[*exchange.BookTickerEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.BookTickerEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddBookTickerDataFeed(req)
+	})
+}
+
+// SubscribeLiquidation 订阅强平订单，忽略 req.Event
+func (s *Subscriber) SubscribeLiquidation(req *LiquidationRequest, conf SubscriptionConfig) (*Subscription[*exchange.LiquidationEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.LiquidationEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddLiquidationDataFeed(req)
+	})
+}
+
+// SubscribeOpenInterest 订阅持仓量，忽略 req.Event
+func (s *Subscriber) SubscribeOpenInterest(req *OpenInterestRequest, conf SubscriptionConfig) (*Subscription[*exchange.OpenInterestEvent], error) {
+	sub := newSubscription(s, req.ID, conf, func(e *exchange.OpenInterestEvent) string { return e.Symbol })
+	return register(s, sub, func() error {
+		req.Event = sub.Push
+		req.ErrorHandler = s.errorHandler(req.ID, req.ErrorHandler)
+		return s.DataFeedManager.AddOpenInterestDataFeed(req)
+	})
+}
+
+// CloseDataFeed 关闭数据订阅及对应的通道
+func (s *Subscriber) CloseDataFeed(id string) error {
+	s.closeSubscription(id)
+	return s.DataFeedManager.CloseDataFeed(id)
+}
+
+// Shutdown 关闭全部通道后关闭数据源
+func (s *Subscriber) Shutdown() error {
+	s.mux.Lock()
+	subs := s.subs
+	s.subs = make(map[string]func() bool)
+	s.mux.Unlock()
+	for _, fn := range subs {
+		fn()
+	}
+	return s.DataFeedManager.Shutdown()
+}
+
+// errorHandler 调用原错误处理后，重连失败时关闭订阅
+func (s *Subscriber) errorHandler(id string, handler func(err error)) func(err error) {
+	return func(err error) {
+		if handler != nil {
+			handler(err)
+		}
+		if errors.Is(err, manager.ErrReconnectFailed) {
+			s.closeSubscription(id)
+		}
+	}
+}
+
+func (s *Subscriber) closeSubscription(id string) {
+	s.mux.Lock()
+	fn, ok := s.subs[id]
+	delete(s.subs, id)
+	s.mux.Unlock()
+	if ok {
+		fn()
+	}
+}
+
+func newSubscription[T any](s *Subscriber, id string, conf SubscriptionConfig, key func(T) string) *Subscription[T] {
+	return NewSubscription(id, conf, key, s.CloseDataFeed)
+}
+
+// register 记录订阅后添加数据源，添加失败时关闭订阅
+func register[T any](s *Subscriber, sub *Subscription[T], add func() error) (*Subscription[T], error) {
+	s.mux.Lock()
+	if _, ok := s.subs[sub.id]; ok {
+		s.mux.Unlock()
+		sub.close()
+		return nil, ErrSubscriptionExists
+	}
+	s.subs[sub.id] = sub.close
+	s.mux.Unlock()
+
+	if err := add(); err != nil {
+		s.closeSubscription(sub.id)
+		return nil, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,219 @@
+package dfmanager
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy 缓冲区已满时的处理方式
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // 阻塞推送，直到消费者取走数据，会阻塞 websocket 读协程
+	OverflowDropOldest                       // 丢弃缓冲区中最早的数据
+	OverflowDropNewest                       // 丢弃新推送的数据
+	OverflowConflate                         // 同一交易对只保留最新的数据，缓冲区已满时丢弃最早的数据
+)
+
+// defaultSubscriptionBuffer 缓冲区默认大小
+const defaultSubscriptionBuffer = 1024
+
+// SubscriptionConfig 订阅的缓冲区配置，Buffer 为空时使用 1024
+type SubscriptionConfig struct {
+	Buffer int
+	Policy OverflowPolicy
+}
+
+// Subscription 将数据推送转为通道或迭代器消费，推送只写入缓冲区，由独立协程投递给消费者，
+// 慢消费者不会阻塞 websocket 读协程（OverflowBlock 除外）
+type Subscription[T any] struct {
+	id      string
+	buffer  int
+	policy  OverflowPolicy
+	key     func(T) string // 合并数据使用的键，为空时 OverflowConflate 等同于 OverflowDropOldest
+	onClose func(id string) error
+
+	// 环形缓冲区，start 为最早数据的位置，popped 为已取出的数据总数
+	queue   []T
+	keys    []string
+	start   int
+	count   int
+	popped  uint64
+	pending map[string]uint64 // 键对应数据的序号（popped + 在缓冲区中的位置），仅 OverflowConflate 使用
+	out     chan T
+	done    chan struct{}
+	dropped atomic.Uint64
+	closed  bool
+	once    sync.Once
+	mux     sync.Mutex
+	cond    *sync.Cond
+}
+
+// NewSubscription 创建订阅，key 为合并数据使用的键，onClose 在 Close 时调用，均可为空
+func NewSubscription[T any](id string, conf SubscriptionConfig, key func(T) string, onClose func(id string) error) *Subscription[T] {
+	if conf.Buffer <= 0 {
+		conf.Buffer = defaultSubscriptionBuffer
+	}
+	s := &Subscription[T]{
+		id:      id,
+		buffer:  conf.Buffer,
+		policy:  conf.Policy,
+		key:     key,
+		onClose: onClose,
+		queue:   make([]T, conf.Buffer),
+		out:     make(chan T),
+		done:    make(chan struct{}),
+	}
+	if s.policy == OverflowConflate && s.key != nil {
+		s.keys = make([]string, conf.Buffer)
+		s.pending = make(map[string]uint64)
+	}
+	s.cond = sync.NewCond(&s.mux)
+	go s.run()
+	return s
+}
+
+// ID 数据订阅 ID
+func (s *Subscription[T]) ID() string {
+	return s.id
+}
+
+// Push 写入一条数据，作为请求的 Event 回调使用，订阅关闭后的数据直接丢弃
+func (s *Subscription[T]) Push(v T) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return
+	}
+	var k string
+	if s.pending != nil {
+		k = s.key(v)
+		if seq, ok := s.pending[k]; ok {
+			s.queue[s.index(int(seq-s.popped))] = v
+			s.dropped.Add(1)
+			return
+		}
+	}
+	for s.count >= s.buffer {
+		switch s.policy {
+		case OverflowBlock:
+			s.cond.Wait()
+			if s.closed {
+				return
+			}
+			continue
+		case OverflowDropNewest:
+			s.dropped.Add(1)
+			return
+		default:
+			s.pop()
+			s.dropped.Add(1)
+		}
+	}
+	i := s.index(s.count)
+	s.queue[i] = v
+	if s.pending != nil {
+		s.keys[i] = k
+		s.pending[k] = s.popped + uint64(s.count)
+	}
+	s.count++
+	s.cond.Broadcast()
+}
+
+// C 数据通道，订阅关闭后通道关闭
+func (s *Subscription[T]) C() <-chan T {
+	return s.out
+}
+
+// All 按顺序迭代数据，与 iter.Seq[T] 类型一致，订阅关闭或提前退出循环时结束，提前退出不会关闭订阅
+func (s *Subscription[T]) All() func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for v := range s.out {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Done 订阅关闭时关闭的通道
+func (s *Subscription[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped 缓冲区已满或合并时丢弃的数据数量
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close 关闭数据订阅，未投递的数据将被丢弃
+func (s *Subscription[T]) Close() error {
+	if !s.close() {
+		return nil
+	}
+	if s.onClose != nil {
+		return s.onClose(s.id)
+	}
+	return nil
+}
+
+// close 只关闭通道不关闭数据订阅，首次关闭时返回 true
+func (s *Subscription[T]) close() bool {
+	closed := false
+	s.once.Do(func() {
+		s.mux.Lock()
+		s.closed = true
+		s.queue, s.keys, s.count = nil, nil, 0
+		s.pending = nil
+		s.cond.Broadcast()
+		s.mux.Unlock()
+		close(s.done)
+		closed = true
+	})
+	return closed
+}
+
+// index 第 n 条数据在环形缓冲区中的位置
+func (s *Subscription[T]) index(n int) int {
+	return (s.start + n) % s.buffer
+}
+
+// pop 移除最早的数据，调用方需持有锁
+func (s *Subscription[T]) pop() T {
+	v := s.queue[s.start]
+	var zero T
+	s.queue[s.start] = zero
+	if s.pending != nil {
+		delete(s.pending, s.keys[s.start])
+		s.keys[s.start] = ""
+	}
+	s.start = (s.start + 1) % s.buffer
+	s.count--
+	s.popped++
+	return v
+}
+
+func (s *Subscription[T]) run() {
+	defer close(s.out)
+	for {
+		s.mux.Lock()
+		for s.count == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mux.Unlock()
+			return
+		}
+		v := s.pop()
+		// 唤醒等待缓冲区空间的推送
+		s.cond.Broadcast()
+		s.mux.Unlock()
+
+		select {
+		case s.out <- v:
+		case <-s.done:
+			return
+		}
+	}
+}
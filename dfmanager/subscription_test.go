@@ -0,0 +1,236 @@
+package dfmanager
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/wsmanager/manager"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyed struct {
+	key   string
+	value int
+}
+
+func keyOf(v keyed) string {
+	return v.key
+}
+
+// waitTaken 等待投递协程取走缓冲区中的数据，之后的推送全部留在缓冲区
+func waitTaken[T any](t *testing.T, s *Subscription[T]) {
+	assert.Eventually(t, func() bool {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+		return s.count == 0
+	}, time.Second, time.Millisecond)
+}
+
+func receive[T any](t *testing.T, s *Subscription[T], n int) []T {
+	result := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case v := <-s.C():
+			result = append(result, v)
+		case <-time.After(time.Second):
+			t.Fatalf("receive timeout after %d items", i)
+		}
+	}
+	return result
+}
+
+func TestSubscriptionDropOldest(t *testing.T) {
+	s := NewSubscription[int]("id", SubscriptionConfig{Buffer: 3, Policy: OverflowDropOldest}, nil, nil)
+	defer s.Close()
+
+	s.Push(0)
+	waitTaken(t, s)
+	for i := 1; i <= 6; i++ {
+		s.Push(i)
+	}
+	assert.Equal(t, []int{0, 4, 5, 6}, receive(t, s, 4))
+	assert.Equal(t, uint64(3), s.Dropped())
+}
+
+func TestSubscriptionDropNewest(t *testing.T) {
+	s := NewSubscription[int]("id", SubscriptionConfig{Buffer: 3, Policy: OverflowDropNewest}, nil, nil)
+	defer s.Close()
+
+	s.Push(0)
+	waitTaken(t, s)
+	for i := 1; i <= 6; i++ {
+		s.Push(i)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3}, receive(t, s, 4))
+	assert.Equal(t, uint64(3), s.Dropped())
+}
+
+func TestSubscriptionConflate(t *testing.T) {
+	s := NewSubscription("id", SubscriptionConfig{Buffer: 2, Policy: OverflowConflate}, keyOf, nil)
+	defer s.Close()
+
+	s.Push(keyed{"a", 0})
+	waitTaken(t, s)
+	// 同一个键只保留最新的数据，位置不变；缓冲区已满时丢弃最早的数据
+	s.Push(keyed{"a", 1})
+	s.Push(keyed{"b", 1})
+	s.Push(keyed{"a", 2})
+	assert.Equal(t, []keyed{{"a", 0}, {"a", 2}, {"b", 1}}, receive(t, s, 3))
+	assert.Equal(t, uint64(1), s.Dropped())
+
+	s.Push(keyed{"c", 1})
+	waitTaken(t, s)
+	s.Push(keyed{"a", 3})
+	s.Push(keyed{"b", 2})
+	s.Push(keyed{"c", 2})
+	s.Push(keyed{"b", 3})
+	assert.Equal(t, []keyed{{"c", 1}, {"b", 3}, {"c", 2}}, receive(t, s, 3))
+	assert.Equal(t, uint64(3), s.Dropped())
+
+	// 取出后同一个键重新进入缓冲区
+	s.Push(keyed{"b", 4})
+	assert.Equal(t, []keyed{{"b", 4}}, receive(t, s, 1))
+}
+
+func TestSubscriptionBlock(t *testing.T) {
+	s := NewSubscription[int]("id", SubscriptionConfig{Buffer: 1, Policy: OverflowBlock}, nil, nil)
+
+	const total = 1000
+	go func() {
+		for i := 0; i < total; i++ {
+			s.Push(i)
+		}
+	}()
+	got := receive(t, s, total)
+	for i, v := range got {
+		assert.Equal(t, i, v)
+	}
+	assert.Equal(t, uint64(0), s.Dropped())
+
+	// 关闭后阻塞的推送返回
+	s.Push(0)
+	waitTaken(t, s)
+	s.Push(1)
+	done := make(chan struct{})
+	go func() {
+		s.Push(2)
+		close(done)
+	}()
+	assert.NoError(t, s.Close())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("push still blocked after close")
+	}
+}
+
+func TestSubscriptionClose(t *testing.T) {
+	closed := make([]string, 0)
+	s := NewSubscription[int]("id", SubscriptionConfig{}, nil, func(id string) error {
+		closed = append(closed, id)
+		return nil
+	})
+	got := make(chan int, 1)
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		s.All()(func(v int) bool {
+			got <- v
+			return true
+		})
+	}()
+	s.Push(1)
+	assert.Equal(t, 1, <-got)
+
+	assert.NoError(t, s.Close())
+	assert.NoError(t, s.Close())
+	<-finished
+	assert.Equal(t, []string{"id"}, closed)
+	_, ok := <-s.C()
+	assert.False(t, ok)
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("done not closed")
+	}
+	// 关闭后的推送直接丢弃
+	s.Push(2)
+}
+
+type subscriberFeed struct {
+	DataFeedManager
+	req    *DataFeedRequest
+	closed []string
+	addErr error
+	mux    sync.Mutex
+}
+
+func (f *subscriberFeed) AddDataFeed(req *DataFeedRequest) error {
+	if f.addErr != nil {
+		return f.addErr
+	}
+	f.req = req
+	return nil
+}
+
+func (f *subscriberFeed) CloseDataFeed(id string) error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.closed = append(f.closed, id)
+	return nil
+}
+
+func TestSubscriber(t *testing.T) {
+	feed := &subscriberFeed{}
+	s := NewSubscriber(feed)
+
+	errs := make([]error, 0)
+	sub, err := s.SubscribeTrades(&DataFeedRequest{
+		ID:           "trade",
+		ErrorHandler: func(err error) { errs = append(errs, err) },
+	}, SubscriptionConfig{})
+	assert.NoError(t, err)
+	_, err = s.SubscribeTrades(&DataFeedRequest{ID: "trade"}, SubscriptionConfig{})
+	assert.ErrorIs(t, err, ErrSubscriptionExists)
+
+	feed.req.Event(&exchange.TradeEvent{Symbol: "BTCUSDT"})
+	assert.Equal(t, "BTCUSDT", receive(t, sub, 1)[0].Symbol)
+
+	// CloseDataFeed 同时关闭订阅
+	assert.NoError(t, s.CloseDataFeed("trade"))
+	<-sub.Done()
+	assert.Equal(t, []string{"trade"}, feed.closed)
+
+	// 数据源重连失败时关闭订阅，原错误处理仍会调用
+	sub, err = s.SubscribeTrades(&DataFeedRequest{
+		ID:           "trade",
+		ErrorHandler: func(err error) { errs = append(errs, err) },
+	}, SubscriptionConfig{})
+	assert.NoError(t, err)
+	feed.req.ErrorHandler(errors.New("read error"))
+	select {
+	case <-sub.Done():
+		t.Fatal("closed on recoverable error")
+	default:
+	}
+	feed.req.ErrorHandler(manager.ErrReconnectFailed)
+	<-sub.Done()
+	assert.Len(t, errs, 2)
+
+	// 订阅的 Close 同时关闭数据源
+	sub, err = s.SubscribeTrades(&DataFeedRequest{ID: "trade"}, SubscriptionConfig{})
+	assert.NoError(t, err)
+	assert.NoError(t, sub.Close())
+	assert.Equal(t, []string{"trade", "trade"}, feed.closed)
+
+	// 添加数据源失败时关闭订阅
+	feed.addErr = errors.New("add failed")
+	_, err = s.SubscribeTrades(&DataFeedRequest{ID: "trade"}, SubscriptionConfig{})
+	assert.Error(t, err)
+	feed.addErr = nil
+	_, err = s.SubscribeTrades(&DataFeedRequest{ID: "trade"}, SubscriptionConfig{})
+	assert.NoError(t, err)
+}
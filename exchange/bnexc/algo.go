@@ -0,0 +1,230 @@
+package bnexc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+)
+
+// CreateOcoOrder 现货 OCO 订单，卖出时止盈腿在上方、止损腿在下方，买入时相反
+func (b *binance) CreateOcoOrder(ctx context.Context, req *exchange.CreateOcoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	if req.MarketType != exchange.MarketTypeSpot {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/api/v3/orderList/oco",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnSpotEndpoint)
+	params := bnhttp.Params{
+		"symbol":           req.Symbol.OriginalSymbol,
+		"side":             req.Side,
+		"quantity":         req.Size.String(),
+		"newOrderRespType": "FULL",
+	}
+	if req.ClientOrderID != "" {
+		params["listClientOrderId"] = req.ClientOrderID
+	}
+	above, below := "above", "below"
+	if req.Side == exchange.SideTypeBuy {
+		above, below = below, above
+	}
+	setBnOcoLeg(params, above, req.TakeProfit, true)
+	setBnOcoLeg(params, below, req.StopLoss, false)
+	r = r.SetFormParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &bnOrderListResponse{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+	orders := make([]*exchange.SearchOrderResponse, 0, len(res.OrderReports))
+	for _, v := range res.OrderReports {
+		order, err := bnSpotOrderToSearchOrder(v)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	return res.toAlgoOrderResponse(orders), nil
+}
+
+// CancelAlgoOrder 现货撤销 OCO 订单，合约条件单按普通订单撤销
+func (b *binance) CancelAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) error {
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r := &bnhttp.Request{
+			APIKey:    req.APIKey,
+			SecretKey: req.SecretKey,
+			Method:    http.MethodDelete,
+			Endpoint:  "/api/v3/orderList",
+			SecType:   bnhttp.SecTypeSigned,
+		}
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+		r = r.SetFormParams(bnOrderListParams(req))
+		_, err := b.callAPI(ctx, r)
+		return err
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		if req.ClientOrderID == "" {
+			return errors.New("client order id is required")
+		}
+		return b.CancelOrder(ctx, &exchange.CancelOrderRequest{
+			APIKey:        req.APIKey,
+			SecretKey:     req.SecretKey,
+			ClientOrderID: req.ClientOrderID,
+			Symbol:        req.Symbol.OriginalSymbol,
+			MarketType:    req.MarketType,
+		})
+	}
+	return exchange.ErrInstrumentTypeNotSupported
+}
+
+// SearchAlgoOrder 现货查询 OCO 订单及两条腿的状态，合约条件单按普通订单查询
+func (b *binance) SearchAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r := &bnhttp.Request{
+			APIKey:    req.APIKey,
+			SecretKey: req.SecretKey,
+			Method:    http.MethodGet,
+			Endpoint:  "/api/v3/orderList",
+			SecType:   bnhttp.SecTypeSigned,
+		}
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+		params := bnhttp.Params{}
+		if req.OrderID != "" {
+			params["orderListId"] = req.OrderID
+		} else {
+			params["origClientOrderId"] = req.ClientOrderID
+		}
+		r = r.SetParams(params)
+		data, err := b.callAPI(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		res := &bnOrderListResponse{}
+		err = bnhttp.Json.Unmarshal(data, res)
+		if err != nil {
+			return nil, err
+		}
+		orders := make([]*exchange.SearchOrderResponse, 0, len(res.Orders))
+		for _, v := range res.Orders {
+			order, err := b.searchSpotOrder(ctx, &exchange.SearchOrderRequest{
+				APIKey:        req.APIKey,
+				SecretKey:     req.SecretKey,
+				ClientOrderID: v.ClientOrderID,
+				MarketType:    req.MarketType,
+				Symbol:        exchange.Symbol{OriginalSymbol: v.Symbol},
+			})
+			if err != nil {
+				return nil, err
+			}
+			orders = append(orders, order)
+		}
+		return res.toAlgoOrderResponse(orders), nil
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		order, err := b.searchFuturesOrder(ctx, &exchange.SearchOrderRequest{
+			APIKey:        req.APIKey,
+			SecretKey:     req.SecretKey,
+			ClientOrderID: req.ClientOrderID,
+			MarketType:    req.MarketType,
+			Symbol:        req.Symbol,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &exchange.AlgoOrderResponse{
+			OrderID:       order.OrderID,
+			ClientOrderID: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			OrderType:     order.OrderType,
+			State:         order.State,
+			Side:          order.Side,
+			PositionSide:  order.PositionSide,
+			Size:          order.Volume,
+			Orders:        []*exchange.SearchOrderResponse{order},
+			CreatedTime:   order.CreatedTime,
+			UpdateTime:    order.UpdateTime,
+		}, nil
+	}
+	return nil, exchange.ErrInstrumentTypeNotSupported
+}
+
+// setBnOcoLeg 设置 OCO 一条腿的参数，止盈腿没有触发价格时为 LIMIT_MAKER
+func setBnOcoLeg(params bnhttp.Params, prefix string, leg exchange.OcoOrderLeg, takeProfit bool) {
+	orderType := "STOP_LOSS"
+	if takeProfit {
+		orderType = "TAKE_PROFIT"
+	}
+	switch {
+	case takeProfit && leg.TriggerPrice.IsZero():
+		params[prefix+"Type"] = "LIMIT_MAKER"
+	case !leg.Price.IsZero():
+		params[prefix+"Type"] = orderType + "_LIMIT"
+		params[prefix+"TimeInForce"] = exchange.TimeInForceGTC
+	default:
+		params[prefix+"Type"] = orderType
+	}
+	if !leg.Price.IsZero() {
+		params[prefix+"Price"] = leg.Price.String()
+	}
+	if !leg.TriggerPrice.IsZero() {
+		params[prefix+"StopPrice"] = leg.TriggerPrice.String()
+	}
+	if leg.ClientOrderID != "" {
+		params[prefix+"ClientOrderId"] = leg.ClientOrderID
+	}
+}
+
+func bnOrderListParams(req *exchange.AlgoOrderRequest) bnhttp.Params {
+	params := bnhttp.Params{
+		"symbol": req.Symbol.OriginalSymbol,
+	}
+	if req.OrderID != "" {
+		params["orderListId"] = req.OrderID
+	} else {
+		params["listClientOrderId"] = req.ClientOrderID
+	}
+	return params
+}
+
+// toAlgoOrderResponse 订单组结束时，有腿成交为 FILLED，否则为 CANCELED
+func (r *bnOrderListResponse) toAlgoOrderResponse(orders []*exchange.SearchOrderResponse) *exchange.AlgoOrderResponse {
+	res := &exchange.AlgoOrderResponse{
+		OrderID:       strconv.FormatInt(r.OrderListID, 10),
+		ClientOrderID: r.ListClientOrderID,
+		Symbol:        r.Symbol,
+		OrderType:     exchange.OrderTypeOco,
+		State:         exchange.OrderStateNew,
+		Orders:        orders,
+		CreatedTime:   r.TransactionTime,
+		UpdateTime:    r.TransactionTime,
+	}
+	if len(orders) > 0 {
+		res.Side = orders[0].Side
+		res.Size = orders[0].Volume
+	}
+	switch r.ListOrderStatus {
+	case "REJECT":
+		res.State = exchange.OrderStateRejected
+	case "ALL_DONE":
+		res.State = exchange.OrderStateCanceled
+		for _, v := range orders {
+			if v.State == exchange.OrderStateFilled || v.State == exchange.OrderStatePartiallyFilled {
+				res.State = exchange.OrderStateFilled
+			}
+			res.UpdateTime = max(res.UpdateTime, v.UpdateTime)
+		}
+	}
+	return res
+}
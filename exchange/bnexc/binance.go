@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-gotop/kit/exchange"
 	"github.com/go-gotop/kit/requests/bnhttp"
@@ -16,6 +18,12 @@ const (
 	bnSpotEndpoint            = "https://api.binance.com"
 	bnFuturesEndpoint         = "https://fapi.binance.com"
 	bnPortfolioMarginEndpoint = "https://papi.binance.com"
+	bnDeliveryEndpoint        = "https://dapi.binance.com"
+
+	bnBatchCreateOrdersLimit = 5  // 批量下单每次最多订单数
+	bnBatchCancelOrdersLimit = 10 // 批量撤单每次最多订单数
+
+	bnFundingRateLookback = int64(9 * time.Hour / time.Millisecond) // 资金费率最长 8 小时结算一次，分页查询的起点
 )
 
 func NewBinance(cli *bnhttp.Client) exchange.Exchange {
@@ -46,7 +54,7 @@ func (b *binance) TransferAsset(ctx context.Context, req *exchange.TransferAsset
 		"type":   req.Type,
 	})
 	b.client.SetApiEndpoint(bnSpotEndpoint)
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return err
 	}
@@ -61,13 +69,13 @@ func (b *binance) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (
 		SecType:  bnhttp.SecTypeNone,
 	}
 	r = r.SetParams(bnhttp.Params{"symbol": req.Symbol.OriginalSymbol, "limit": req.Limit})
-	if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+	if req.MarketType.IsContract() {
 		r.Endpoint = "/fapi/v1/depth"
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
+		b.setFuturesEndpoint(r, req.MarketType)
 	} else {
 		b.client.SetApiEndpoint(bnSpotEndpoint)
 	}
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return exchange.GetDepthResponse{}, err
 	}
@@ -113,7 +121,7 @@ func (b *binance) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (
 }
 
 func (b *binance) SetLeverage(ctx context.Context, req *exchange.SetLeverageRequest) error {
-	if req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+	if req.MarketType == exchange.MarketTypePerpetualUSDMargined || req.MarketType.IsCoinMargined() {
 		r := &bnhttp.Request{
 			APIKey:    req.APIKey,
 			SecretKey: req.SecretKey,
@@ -125,8 +133,8 @@ func (b *binance) SetLeverage(ctx context.Context, req *exchange.SetLeverageRequ
 			"symbol":   req.Symbol,
 			"leverage": req.Lever,
 		})
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
-		data, err := b.client.CallAPI(ctx, r)
+		b.setFuturesEndpoint(r, req.MarketType)
+		data, err := b.callAPI(ctx, r)
 		if err != nil {
 			return err
 		}
@@ -146,7 +154,7 @@ func (b *binance) GetLeverage(ctx context.Context, req *exchange.GetLeverageRequ
 		}
 		r = r.SetParams(bnhttp.Params{"symbol": req.Symbol})
 		b.client.SetApiEndpoint(bnFuturesEndpoint)
-		data, err := b.client.CallAPI(ctx, r)
+		data, err := b.callAPI(ctx, r)
 		if err != nil {
 			return exchange.GetLeverageResponse{}, err
 		}
@@ -168,99 +176,94 @@ func (b *binance) GetLeverage(ctx context.Context, req *exchange.GetLeverageRequ
 	return exchange.GetLeverageResponse{}, errors.New("symbol not found")
 }
 
-func (b *binance) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceKlineRequest) ([]exchange.GetMarkPriceKlineResponse, error) {
-	return nil, errors.New("not implemented")
+// SetPositionMode 设置合约持仓模式，对账户下 U 本位或币本位的所有交易对生效，模式未变化时返回成功
+func (b *binance) SetPositionMode(ctx context.Context, req *exchange.SetPositionModeRequest) error {
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/fapi/v1/positionSide/dual",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	if req.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/um/positionSide/dual"
+	}
+	b.setFuturesEndpoint(r, req.MarketType)
+	r = r.SetFormParams(bnhttp.Params{
+		"dualSidePosition": strconv.FormatBool(req.Mode == exchange.PositionModeHedge),
+	})
+	_, err := b.callAPI(ctx, r)
+	if exchange.ErrorCode(err) == bnNoNeedToChangePositionSide {
+		return nil
+	}
+	return err
 }
 
-func (b *binance) GetKline(ctx context.Context, req *exchange.GetKlineRequest) ([]exchange.GetKlineResponse, error) {
-	var r *bnhttp.Request
-
-	if req.MarketType == exchange.MarketTypeSpot {
-		r = &bnhttp.Request{
-			Method:   http.MethodGet,
-			Endpoint: "/api/v3/klines",
-			SecType:  bnhttp.SecTypeNone,
-		}
-		b.client.SetApiEndpoint(bnSpotEndpoint)
-	} else if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
-		r = &bnhttp.Request{
-			Method:   http.MethodGet,
-			Endpoint: "/fapi/v1/klines",
-			SecType:  bnhttp.SecTypeNone,
-		}
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
+// SetMarginMode 设置合约交易对的保证金模式，模式未变化时返回成功
+func (b *binance) SetMarginMode(ctx context.Context, req *exchange.SetMarginModeRequest) error {
+	if !req.MarketType.IsContract() {
+		return exchange.ErrInstrumentTypeNotSupported
 	}
-
-	params := bnhttp.Params{
-		"symbol":   req.Symbol.OriginalSymbol,
-		"interval": req.Period,
+	marginType := "CROSSED"
+	if req.Mode == exchange.PosModeIsolated {
+		marginType = "ISOLATED"
+	}
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/fapi/v1/marginType",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.setFuturesEndpoint(r, req.MarketType)
+	r = r.SetFormParams(bnhttp.Params{
+		"symbol":     req.Symbol.OriginalSymbol,
+		"marginType": marginType,
+	})
+	_, err := b.callAPI(ctx, r)
+	if exchange.ErrorCode(err) == bnNoNeedToChangeMarginType {
+		return nil
 	}
+	return err
+}
 
-	if req.Start != 0 {
-		params["startTime"] = req.Start
+// AdjustPositionMargin 调整合约逐仓仓位保证金，币本位合约的数量单位为币
+func (b *binance) AdjustPositionMargin(ctx context.Context, req *exchange.AdjustPositionMarginRequest) error {
+	if !req.MarketType.IsContract() {
+		return exchange.ErrInstrumentTypeNotSupported
 	}
-	if req.End != 0 {
-		params["endTime"] = req.End
+	if req.Amount.IsZero() {
+		return errors.New("amount is required")
 	}
-	if req.Limit != 0 {
-		params["limit"] = req.Limit
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/fapi/v1/positionMargin",
+		SecType:   bnhttp.SecTypeSigned,
 	}
-
-	r = r.SetParams(params)
-	data, err := b.client.CallAPI(ctx, r)
-	if err != nil {
-		return nil, err
+	b.setFuturesEndpoint(r, req.MarketType)
+	// type 1 增加保证金，2 减少保证金
+	params := bnhttp.Params{
+		"symbol": req.Symbol.OriginalSymbol,
+		"amount": req.Amount.Abs().String(),
+		"type":   1,
 	}
-
-	var klines [][]interface{}
-	err = bnhttp.Json.Unmarshal(data, &klines)
-	if err != nil {
-		return nil, err
+	if req.Amount.IsNegative() {
+		params["type"] = 2
 	}
-
-	result := make([]exchange.GetKlineResponse, 0, len(klines))
-	for _, k := range klines {
-		open, err := decimal.NewFromString(k[1].(string))
-		if err != nil {
-			return nil, err
-		}
-		high, err := decimal.NewFromString(k[2].(string))
-		if err != nil {
-			return nil, err
-		}
-		low, err := decimal.NewFromString(k[3].(string))
-		if err != nil {
-			return nil, err
-		}
-		close, err := decimal.NewFromString(k[4].(string))
-		if err != nil {
-			return nil, err
-		}
-		volume, err := decimal.NewFromString(k[5].(string))
-		if err != nil {
-			return nil, err
-		}
-		quoteVolume, err := decimal.NewFromString(k[7].(string))
-		if err != nil {
-			return nil, err
-		}
-
-		result = append(result, exchange.GetKlineResponse{
-			Symbol:      req.Symbol.UnifiedSymbol,
-			OpenTime:    int64(k[0].(float64)),
-			Open:        open,
-			High:        high,
-			Low:         low,
-			Close:       close,
-			Volume:      volume,
-			QuoteVolume: quoteVolume,
-			Confirm:     "1",
-		})
+	if req.PositionSide != "" {
+		params["positionSide"] = req.PositionSide
 	}
-	return result, nil
+	r = r.SetFormParams(params)
+	_, err := b.callAPI(ctx, r)
+	return err
 }
 
 func (b *binance) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([]exchange.Asset, error) {
+	if req.IsUnifiedAccount && req.MarketType != exchange.MarketTypeSpot {
+		return b.pmAssets(ctx, req)
+	}
 	if req.MarketType == exchange.MarketTypeSpot || req.MarketType == exchange.MarketTypeMargin {
 		result, err := b.spotAssets(ctx, req)
 		if err != nil {
@@ -271,12 +274,12 @@ func (b *binance) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([
 			return nil, err
 		}
 		return data, nil
-	} else if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+	} else if req.MarketType.IsContract() {
 		result, err := b.futuresAssets(ctx, req)
 		if err != nil {
 			return nil, err
 		}
-		data, err := bnFuturesAssetsToAssets(result)
+		data, err := bnFuturesAssetsToAssets(result, req.MarketType)
 		if err != nil {
 			return nil, err
 		}
@@ -285,41 +288,189 @@ func (b *binance) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([
 	return nil, exchange.ErrInstrumentTypeNotSupported
 }
 
+func (b *binance) Symbols(ctx context.Context, marketType exchange.MarketType) ([]exchange.Symbol, error) {
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: "/api/v3/exchangeInfo",
+		SecType:  bnhttp.SecTypeNone,
+	}
+	switch marketType {
+	case exchange.MarketTypeSpot, exchange.MarketTypeMargin:
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		r.Endpoint = "/fapi/v1/exchangeInfo"
+		b.setFuturesEndpoint(r, marketType)
+	default:
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &bnExchangeInfo{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]exchange.Symbol, 0, len(res.Symbols))
+	for _, v := range res.Symbols {
+		switch marketType {
+		case exchange.MarketTypeMargin:
+			if !v.IsMarginTradingAllowed {
+				continue
+			}
+		case exchange.MarketTypePerpetualUSDMargined, exchange.MarketTypePerpetualCoinMargined:
+			if v.ContractType != "PERPETUAL" {
+				continue
+			}
+		case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypeFuturesCoinMargined:
+			if v.ContractType != "CURRENT_QUARTER" && v.ContractType != "NEXT_QUARTER" {
+				continue
+			}
+		}
+		symbol, err := bnSymbolToSymbol(v, marketType)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, symbol)
+	}
+	return result, nil
+}
+
 func (b *binance) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConfigRequest) (exchange.GetAccountConfigResponse, error) {
 	return exchange.GetAccountConfigResponse{}, errors.New("not implemented")
 }
 
-func (b *binance) CreateOrder(ctx context.Context, o *exchange.CreateOrderRequest) error {
+func (b *binance) CreateOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	if o.MarketType == exchange.MarketTypeSpot || o.MarketType == exchange.MarketTypeMargin {
+		// 现货和杠杆没有仓位，不支持只减仓、平仓和盘口价格下单，也不支持 GTD
+		if o.ReduceOnly || o.ClosePosition || o.PriceMatch != "" || o.TimeInForce == exchange.TimeInForceGTD {
+			return nil, exchange.ErrOrderOptionNotSupported
+		}
+	}
 	if o.MarketType == exchange.MarketTypeSpot {
 		return b.createSpotOrder(ctx, o)
-	} else if o.MarketType == exchange.MarketTypeFuturesUSDMargined || o.MarketType == exchange.MarketTypePerpetualUSDMargined {
+	} else if o.MarketType.IsContract() {
 		return b.createFuturesOrder(ctx, o)
 	} else if o.MarketType == exchange.MarketTypeMargin {
 		return b.createMarginOrder(ctx, o)
 	}
-	return exchange.ErrInstrumentTypeNotSupported
+	return nil, exchange.ErrInstrumentTypeNotSupported
 }
 
 func (b *binance) SearchOrder(ctx context.Context, o *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
-	if o.MarketType == exchange.MarketTypeSpot {
+	if o.MarketType == exchange.MarketTypeSpot || o.MarketType == exchange.MarketTypeMargin {
 		return b.searchSpotOrder(ctx, o)
 	}
 	return b.searchFuturesOrder(ctx, o)
 }
 
 func (b *binance) SearchTrades(ctx context.Context, o *exchange.SearchTradesRequest) ([]*exchange.SearchTradesResponse, error) {
-	if o.MarketType == exchange.MarketTypeSpot {
+	if o.MarketType == exchange.MarketTypeSpot || o.MarketType == exchange.MarketTypeMargin {
 		return b.searchSpotTrades(ctx, o)
 	}
 	return b.searchFuturesTrades(ctx, o)
 }
 
 func (b *binance) GetFundingRate(ctx context.Context, req *exchange.GetFundingRate) ([]*exchange.GetFundingRateResponse, error) {
-	b.client.SetApiEndpoint(bnFuturesEndpoint)
-	if req.Symbol != "" {
-		return b.getSingleFundingRate(ctx, req.Symbol)
+	var (
+		res []*exchange.GetFundingRateResponse
+		err error
+	)
+	// dapi 按交易对查询也返回数组
+	if req.Symbol != "" && !req.MarketType.IsCoinMargined() {
+		res, err = b.getSingleFundingRate(ctx, req.Symbol)
+	} else {
+		res, err = b.getAllFundingRates(ctx, req.Symbol, req.MarketType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := b.fillLastFundingRates(ctx, req.Symbol, req.MarketType, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// fillLastFundingRates premiumIndex 只返回本期预测资金费率，上一次结算的资金费率取历史资金费率的最新一条；
+// 查询全部 U 本位交易对时从最长结算周期之前开始按时间分页，dapi 必须指定交易对，
+// 币本位合约和分页中没有结算记录的交易对逐个查询，交割合约没有资金费率不查询
+func (b *binance) fillLastFundingRates(ctx context.Context, symbol string, marketType exchange.MarketType, rates []*exchange.GetFundingRateResponse) error {
+	latest := make(map[string]*bnFundingRate, len(rates))
+	if symbol == "" && !marketType.IsCoinMargined() {
+		start := time.Now().UnixMilli() - bnFundingRateLookback
+		for {
+			items, err := b.getFundingRateRecords(ctx, bnhttp.Params{"startTime": start, "limit": bnHistoryPageLimit}, marketType)
+			if err != nil {
+				return err
+			}
+			for _, v := range items {
+				if last, ok := latest[v.Symbol]; !ok || v.FundingTime >= last.FundingTime {
+					latest[v.Symbol] = v
+				}
+			}
+			if len(items) < bnHistoryPageLimit {
+				break
+			}
+			// 下一页从最后一条的时间开始，同一毫秒的记录可能重复返回
+			next := items[len(items)-1].FundingTime
+			if next <= start {
+				next = start + 1
+			}
+			start = next
+		}
+	}
+	for _, rate := range rates {
+		if rate == nil || rate.NextFundingTime == 0 {
+			continue
+		}
+		if _, ok := latest[rate.Symbol]; ok {
+			continue
+		}
+		items, err := b.getFundingRateRecords(ctx, bnhttp.Params{"symbol": rate.Symbol, "limit": 1}, marketType)
+		if err != nil {
+			return err
+		}
+		if len(items) > 0 {
+			latest[rate.Symbol] = items[len(items)-1]
+		}
+	}
+	for _, rate := range rates {
+		if rate == nil {
+			continue
+		}
+		v, ok := latest[rate.Symbol]
+		if !ok {
+			continue
+		}
+		var err error
+		if rate.LastFundingRate, err = decimal.NewFromString(v.FundingRate); err != nil {
+			return err
+		}
 	}
-	return b.getAllFundingRates(ctx)
+	return nil
+}
+
+// getFundingRateRecords 查询历史资金费率，按时间升序返回
+func (b *binance) getFundingRateRecords(ctx context.Context, params bnhttp.Params, marketType exchange.MarketType) ([]*bnFundingRate, error) {
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: "/fapi/v1/fundingRate",
+		SecType:  bnhttp.SecTypeNone,
+	}
+	b.setFuturesEndpoint(r, marketType)
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var items []*bnFundingRate
+	if err := bnhttp.Json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 func (b *binance) GetMarginInterestRate(ctx context.Context, req *exchange.GetMarginInterestRateRequest) ([]*exchange.GetMarginInterestRateResponse, error) {
@@ -332,7 +483,7 @@ func (b *binance) GetMarginInterestRate(ctx context.Context, req *exchange.GetMa
 	}
 	b.client.SetApiEndpoint(bnSpotEndpoint)
 	r = r.SetParams(bnhttp.Params{"assets": req.Assets, "isIsolated": req.IsIsolated})
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -356,6 +507,9 @@ func (b *binance) GetMarginInterestRate(ctx context.Context, req *exchange.GetMa
 }
 
 func (b *binance) MarginBorrowOrRepay(ctx context.Context, req *exchange.MarginBorrowOrRepayRequest) error {
+	if req.IsUnifiedAccount {
+		return b.pmBorrowOrRepay(ctx, req)
+	}
 	r := &bnhttp.Request{
 		APIKey:    req.APIKey,
 		SecretKey: req.SecretKey,
@@ -372,7 +526,7 @@ func (b *binance) MarginBorrowOrRepay(ctx context.Context, req *exchange.MarginB
 		"symbol":     req.Symbol,
 		"type":       req.Typ,
 	})
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return err
 	}
@@ -401,7 +555,7 @@ func (b *binance) GetMarginInventory(ctx context.Context, req *exchange.MarginIn
 	}
 	b.client.SetApiEndpoint(bnSpotEndpoint)
 	r = r.SetParams(bnhttp.Params{"type": req.Typ})
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -428,9 +582,22 @@ func (b *binance) GetPosition(ctx context.Context, req *exchange.GetPositionRequ
 		Endpoint:  "/fapi/v2/positionRisk",
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	b.client.SetApiEndpoint(bnFuturesEndpoint)
-	r = r.SetParams(bnhttp.Params{"symbol": req.Symbol})
-	data, err := b.client.CallAPI(ctx, r)
+	if req.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/um/positionRisk"
+	}
+	b.setFuturesEndpoint(r, req.MarketType)
+	marketType := req.MarketType
+	if marketType.IsCoinMargined() {
+		// dapi 只能按标的查询，如 BTCUSD_PERP 的标的为 BTCUSD
+		if req.Symbol != "" {
+			pair, _, _ := strings.Cut(req.Symbol, "_")
+			r = r.SetParams(bnhttp.Params{"pair": pair})
+		}
+	} else {
+		marketType = exchange.MarketTypePerpetualUSDMargined
+		r = r.SetParams(bnhttp.Params{"symbol": req.Symbol})
+	}
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -442,6 +609,9 @@ func (b *binance) GetPosition(ctx context.Context, req *exchange.GetPositionRequ
 
 	var positions []*exchange.GetPositionResponse
 	for _, v := range res {
+		if req.Symbol != "" && v.Symbol != req.Symbol {
+			continue
+		}
 		size, err := decimal.NewFromString(v.PositionAmt)
 		if err != nil {
 			return nil, err
@@ -458,14 +628,17 @@ func (b *binance) GetPosition(ctx context.Context, req *exchange.GetPositionRequ
 		if err != nil {
 			return nil, err
 		}
-		bePx, err := decimal.NewFromString(v.BreakEvenPrice)
-		if err != nil {
-			return nil, err
+		// 统一账户的持仓不返回盈亏平衡价
+		bePx := decimal.Zero
+		if v.BreakEvenPrice != "" {
+			if bePx, err = decimal.NewFromString(v.BreakEvenPrice); err != nil {
+				return nil, err
+			}
 		}
 
 		positions = append(positions, &exchange.GetPositionResponse{
 			Symbol:       v.Symbol,
-			MarketType:   exchange.MarketTypePerpetualUSDMargined,
+			MarketType:   marketType,
 			AvgPrice:     avgPrice,
 			Size:         size,
 			Upl:          upl,
@@ -484,19 +657,15 @@ func (b *binance) GetHistoryPosition(ctx context.Context, req *exchange.GetPosit
 	return nil
 }
 
-func (b *binance) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string, opTyp string) (string, error) {
-	return "", errors.New("not implemented")
-}
-
 func (b *binance) getSingleFundingRate(ctx context.Context, symbol string) ([]*exchange.GetFundingRateResponse, error) {
 	r := &bnhttp.Request{
 		Method:   http.MethodGet,
 		Endpoint: "/fapi/v1/premiumIndex",
 		SecType:  bnhttp.SecTypeNone,
 	}
-
+	b.client.SetApiEndpoint(bnFuturesEndpoint)
 	r = r.SetParams(bnhttp.Params{"symbol": symbol})
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -508,13 +677,17 @@ func (b *binance) getSingleFundingRate(ctx context.Context, symbol string) ([]*e
 	return []*exchange.GetFundingRateResponse{b.convertFundingRate(&result)}, nil
 }
 
-func (b *binance) getAllFundingRates(ctx context.Context) ([]*exchange.GetFundingRateResponse, error) {
+func (b *binance) getAllFundingRates(ctx context.Context, symbol string, marketType exchange.MarketType) ([]*exchange.GetFundingRateResponse, error) {
 	r := &bnhttp.Request{
 		Method:   http.MethodGet,
 		Endpoint: "/fapi/v1/premiumIndex",
 		SecType:  bnhttp.SecTypeNone,
 	}
-	data, err := b.client.CallAPI(ctx, r)
+	b.setFuturesEndpoint(r, marketType)
+	if symbol != "" {
+		r = r.SetParams(bnhttp.Params{"symbol": symbol})
+	}
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -543,7 +716,8 @@ func (b *binance) convertFundingRate(data *bnPremiumIndex) *exchange.GetFundingR
 	if err != nil {
 		return nil
 	}
-	lastFundingRate, err := decimal.NewFromString(data.LastFundingRate)
+	// 币安 premiumIndex 的 lastFundingRate 为本期预测资金费率
+	nextFundingRate, err := decimal.NewFromString(data.LastFundingRate)
 	if err != nil {
 		return nil
 	}
@@ -556,7 +730,7 @@ func (b *binance) convertFundingRate(data *bnPremiumIndex) *exchange.GetFundingR
 		MarkPrice:            markPrice,
 		IndexPrice:           indexPrice,
 		EstimatedSettlePrice: estimatedSettlePrice,
-		LastFundingRate:      lastFundingRate,
+		NextFundingRate:      nextFundingRate,
 		NextFundingTime:      data.NextFundingTime,
 		InterestRate:         interestRate,
 		Time:                 data.Time,
@@ -568,23 +742,26 @@ func (b *binance) CancelOrder(ctx context.Context, o *exchange.CancelOrderReques
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
 		Method:    http.MethodDelete,
-		Endpoint:  "/api/v3/order",
 		SecType:   bnhttp.SecTypeSigned,
 	}
+	path, err := bnOrderPaths(o.MarketType, o.IsUnifiedAccount)
+	if err != nil {
+		return err
+	}
+	r.Endpoint = path.order
+	b.client.SetApiEndpoint(path.endpoint)
 
-	if o.MarketType == exchange.MarketTypePerpetualUSDMargined {
-		r.Endpoint = "/fapi/v1/order"
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
+	params := bnhttp.Params{
+		"symbol": o.Symbol,
+	}
+	if o.OrderID != "" {
+		params["orderId"] = o.OrderID
 	} else {
-		b.client.SetApiEndpoint(bnSpotEndpoint)
+		params["origClientOrderId"] = o.ClientOrderID
 	}
+	r = r.SetFormParams(params)
 
-	r = r.SetFormParams(bnhttp.Params{
-		"symbol":            o.Symbol,
-		"origClientOrderId": o.ClientOrderID,
-	})
-
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return err
 	}
@@ -600,108 +777,321 @@ func (b *binance) CancelOrder(ctx context.Context, o *exchange.CancelOrderReques
 	return nil
 }
 
-func (b *binance) GetTickerPrice(ctx context.Context, symbol string, marketType exchange.MarketType) (decimal.Decimal, error) {
+func (b *binance) GetOpenOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest) ([]*exchange.SearchOrderResponse, error) {
 	r := &bnhttp.Request{
-		Method:   http.MethodGet,
-		Endpoint: "/api/v3/ticker/price",
-		SecType:  bnhttp.SecTypeNone,
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodGet,
+		SecType:   bnhttp.SecTypeSigned,
 	}
-
-	if marketType == exchange.MarketTypeSpot {
+	isFutures := false
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r.Endpoint = "/api/v3/openOrders"
 		b.client.SetApiEndpoint(bnSpotEndpoint)
-	} else if marketType == exchange.MarketTypePerpetualUSDMargined {
-		r.Endpoint = "/fapi/v1/ticker/price"
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
-	} else {
-		return decimal.Zero, exchange.ErrInstrumentTypeNotSupported
-	}
-
-	r = r.SetParams(bnhttp.Params{"symbol": symbol})
-	data, err := b.client.CallAPI(ctx, r)
-	if err != nil {
-		return decimal.Zero, err
+	case exchange.MarketTypeMargin:
+		r.Endpoint = "/sapi/v1/margin/openOrders"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/margin/openOrders"
+			b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+		}
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		isFutures = true
+		r.Endpoint = "/fapi/v1/openOrders"
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/um/openOrders"
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+	default:
+		return nil, exchange.ErrInstrumentTypeNotSupported
 	}
-	var res bnTickerPriceResponse
-	err = bnhttp.Json.Unmarshal(data, &res)
-	if err != nil {
-		return decimal.Zero, err
+	if req.Symbol != "" {
+		r = r.SetParams(bnhttp.Params{
+			"symbol": req.Symbol,
+		})
 	}
-	price, err := decimal.NewFromString(res.Price)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
-		return decimal.Zero, err
+		return nil, err
 	}
-	return price, nil
-}
 
-func (b *binance) spotAssets(ctx context.Context, req *exchange.GetAssetsRequest) (*bnSpotAccount, error) {
-	var res bnSpotAccount
-	r := &bnhttp.Request{
-		Method:    http.MethodGet,
-		Endpoint:  "/api/v3/account",
-		APIKey:    req.APIKey,
-		SecretKey: req.SecretKey,
-		SecType:   bnhttp.SecTypeSigned,
+	result := make([]*exchange.SearchOrderResponse, 0)
+	if isFutures {
+		var res []*bnFuturesSearchOrderResponse
+		err = bnhttp.Json.Unmarshal(data, &res)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res {
+			order, err := bnFuturesOrderToSearchOrder(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, order)
+		}
+		return result, nil
 	}
-	b.client.SetApiEndpoint(bnSpotEndpoint)
 
-	// 忽略零余额资产
-	r = r.SetParams(bnhttp.Params{"omitZeroBalances": true})
-	data, err := b.client.CallAPI(ctx, r)
-	if err != nil {
-		return nil, err
-	}
+	var res []*bnSpotSearchOrderReponse
 	err = bnhttp.Json.Unmarshal(data, &res)
 	if err != nil {
 		return nil, err
 	}
-	return &res, nil
+	for _, v := range res {
+		order, err := bnSpotOrderToSearchOrder(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
 }
 
-func (b *binance) futuresAssets(ctx context.Context, req *exchange.GetAssetsRequest) ([]*bnFuturesBalance, error) {
-	r := &bnhttp.Request{
-		Method:    http.MethodGet,
-		Endpoint:  "/fapi/v3/balance",
-		APIKey:    req.APIKey,
-		SecretKey: req.SecretKey,
-		SecType:   bnhttp.SecTypeSigned,
+// CancelAllOrders 币安撤销全部挂单接口必须指定交易对，未指定时先查询挂单再按交易对逐个撤销
+func (b *binance) CancelAllOrders(ctx context.Context, req *exchange.CancelAllOrdersRequest) error {
+	if req.Symbol != "" {
+		return b.cancelAllOrders(ctx, req)
 	}
-	b.client.SetApiEndpoint(bnFuturesEndpoint)
-	data, err := b.client.CallAPI(ctx, r)
+	orders, err := b.GetOpenOrders(ctx, &exchange.GetOpenOrdersRequest{
+		APIKey:           req.APIKey,
+		SecretKey:        req.SecretKey,
+		MarketType:       req.MarketType,
+		IsUnifiedAccount: req.IsUnifiedAccount,
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
-	res := make([]*bnFuturesBalance, 0)
-	err = bnhttp.Json.Unmarshal(data, &res)
-	if err != nil {
-		return nil, err
+	symbols := make(map[string]struct{})
+	errs := make([]error, 0)
+	for _, v := range orders {
+		if _, ok := symbols[v.Symbol]; ok {
+			continue
+		}
+		symbols[v.Symbol] = struct{}{}
+		r := *req
+		r.Symbol = v.Symbol
+		if err := b.cancelAllOrders(ctx, &r); err != nil {
+			errs = append(errs, fmt.Errorf("cancel %s orders failed: %w", v.Symbol, err))
+		}
 	}
-	return res, nil
+	return errors.Join(errs...)
 }
 
-func (b *binance) createSpotOrder(ctx context.Context, o *exchange.CreateOrderRequest) error {
-	r := &bnhttp.Request{
-		APIKey:    o.APIKey,
-		SecretKey: o.SecretKey,
-		Method:    http.MethodPost,
-		Endpoint:  "/api/v3/order",
-		SecType:   bnhttp.SecTypeSigned,
+// AmendOrder 合约使用 PUT /fapi/v1/order 原单修改，保留队列优先级；现货使用 cancelReplace 撤单重下
+func (b *binance) AmendOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	if o.ClientOrderID == "" && o.OrderID == "" {
+		return nil, errors.New("client order id or order id is required")
 	}
-	b.client.SetApiEndpoint(bnSpotEndpoint)
+	if o.MarketType == exchange.MarketTypeSpot {
+		return b.amendSpotOrder(ctx, o)
+	} else if o.MarketType.IsContract() {
+		return b.amendFuturesOrder(ctx, o)
+	}
+	return nil, exchange.ErrInstrumentTypeNotSupported
+}
+
+// BatchCreateOrders 合约使用 /fapi/v1/batchOrders（币本位为 /dapi/v1/batchOrders）每次最多 5 笔，其他市场逐笔下单
+func (b *binance) BatchCreateOrders(ctx context.Context, o []*exchange.CreateOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	for _, v := range o[1:] {
+		if v.APIKey != o[0].APIKey || v.MarketType != o[0].MarketType || v.IsUnifiedAccount != o[0].IsUnifiedAccount {
+			return nil, errors.New("batch orders must share the same account and market type")
+		}
+	}
+	if !o[0].MarketType.IsContract() || o[0].IsUnifiedAccount {
+		result := make([]*exchange.BatchOrderResult, 0, len(o))
+		for _, v := range o {
+			res := &exchange.BatchOrderResult{ClientOrderID: v.ClientOrderID}
+			ack, err := b.CreateOrder(ctx, v)
+			if err != nil {
+				res.Err = err
+			} else {
+				res.OrderID = ack.OrderID
+			}
+			result = append(result, res)
+		}
+		return result, nil
+	}
+
+	result := make([]*exchange.BatchOrderResult, 0, len(o))
+	for i := 0; i < len(o); i += bnBatchCreateOrdersLimit {
+		end := min(i+bnBatchCreateOrdersLimit, len(o))
+		res, err := b.batchCreateFuturesOrders(ctx, o[i:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, res...)
+	}
+	return result, nil
+}
+
+// BatchCancelOrders 合约使用 /fapi/v1/batchOrders（币本位为 /dapi/v1/batchOrders）按交易对每次最多撤 10 笔，其他市场逐笔撤单
+func (b *binance) BatchCancelOrders(ctx context.Context, o []*exchange.CancelOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	if len(o) == 0 {
+		return nil, nil
+	}
+	for _, v := range o[1:] {
+		if v.APIKey != o[0].APIKey || v.MarketType != o[0].MarketType || v.IsUnifiedAccount != o[0].IsUnifiedAccount {
+			return nil, errors.New("batch orders must share the same account and market type")
+		}
+	}
+	result := make([]*exchange.BatchOrderResult, len(o))
+	// 统一账户没有批量撤单接口，逐个撤单
+	if !o[0].MarketType.IsContract() || o[0].IsUnifiedAccount {
+		for i, v := range o {
+			result[i] = &exchange.BatchOrderResult{
+				ClientOrderID: v.ClientOrderID,
+				OrderID:       v.OrderID,
+				Err:           b.CancelOrder(ctx, v),
+			}
+		}
+		return result, nil
+	}
+
+	// 批量撤单接口要求同一交易对，按交易对分组并保留原始下标
+	groups := make(map[string][]int)
+	symbols := make([]string, 0)
+	for i, v := range o {
+		if _, ok := groups[v.Symbol]; !ok {
+			symbols = append(symbols, v.Symbol)
+		}
+		groups[v.Symbol] = append(groups[v.Symbol], i)
+	}
+	for _, symbol := range symbols {
+		idx := groups[symbol]
+		for i := 0; i < len(idx); i += bnBatchCancelOrdersLimit {
+			chunk := idx[i:min(i+bnBatchCancelOrdersLimit, len(idx))]
+			reqs := make([]*exchange.CancelOrderRequest, 0, len(chunk))
+			for _, j := range chunk {
+				reqs = append(reqs, o[j])
+			}
+			res, err := b.batchCancelFuturesOrders(ctx, symbol, reqs)
+			if err != nil {
+				return nil, err
+			}
+			for k, j := range chunk {
+				result[j] = res[k]
+			}
+		}
+	}
+	return result, nil
+}
+
+func (b *binance) GetTickerPrice(ctx context.Context, symbol string, marketType exchange.MarketType) (decimal.Decimal, error) {
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: "/api/v3/ticker/price",
+		SecType:  bnhttp.SecTypeNone,
+	}
+
+	if marketType == exchange.MarketTypeSpot {
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	} else if marketType == exchange.MarketTypePerpetualUSDMargined || marketType.IsCoinMargined() {
+		r.Endpoint = "/fapi/v1/ticker/price"
+		b.setFuturesEndpoint(r, marketType)
+	} else {
+		return decimal.Zero, exchange.ErrInstrumentTypeNotSupported
+	}
+
+	r = r.SetParams(bnhttp.Params{"symbol": symbol})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	var res bnTickerPriceResponse
+	if marketType.IsCoinMargined() {
+		// dapi 按交易对查询也返回数组
+		var list []bnTickerPriceResponse
+		if err = bnhttp.Json.Unmarshal(data, &list); err != nil {
+			return decimal.Zero, err
+		}
+		if len(list) == 0 {
+			return decimal.Zero, exchange.ErrInvalidSymbol
+		}
+		res = list[0]
+	} else if err = bnhttp.Json.Unmarshal(data, &res); err != nil {
+		return decimal.Zero, err
+	}
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return price, nil
+}
+
+func (b *binance) spotAssets(ctx context.Context, req *exchange.GetAssetsRequest) (*bnSpotAccount, error) {
+	var res bnSpotAccount
+	r := &bnhttp.Request{
+		Method:    http.MethodGet,
+		Endpoint:  "/api/v3/account",
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnSpotEndpoint)
+
+	// 忽略零余额资产
+	r = r.SetParams(bnhttp.Params{"omitZeroBalances": true})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (b *binance) futuresAssets(ctx context.Context, req *exchange.GetAssetsRequest) ([]*bnFuturesBalance, error) {
+	r := &bnhttp.Request{
+		Method:    http.MethodGet,
+		Endpoint:  "/fapi/v3/balance",
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.setFuturesEndpoint(r, req.MarketType)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*bnFuturesBalance, 0)
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (b *binance) createSpotOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	r := &bnhttp.Request{
+		APIKey:    o.APIKey,
+		SecretKey: o.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/api/v3/order",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnSpotEndpoint)
 	r = r.SetFormParams(toBnSpotOrderParams(o))
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	res := &bnSpotCreateOrderResponse{}
 	err = bnhttp.Json.Unmarshal(data, res)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return res.toCreateOrderResponse()
 }
 
 // TOFIX:创建杠杠订单默认自动借款和还款，后期按需要把该参数抽离出来sideEffectType
-func (b *binance) createMarginOrder(ctx context.Context, o *exchange.CreateOrderRequest) error {
+func (b *binance) createMarginOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
@@ -709,67 +1099,434 @@ func (b *binance) createMarginOrder(ctx context.Context, o *exchange.CreateOrder
 		Endpoint:  "/sapi/v1/margin/order",
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	if o.IsUnifiedAccount {
-		r.Endpoint = "/papi/v1/margin/order"
-		b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	if o.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/margin/order"
+		b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	} else {
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	}
+	r = r.SetFormParams(toBnMarginOrderParams(o))
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	// 杠杆下单回执与现货字段相同
+	res := &bnSpotCreateOrderResponse{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.toCreateOrderResponse()
+}
+
+func (b *binance) createFuturesOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	r := &bnhttp.Request{
+		APIKey:    o.APIKey,
+		SecretKey: o.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/fapi/v1/order",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	if o.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/um/order"
+	}
+	b.setFuturesEndpoint(r, o.MarketType)
+	r = r.SetFormParams(toBnFuturesOrderParams(o))
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &bnFuturesOrderResponse{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+	return res.toCreateOrderResponse()
+}
+
+func (b *binance) batchCreateFuturesOrders(ctx context.Context, o []*exchange.CreateOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	orders := make([]map[string]string, 0, len(o))
+	for _, v := range o {
+		orders = append(orders, toBnBatchParams(toBnFuturesOrderParams(v)))
+	}
+	batch, err := bnhttp.Json.Marshal(orders)
+	if err != nil {
+		return nil, err
+	}
+	r := &bnhttp.Request{
+		APIKey:    o[0].APIKey,
+		SecretKey: o[0].SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/fapi/v1/batchOrders",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.setFuturesEndpoint(r, o[0].MarketType)
+	r = r.SetFormParams(bnhttp.Params{
+		"batchOrders": string(batch),
+	})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var res []*bnBatchOrderResponse
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != len(o) {
+		return nil, fmt.Errorf("batch orders response mismatch, expected %d, got %d", len(o), len(res))
+	}
+	result := make([]*exchange.BatchOrderResult, 0, len(o))
+	for i, v := range res {
+		result = append(result, v.toBatchOrderResult(o[i].ClientOrderID))
+	}
+	return result, nil
+}
+
+func (b *binance) batchCancelFuturesOrders(ctx context.Context, symbol string, o []*exchange.CancelOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	params := bnhttp.Params{"symbol": symbol}
+	// orderIdList 与 origClientOrderIdList 不能混用，全部带有订单ID时按订单ID撤单
+	orderIDs := make([]int64, 0, len(o))
+	for _, v := range o {
+		id, err := strconv.ParseInt(v.OrderID, 10, 64)
+		if err != nil {
+			break
+		}
+		orderIDs = append(orderIDs, id)
+	}
+	if len(orderIDs) == len(o) {
+		params["orderIdList"] = orderIDs
+	} else {
+		ids := make([]string, 0, len(o))
+		for _, v := range o {
+			ids = append(ids, v.ClientOrderID)
+		}
+		params["origClientOrderIdList"] = ids
+	}
+	r := &bnhttp.Request{
+		APIKey:    o[0].APIKey,
+		SecretKey: o[0].SecretKey,
+		Method:    http.MethodDelete,
+		Endpoint:  "/fapi/v1/batchOrders",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.setFuturesEndpoint(r, o[0].MarketType)
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var res []*bnBatchOrderResponse
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != len(o) {
+		return nil, fmt.Errorf("batch orders response mismatch, expected %d, got %d", len(o), len(res))
+	}
+	result := make([]*exchange.BatchOrderResult, 0, len(o))
+	for i, v := range res {
+		result = append(result, v.toBatchOrderResult(o[i].ClientOrderID))
+	}
+	return result, nil
+}
+
+func (b *binance) cancelAllOrders(ctx context.Context, req *exchange.CancelAllOrdersRequest) error {
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodDelete,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r.Endpoint = "/api/v3/openOrders"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeMargin:
+		r.Endpoint = "/sapi/v1/margin/openOrders"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/margin/allOpenOrders"
+			b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+		}
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		r.Endpoint = "/fapi/v1/allOpenOrders"
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/um/allOpenOrders"
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+	default:
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	r = r.SetFormParams(bnhttp.Params{
+		"symbol": req.Symbol,
+	})
+	_, err := b.callAPI(ctx, r)
+	if err != nil {
+		// 现货没有挂单时返回 -2011，视为撤销成功
+		if errors.Is(err, exchange.ErrOrderNotFound) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (b *binance) amendSpotOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	side, orderType, timeInForce, size, price := o.Side, o.OrderType, o.TimeInForce, o.NewSize, o.NewPrice
+	if side == "" || orderType == "" || size.IsZero() || price.IsZero() {
+		orig, err := b.querySpotOrder(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		if side == "" {
+			side = exchange.SideType(orig.Side)
+		}
+		if orderType == "" {
+			orderType = exchange.OrderType(orig.OrderType)
+		}
+		if timeInForce == "" {
+			timeInForce = exchange.TimeInForce(orig.TimeInForce)
+		}
+		if size.IsZero() {
+			if size, err = decimal.NewFromString(orig.Volume); err != nil {
+				return nil, err
+			}
+		}
+		if price.IsZero() {
+			if price, err = decimal.NewFromString(orig.Price); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	r := &bnhttp.Request{
+		APIKey:    o.APIKey,
+		SecretKey: o.SecretKey,
+		Method:    http.MethodPost,
+		Endpoint:  "/api/v3/order/cancelReplace",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnSpotEndpoint)
+	params := bnhttp.Params{
+		"symbol":            o.Symbol.OriginalSymbol,
+		"side":              side,
+		"type":              orderType,
+		"cancelReplaceMode": "STOP_ON_FAILURE",
+		"quantity":          size.String(),
+		"price":             price.String(),
+	}
+	if orderType == exchange.OrderTypeLimit {
+		if timeInForce == "" {
+			timeInForce = exchange.TimeInForceGTC
+		}
+		params["timeInForce"] = timeInForce
+	}
+	if o.ClientOrderID != "" {
+		params["cancelOrigClientOrderId"] = o.ClientOrderID
+	} else {
+		params["cancelOrderId"] = o.OrderID
+	}
+	if o.NewClientOrderID != "" {
+		params["newClientOrderId"] = o.NewClientOrderID
+	}
+	r = r.SetFormParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &bnSpotCancelReplaceResponse{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+	if res.NewOrderResult != "SUCCESS" || res.NewOrderResponse == nil {
+		return nil, fmt.Errorf("order not replaced, cancelResult: %s, newOrderResult: %s", res.CancelResult, res.NewOrderResult)
+	}
+
+	nr := res.NewOrderResponse
+	newPrice, err := decimal.NewFromString(nr.Price)
+	if err != nil {
+		return nil, err
+	}
+	newSize, err := decimal.NewFromString(nr.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.AmendOrderResponse{
+		Symbol:        nr.Symbol,
+		ClientOrderID: nr.ClientOrderID,
+		OrderID:       fmt.Sprintf("%d", nr.OrderID),
+		State:         exchange.OrderState(nr.Status),
+		Price:         newPrice,
+		Size:          newSize,
+		UpdateTime:    nr.TransactTime,
+	}, nil
+}
+
+func (b *binance) amendFuturesOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	if o.NewClientOrderID != "" {
+		return nil, errors.New("binance futures amend order does not support new client order id")
+	}
+	// 改单时 side、quantity、price 均为必填
+	side, size, price := o.Side, o.NewSize, o.NewPrice
+	if side == "" || size.IsZero() || price.IsZero() {
+		orig, err := b.queryFuturesOrder(ctx, o)
+		if err != nil {
+			return nil, err
+		}
+		if side == "" {
+			side = exchange.SideType(orig.Side)
+		}
+		if size.IsZero() {
+			if size, err = decimal.NewFromString(orig.Volume); err != nil {
+				return nil, err
+			}
+		}
+		if price.IsZero() {
+			if price, err = decimal.NewFromString(orig.Price); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	r := &bnhttp.Request{
+		APIKey:    o.APIKey,
+		SecretKey: o.SecretKey,
+		Method:    http.MethodPut,
+		Endpoint:  "/fapi/v1/order",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	if o.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/um/order"
+	}
+	b.setFuturesEndpoint(r, o.MarketType)
+	params := bnhttp.Params{
+		"symbol":   o.Symbol.OriginalSymbol,
+		"side":     side,
+		"quantity": size.String(),
+		"price":    price.String(),
+	}
+	if o.ClientOrderID != "" {
+		params["origClientOrderId"] = o.ClientOrderID
+	} else {
+		params["orderId"] = o.OrderID
+	}
+	r = r.SetFormParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := &bnFuturesOrderResponse{}
+	err = bnhttp.Json.Unmarshal(data, res)
+	if err != nil {
+		return nil, err
+	}
+
+	newPrice, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return nil, err
+	}
+	newSize, err := decimal.NewFromString(res.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.AmendOrderResponse{
+		Symbol:        res.Symbol,
+		ClientOrderID: res.ClientOrderID,
+		OrderID:       fmt.Sprintf("%d", res.OrderID),
+		State:         exchange.OrderState(res.Status),
+		Price:         newPrice,
+		Size:          newSize,
+		UpdateTime:    res.UpdateTime,
+	}, nil
+}
+
+// querySpotOrder 查询原订单，用于补全改单参数
+func (b *binance) querySpotOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*bnSpotSearchOrderReponse, error) {
+	r := &bnhttp.Request{
+		APIKey:    o.APIKey,
+		SecretKey: o.SecretKey,
+		Method:    http.MethodGet,
+		Endpoint:  "/api/v3/order",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnSpotEndpoint)
+	params := bnhttp.Params{
+		"symbol": o.Symbol.OriginalSymbol,
+	}
+	if o.ClientOrderID != "" {
+		params["origClientOrderId"] = o.ClientOrderID
 	} else {
-		b.client.SetApiEndpoint(bnSpotEndpoint)
+		params["orderId"] = o.OrderID
 	}
-	r = r.SetFormParams(toBnMarginOrderParams(o))
-	data, err := b.client.CallAPI(ctx, r)
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	res := &bnMarginCreateOrderResponse{}
+	res := &bnSpotSearchOrderReponse{}
 	err = bnhttp.Json.Unmarshal(data, res)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return res, nil
 }
 
-func (b *binance) createFuturesOrder(ctx context.Context, o *exchange.CreateOrderRequest) error {
+// queryFuturesOrder 查询原订单，用于补全改单参数
+func (b *binance) queryFuturesOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*bnFuturesSearchOrderResponse, error) {
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
-		Method:    http.MethodPost,
+		Method:    http.MethodGet,
 		Endpoint:  "/fapi/v1/order",
 		SecType:   bnhttp.SecTypeSigned,
 	}
 	if o.IsUnifiedAccount {
 		r.Endpoint = "/papi/v1/um/order"
-		b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	}
+	b.setFuturesEndpoint(r, o.MarketType)
+	params := bnhttp.Params{
+		"symbol": o.Symbol.OriginalSymbol,
+	}
+	if o.ClientOrderID != "" {
+		params["origClientOrderId"] = o.ClientOrderID
 	} else {
-		b.client.SetApiEndpoint(bnFuturesEndpoint)
+		params["orderId"] = o.OrderID
 	}
-	r = r.SetFormParams(toBnFuturesOrderParams(o))
-	data, err := b.client.CallAPI(ctx, r)
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	res := &bnFuturesOrderResponse{}
+	res := &bnFuturesSearchOrderResponse{}
 	err = bnhttp.Json.Unmarshal(data, res)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	return res, nil
 }
 
 func (b *binance) searchSpotOrder(ctx context.Context, o *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
+	path, err := bnOrderPaths(o.MarketType, o.IsUnifiedAccount)
+	if err != nil {
+		return nil, err
+	}
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
 		Method:    http.MethodGet,
-		Endpoint:  "/api/v3/order",
+		Endpoint:  path.order,
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	b.client.SetApiEndpoint(bnSpotEndpoint)
+	b.client.SetApiEndpoint(path.endpoint)
 	params := bnhttp.Params{
 		"symbol":            o.Symbol.OriginalSymbol,
 		"origClientOrderId": o.ClientOrderID,
 	}
 	r = r.SetParams(params)
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -794,7 +1551,10 @@ func (b *binance) searchSpotOrder(ctx context.Context, o *exchange.SearchOrderRe
 	if err != nil {
 		return nil, err
 	}
-	avgPrice := filledQuoteVolume.Div(filledVolume)
+	avgPrice := decimal.Zero
+	if !filledVolume.IsZero() {
+		avgPrice = filledQuoteVolume.Div(filledVolume)
+	}
 
 	result := &exchange.SearchOrderResponse{
 		ClientOrderID:     res.ClientOrderID,
@@ -815,11 +1575,12 @@ func (b *binance) searchSpotOrder(ctx context.Context, o *exchange.SearchOrderRe
 
 	// 获取成交记录，统计手续费
 	trades, err := b.SearchTrades(ctx, &exchange.SearchTradesRequest{
-		APIKey:     o.APIKey,
-		SecretKey:  o.SecretKey,
-		Symbol:     o.Symbol.OriginalSymbol,
-		OrderID:    result.OrderID,
-		MarketType: exchange.MarketTypeSpot,
+		APIKey:           o.APIKey,
+		SecretKey:        o.SecretKey,
+		Symbol:           o.Symbol.OriginalSymbol,
+		OrderID:          result.OrderID,
+		MarketType:       o.MarketType,
+		IsUnifiedAccount: o.IsUnifiedAccount,
 	})
 	if err != nil {
 		return nil, err
@@ -840,20 +1601,24 @@ func (b *binance) searchSpotOrder(ctx context.Context, o *exchange.SearchOrderRe
 }
 
 func (b *binance) searchFuturesOrder(ctx context.Context, o *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
+	path, err := bnOrderPaths(o.MarketType, o.IsUnifiedAccount)
+	if err != nil {
+		return nil, err
+	}
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
 		Method:    http.MethodGet,
-		Endpoint:  "/fapi/v1/order",
+		Endpoint:  path.order,
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	b.client.SetApiEndpoint(bnSpotEndpoint)
+	b.client.SetApiEndpoint(path.endpoint)
 	params := bnhttp.Params{
 		"symbol":            o.Symbol.OriginalSymbol,
 		"origClientOrderId": o.ClientOrderID,
 	}
 	r = r.SetParams(params)
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -870,7 +1635,7 @@ func (b *binance) searchFuturesOrder(ctx context.Context, o *exchange.SearchOrde
 	if err != nil {
 		return nil, err
 	}
-	filledQuoteVolume, err := decimal.NewFromString(res.FilledQuoteVolume)
+	filledQuoteVolume, err := res.filledQuoteVolume()
 	if err != nil {
 		return nil, err
 	}
@@ -903,11 +1668,12 @@ func (b *binance) searchFuturesOrder(ctx context.Context, o *exchange.SearchOrde
 
 	// 获取成交记录，统计手续费
 	trades, err := b.SearchTrades(ctx, &exchange.SearchTradesRequest{
-		APIKey:     o.APIKey,
-		SecretKey:  o.SecretKey,
-		Symbol:     o.Symbol.OriginalSymbol,
-		OrderID:    result.OrderID,
-		MarketType: exchange.MarketTypeSpot,
+		APIKey:           o.APIKey,
+		SecretKey:        o.SecretKey,
+		Symbol:           o.Symbol.OriginalSymbol,
+		OrderID:          result.OrderID,
+		MarketType:       o.MarketType,
+		IsUnifiedAccount: o.IsUnifiedAccount,
 	})
 	if err != nil {
 		return nil, err
@@ -928,20 +1694,24 @@ func (b *binance) searchFuturesOrder(ctx context.Context, o *exchange.SearchOrde
 }
 
 func (b *binance) searchSpotTrades(ctx context.Context, o *exchange.SearchTradesRequest) ([]*exchange.SearchTradesResponse, error) {
+	path, err := bnOrderPaths(o.MarketType, o.IsUnifiedAccount)
+	if err != nil {
+		return nil, err
+	}
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
 		Method:    http.MethodGet,
-		Endpoint:  "/api/v3/myTrades",
+		Endpoint:  path.trades,
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	b.client.SetApiEndpoint(bnSpotEndpoint)
+	b.client.SetApiEndpoint(path.endpoint)
 	params := bnhttp.Params{
 		"symbol":  o.Symbol,
 		"orderId": o.OrderID,
 	}
 	r = r.SetParams(params)
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -952,52 +1722,34 @@ func (b *binance) searchSpotTrades(ctx context.Context, o *exchange.SearchTrades
 	}
 	result := make([]*exchange.SearchTradesResponse, 0)
 	for _, v := range res {
-		quantity, err := decimal.NewFromString(v.Quantity)
-		if err != nil {
-			return nil, err
-		}
-		price, err := decimal.NewFromString(v.Price)
+		trade, err := bnSpotTradeToSearchTrade(v)
 		if err != nil {
 			return nil, err
 		}
-		feeCost, err := decimal.NewFromString(v.Commission)
-		if err != nil {
-			return nil, err
-		}
-		by := exchange.ByTaker
-		if v.IsMaker {
-			by = exchange.ByMaker
-		}
-		result = append(result, &exchange.SearchTradesResponse{
-			Symbol:   v.Symbol,
-			ID:       fmt.Sprintf("%d", v.ID),
-			OrderID:  fmt.Sprintf("%d", v.OrderID),
-			Price:    price,
-			Volume:   quantity,
-			FeeCost:  feeCost,
-			FeeAsset: v.CommissionAsset,
-			Time:     v.Time,
-			By:       by,
-		})
+		result = append(result, trade)
 	}
 	return result, nil
 }
 
 func (b *binance) searchFuturesTrades(ctx context.Context, o *exchange.SearchTradesRequest) ([]*exchange.SearchTradesResponse, error) {
+	path, err := bnOrderPaths(o.MarketType, o.IsUnifiedAccount)
+	if err != nil {
+		return nil, err
+	}
 	r := &bnhttp.Request{
 		APIKey:    o.APIKey,
 		SecretKey: o.SecretKey,
 		Method:    http.MethodGet,
-		Endpoint:  "/fapi/v1/userTrades",
+		Endpoint:  path.trades,
 		SecType:   bnhttp.SecTypeSigned,
 	}
-	b.client.SetApiEndpoint(bnFuturesEndpoint)
+	b.client.SetApiEndpoint(path.endpoint)
 	params := bnhttp.Params{
 		"symbol":  o.Symbol,
 		"orderId": o.OrderID,
 	}
 	r = r.SetParams(params)
-	data, err := b.client.CallAPI(ctx, r)
+	data, err := b.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -1008,39 +1760,17 @@ func (b *binance) searchFuturesTrades(ctx context.Context, o *exchange.SearchTra
 	}
 	result := make([]*exchange.SearchTradesResponse, 0)
 	for _, v := range res {
-		quantity, err := decimal.NewFromString(v.Quantity)
-		if err != nil {
-			return nil, err
-		}
-		price, err := decimal.NewFromString(v.Price)
-		if err != nil {
-			return nil, err
-		}
-		feeCost, err := decimal.NewFromString(v.Commission)
+		trade, err := bnFuturesTradeToSearchTrade(v)
 		if err != nil {
 			return nil, err
 		}
-		by := exchange.ByTaker
-		if v.IsMaker {
-			by = exchange.ByMaker
-		}
-		result = append(result, &exchange.SearchTradesResponse{
-			Symbol:   v.Symbol,
-			ID:       fmt.Sprintf("%d", v.ID),
-			OrderID:  fmt.Sprintf("%d", v.OrderID),
-			Price:    price,
-			Volume:   quantity,
-			FeeCost:  feeCost,
-			FeeAsset: v.CommissionAsset,
-			Time:     v.Time,
-			By:       by,
-		})
+		result = append(result, trade)
 	}
 
 	return result, nil
 }
 
-func bnFuturesAssetsToAssets(b []*bnFuturesBalance) ([]exchange.Asset, error) {
+func bnFuturesAssetsToAssets(b []*bnFuturesBalance, marketType exchange.MarketType) ([]exchange.Asset, error) {
 	result := make([]exchange.Asset, 0)
 	for _, v := range b {
 		// 总余额(未包含未实现盈亏)
@@ -1065,7 +1795,7 @@ func bnFuturesAssetsToAssets(b []*bnFuturesBalance) ([]exchange.Asset, error) {
 			Free:       available,
 			Locked:     marginBalance.Sub(available),
 			Exchange:   exchange.BinanceExchange,
-			MarketType: exchange.MarketTypeFuturesUSDMargined,
+			MarketType: marketType,
 		})
 	}
 	return result, nil
@@ -1098,7 +1828,7 @@ func toBnFuturesOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 		"symbol":           o.Symbol.OriginalSymbol,
 		"side":             o.Side,
 		"quantity":         o.Size,
-		"newOrderRespType": "ACK",
+		"newOrderRespType": "RESULT", // 返回下单后的状态，IOC/FOK 和市价单为最终成交结果
 	}
 	if o.OrderType == exchange.OrderTypeLimitMaker {
 		m["type"] = "LIMIT"
@@ -1106,15 +1836,47 @@ func toBnFuturesOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	} else if o.OrderType == exchange.OrderTypeLimit {
 		m["type"] = "LIMIT"
 		m["timeInForce"] = "GTC"
+		if o.TimeInForce != "" {
+			m["timeInForce"] = o.TimeInForce
+		}
+		if o.TimeInForce == exchange.TimeInForceGTD {
+			// 过期时间精确到秒，必须大于当前时间 600 秒，到期后推送 EXPIRED 订单事件
+			m["goodTillDate"] = o.ExpireTime
+		}
 	} else {
 		m["type"] = o.OrderType
 	}
 	if o.PositionSide != "" {
 		m["positionSide"] = o.PositionSide
-	}
-	if !o.Price.IsZero() {
+	} else if o.ReduceOnly {
+		// 双向持仓模式下不能发送 reduceOnly
+		m["reduceOnly"] = "true"
+	}
+	if o.ClosePosition {
+		// 平仓单不能同时发送 quantity 和 reduceOnly
+		m["closePosition"] = "true"
+		delete(m, "quantity")
+		delete(m, "reduceOnly")
+	}
+	if o.PriceMatch != "" {
+		// 盘口价格下单不能同时发送 price
+		m["priceMatch"] = o.PriceMatch
+	} else if !o.Price.IsZero() {
 		m["price"] = o.Price.String()
 	}
+	if !o.StopPrice.IsZero() {
+		m["stopPrice"] = o.StopPrice.String()
+	}
+	if o.SelfTradePreventionMode != "" {
+		m["selfTradePreventionMode"] = o.SelfTradePreventionMode
+	}
+	if o.OrderType == exchange.OrderTypeTrailingStopMarket {
+		if !o.ActivationPrice.IsZero() {
+			m["activationPrice"] = o.ActivationPrice.String()
+		}
+		// 币安合约回调比例单位为百分比
+		m["callbackRate"] = o.CallbackRate.Mul(decimal.NewFromInt(100)).String()
+	}
 	if o.ClientOrderID != "" {
 		m["newClientOrderId"] = o.ClientOrderID
 	}
@@ -1124,12 +1886,15 @@ func toBnFuturesOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 func toBnSpotOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	// TODO: 公共参数和每个交易所的参数之间的变换，这个得后面根据具体情况再来完善
 	m := bnhttp.Params{
-		"symbol": o.Symbol.OriginalSymbol,
-		"side":   o.Side,
-		"type":   o.OrderType,
+		"symbol":           o.Symbol.OriginalSymbol,
+		"side":             o.Side,
+		"type":             bnSpotOrderType(o.OrderType),
+		"newOrderRespType": "FULL", // 返回立即成交明细
 	}
 	if o.TimeInForce != "" {
 		m["timeInForce"] = o.TimeInForce
+	} else if o.OrderType == exchange.OrderTypeStop || o.OrderType == exchange.OrderTypeTakeProfit {
+		m["timeInForce"] = exchange.TimeInForceGTC
 	}
 	if !o.Size.IsZero() {
 		m["quantity"] = o.Size.String()
@@ -1137,6 +1902,19 @@ func toBnSpotOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	if !o.Price.IsZero() {
 		m["price"] = o.Price.String()
 	}
+	if !o.StopPrice.IsZero() {
+		m["stopPrice"] = o.StopPrice.String()
+	}
+	if o.OrderType == exchange.OrderTypeTrailingStopMarket {
+		// 现货跟踪止损单位为 BIPS，stopPrice 为激活价格
+		m["trailingDelta"] = o.CallbackRate.Mul(decimal.NewFromInt(10000)).IntPart()
+		if !o.ActivationPrice.IsZero() {
+			m["stopPrice"] = o.ActivationPrice.String()
+		}
+	}
+	if o.SelfTradePreventionMode != "" {
+		m["selfTradePreventionMode"] = o.SelfTradePreventionMode
+	}
 	if o.ClientOrderID != "" {
 		m["newClientOrderId"] = o.ClientOrderID
 	}
@@ -1145,12 +1923,15 @@ func toBnSpotOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 
 func toBnMarginOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	m := bnhttp.Params{
-		"symbol": o.Symbol.OriginalSymbol,
-		"side":   o.Side,
-		"type":   o.OrderType,
+		"symbol":           o.Symbol.OriginalSymbol,
+		"side":             o.Side,
+		"type":             bnSpotOrderType(o.OrderType),
+		"newOrderRespType": "FULL", // 返回立即成交明细
 	}
 	if o.TimeInForce != "" {
 		m["timeInForce"] = o.TimeInForce
+	} else if o.OrderType == exchange.OrderTypeStop || o.OrderType == exchange.OrderTypeTakeProfit {
+		m["timeInForce"] = exchange.TimeInForceGTC
 	}
 	if !o.Size.IsZero() {
 		m["quantity"] = o.Size.String()
@@ -1158,6 +1939,19 @@ func toBnMarginOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	if !o.Price.IsZero() {
 		m["price"] = o.Price.String()
 	}
+	if !o.StopPrice.IsZero() {
+		m["stopPrice"] = o.StopPrice.String()
+	}
+	if o.OrderType == exchange.OrderTypeTrailingStopMarket {
+		// 现货跟踪止损单位为 BIPS，stopPrice 为激活价格
+		m["trailingDelta"] = o.CallbackRate.Mul(decimal.NewFromInt(10000)).IntPart()
+		if !o.ActivationPrice.IsZero() {
+			m["stopPrice"] = o.ActivationPrice.String()
+		}
+	}
+	if o.SelfTradePreventionMode != "" {
+		m["selfTradePreventionMode"] = o.SelfTradePreventionMode
+	}
 	if o.ClientOrderID != "" {
 		m["newClientOrderId"] = o.ClientOrderID
 	}
@@ -1165,3 +1959,367 @@ func toBnMarginOrderParams(o *exchange.CreateOrderRequest) bnhttp.Params {
 	m["sideEffectType"] = "AUTO_BORROW_REPAY"
 	return m
 }
+
+// bnSpotOrderType 现货和杠杆的条件单类型与合约不同
+func bnSpotOrderType(orderType exchange.OrderType) string {
+	switch orderType {
+	case exchange.OrderTypeStop:
+		return "STOP_LOSS_LIMIT"
+	case exchange.OrderTypeStopMarket, exchange.OrderTypeTrailingStopMarket:
+		return "STOP_LOSS"
+	case exchange.OrderTypeTakeProfit:
+		return "TAKE_PROFIT_LIMIT"
+	case exchange.OrderTypeTakeProfitMarket:
+		return "TAKE_PROFIT"
+	}
+	return string(orderType)
+}
+
+// toBnBatchParams 批量接口中每个订单参数都以字符串形式传递
+func toBnBatchParams(p bnhttp.Params) map[string]string {
+	m := make(map[string]string, len(p))
+	for k, v := range p {
+		m[k] = fmt.Sprintf("%v", v)
+	}
+	return m
+}
+
+func (r *bnBatchOrderResponse) toBatchOrderResult(clientOrderID string) *exchange.BatchOrderResult {
+	if r.Code != 0 {
+		return &exchange.BatchOrderResult{
+			ClientOrderID: clientOrderID,
+			Err:           bnError(&bnhttp.APIError{Code: r.Code, Message: r.Msg}),
+		}
+	}
+	return &exchange.BatchOrderResult{
+		ClientOrderID: r.ClientOrderID,
+		OrderID:       strconv.FormatInt(r.OrderID, 10),
+	}
+}
+
+func (r *bnSpotCreateOrderResponse) toCreateOrderResponse() (*exchange.CreateOrderResponse, error) {
+	price, err := decimal.NewFromString(r.Price)
+	if err != nil {
+		return nil, err
+	}
+	origQuantity, err := decimal.NewFromString(r.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+	executedQuantity, err := decimal.NewFromString(r.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+	cumQuote, err := decimal.NewFromString(r.CummulativeQuoteQuantity)
+	if err != nil {
+		return nil, err
+	}
+	avgPrice := decimal.Zero
+	if !executedQuantity.IsZero() {
+		avgPrice = cumQuote.Div(executedQuantity)
+	}
+	orderID := strconv.FormatInt(r.OrderID, 10)
+	fills := make([]*exchange.SearchTradesResponse, 0, len(r.Fills))
+	for _, f := range r.Fills {
+		fillPrice, err := decimal.NewFromString(f.Price)
+		if err != nil {
+			return nil, err
+		}
+		quantity, err := decimal.NewFromString(f.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		commission, err := decimal.NewFromString(f.Commission)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, &exchange.SearchTradesResponse{
+			Symbol:        r.Symbol,
+			ID:            strconv.FormatInt(f.TradeID, 10),
+			OrderID:       orderID,
+			ClientOrderID: r.ClientOrderID,
+			Side:          exchange.SideType(r.Side),
+			Price:         fillPrice,
+			Volume:        quantity,
+			FeeCost:       commission,
+			FeeAsset:      f.CommissionAsset,
+			Time:          r.TransactTime,
+			// 下单时立即成交的部分为吃单
+			By: exchange.ByTaker,
+		})
+	}
+	return &exchange.CreateOrderResponse{
+		TransactTime:     r.TransactTime,
+		Symbol:           r.Symbol,
+		ClientOrderID:    r.ClientOrderID,
+		OrderID:          orderID,
+		Side:             exchange.SideType(r.Side),
+		State:            exchange.OrderState(r.Status),
+		Price:            price,
+		AvgPrice:         avgPrice,
+		OriginalQuantity: origQuantity,
+		ExecutedQuantity: executedQuantity,
+		Fills:            fills,
+	}, nil
+}
+
+func (r *bnFuturesOrderResponse) toCreateOrderResponse() (*exchange.CreateOrderResponse, error) {
+	price, err := decimal.NewFromString(r.Price)
+	if err != nil {
+		return nil, err
+	}
+	origQuantity, err := decimal.NewFromString(r.OrigQuantity)
+	if err != nil {
+		return nil, err
+	}
+	executedQuantity, err := decimal.NewFromString(r.ExecutedQuantity)
+	if err != nil {
+		return nil, err
+	}
+	avgPrice, err := decimal.NewFromString(r.AvgPrice)
+	if err != nil {
+		avgPrice = decimal.Zero
+	}
+	return &exchange.CreateOrderResponse{
+		TransactTime:     r.UpdateTime,
+		Symbol:           r.Symbol,
+		ClientOrderID:    r.ClientOrderID,
+		OrderID:          strconv.FormatInt(r.OrderID, 10),
+		Side:             exchange.SideType(r.Side),
+		State:            exchange.OrderState(r.Status),
+		PositionSide:     exchange.PositionSide(r.PositionSide),
+		Price:            price,
+		AvgPrice:         avgPrice,
+		OriginalQuantity: origQuantity,
+		ExecutedQuantity: executedQuantity,
+	}, nil
+}
+
+func bnSpotOrderToSearchOrder(res *bnSpotSearchOrderReponse) (*exchange.SearchOrderResponse, error) {
+	volume, err := decimal.NewFromString(res.Volume)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return nil, err
+	}
+	filledQuoteVolume, err := decimal.NewFromString(res.FilledQuoteVolume)
+	if err != nil {
+		return nil, err
+	}
+	filledVolume, err := decimal.NewFromString(res.FilledVolume)
+	if err != nil {
+		return nil, err
+	}
+	avgPrice := decimal.Zero
+	if !filledVolume.IsZero() {
+		avgPrice = filledQuoteVolume.Div(filledVolume)
+	}
+	return &exchange.SearchOrderResponse{
+		ClientOrderID:     res.ClientOrderID,
+		OrderID:           fmt.Sprintf("%d", res.OrderID),
+		State:             exchange.OrderState(res.Status),
+		Symbol:            res.Symbol,
+		AvgPrice:          avgPrice,
+		Volume:            volume,
+		Price:             price,
+		FilledQuoteVolume: filledQuoteVolume,
+		FilledVolume:      filledVolume,
+		Side:              exchange.SideType(res.Side),
+		TimeInForce:       exchange.TimeInForce(res.TimeInForce),
+		OrderType:         exchange.OrderType(res.OrderType),
+		CreatedTime:       res.CreatedTime,
+		UpdateTime:        res.UpdateTime,
+	}, nil
+}
+
+func bnFuturesOrderToSearchOrder(res *bnFuturesSearchOrderResponse) (*exchange.SearchOrderResponse, error) {
+	volume, err := decimal.NewFromString(res.Volume)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return nil, err
+	}
+	filledQuoteVolume, err := res.filledQuoteVolume()
+	if err != nil {
+		return nil, err
+	}
+	filledVolume, err := decimal.NewFromString(res.FilledVolume)
+	if err != nil {
+		return nil, err
+	}
+	avgPrice, err := decimal.NewFromString(res.AvgPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.SearchOrderResponse{
+		ClientOrderID:     res.ClientOrderID,
+		OrderID:           fmt.Sprintf("%d", res.OrderID),
+		State:             exchange.OrderState(res.Status),
+		Symbol:            res.Symbol,
+		AvgPrice:          avgPrice,
+		Volume:            volume,
+		Price:             price,
+		FilledQuoteVolume: filledQuoteVolume,
+		FilledVolume:      filledVolume,
+		Side:              exchange.SideType(res.Side),
+		PositionSide:      exchange.PositionSide(res.PositionSide),
+		TimeInForce:       exchange.TimeInForce(res.TimeInForce),
+		OrderType:         exchange.OrderType(res.OrderType),
+		CreatedTime:       res.CreatedTime,
+		UpdateTime:        res.UpdateTime,
+	}, nil
+}
+
+// filledQuoteVolume 币本位合约没有 cumQuote，按成交的币数量乘以均价换算为美元价值
+func (r *bnFuturesSearchOrderResponse) filledQuoteVolume() (decimal.Decimal, error) {
+	if r.FilledQuoteVolume != "" || r.CumBase == "" {
+		return decimal.NewFromString(r.FilledQuoteVolume)
+	}
+	base, err := decimal.NewFromString(r.CumBase)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	avgPrice, err := decimal.NewFromString(r.AvgPrice)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return base.Mul(avgPrice), nil
+}
+
+func bnSpotTradeToSearchTrade(v *bnSpotTrades) (*exchange.SearchTradesResponse, error) {
+	quantity, err := decimal.NewFromString(v.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(v.Price)
+	if err != nil {
+		return nil, err
+	}
+	feeCost, err := decimal.NewFromString(v.Commission)
+	if err != nil {
+		return nil, err
+	}
+	by := exchange.ByTaker
+	if v.IsMaker {
+		by = exchange.ByMaker
+	}
+	side := exchange.SideTypeSell
+	if v.IsBuyer {
+		side = exchange.SideTypeBuy
+	}
+	return &exchange.SearchTradesResponse{
+		Symbol:   v.Symbol,
+		ID:       fmt.Sprintf("%d", v.ID),
+		OrderID:  fmt.Sprintf("%d", v.OrderID),
+		Side:     side,
+		Price:    price,
+		Volume:   quantity,
+		FeeCost:  feeCost,
+		FeeAsset: v.CommissionAsset,
+		Time:     v.Time,
+		By:       by,
+	}, nil
+}
+
+func bnFuturesTradeToSearchTrade(v *bnFuturesTrades) (*exchange.SearchTradesResponse, error) {
+	quantity, err := decimal.NewFromString(v.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(v.Price)
+	if err != nil {
+		return nil, err
+	}
+	feeCost, err := decimal.NewFromString(v.Commission)
+	if err != nil {
+		return nil, err
+	}
+	realizedPnl, err := decimal.NewFromString(v.RealizedPnl)
+	if err != nil {
+		realizedPnl = decimal.Zero
+	}
+	by := exchange.ByTaker
+	if v.IsMaker {
+		by = exchange.ByMaker
+	}
+	return &exchange.SearchTradesResponse{
+		Symbol:       v.Symbol,
+		ID:           fmt.Sprintf("%d", v.ID),
+		OrderID:      fmt.Sprintf("%d", v.OrderID),
+		Side:         exchange.SideType(v.Side),
+		PositionSide: exchange.PositionSide(v.PositionSide),
+		Price:        price,
+		Volume:       quantity,
+		FeeCost:      feeCost,
+		FeeAsset:     v.CommissionAsset,
+		RealizedPnl:  realizedPnl,
+		Time:         v.Time,
+		By:           by,
+	}, nil
+}
+
+func bnSymbolToSymbol(v *bnSymbolInfo, marketType exchange.MarketType) (exchange.Symbol, error) {
+	status := exchange.SymbolStatusDisabled
+	if v.Status == "TRADING" || v.ContractStatus == "TRADING" {
+		status = exchange.SymbolStatusEnabled
+	}
+	var expTime int64
+	if marketType == exchange.MarketTypeFuturesUSDMargined || marketType == exchange.MarketTypeFuturesCoinMargined {
+		expTime = v.DeliveryDate
+	}
+	symbol := exchange.Symbol{
+		OriginalSymbol: v.Symbol,
+		UnifiedSymbol:  exchange.UnifiedSymbolName(v.BaseAsset, v.QuoteAsset, marketType, expTime),
+		OriginalAsset:  v.BaseAsset,
+		UnifiedAsset:   v.BaseAsset,
+		QuoteAsset:     v.QuoteAsset,
+		Exchange:       exchange.BinanceExchange,
+		MarketType:     marketType,
+		Status:         status,
+		ListTime:       v.OnboardDate,
+		ExpTime:        expTime,
+	}
+	if marketType.IsCoinMargined() {
+		symbol.CtVal = decimal.NewFromInt(v.ContractSize)
+	}
+	var err error
+	for _, f := range v.Filters {
+		switch f.FilterType {
+		case "PRICE_FILTER":
+			if symbol.MinPrice, err = decimal.NewFromString(f.MinPrice); err != nil {
+				return symbol, err
+			}
+			if symbol.MaxPrice, err = decimal.NewFromString(f.MaxPrice); err != nil {
+				return symbol, err
+			}
+			if symbol.TickSize, err = decimal.NewFromString(f.TickSize); err != nil {
+				return symbol, err
+			}
+			symbol.PricePrecision = exchange.StepPrecision(f.TickSize)
+		case "LOT_SIZE":
+			if symbol.MinSize, err = decimal.NewFromString(f.MinQty); err != nil {
+				return symbol, err
+			}
+			if symbol.MaxSize, err = decimal.NewFromString(f.MaxQty); err != nil {
+				return symbol, err
+			}
+			if symbol.StepSize, err = decimal.NewFromString(f.StepSize); err != nil {
+				return symbol, err
+			}
+			symbol.SizePrecision = exchange.StepPrecision(f.StepSize)
+		case "NOTIONAL", "MIN_NOTIONAL":
+			notional := f.MinNotional
+			if notional == "" {
+				notional = f.Notional
+			}
+			if symbol.MinNotional, err = decimal.NewFromString(notional); err != nil {
+				return symbol, err
+			}
+		}
+	}
+	return symbol, nil
+}
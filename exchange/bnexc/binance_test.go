@@ -0,0 +1,74 @@
+package bnexc
+
+import (
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBnFuturesOrderParams(t *testing.T) {
+	req := &exchange.CreateOrderRequest{
+		Symbol:                  exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		Side:                    exchange.SideTypeSell,
+		OrderType:               exchange.OrderTypeLimit,
+		MarketType:              exchange.MarketTypePerpetualUSDMargined,
+		Size:                    decimal.NewFromInt(1),
+		Price:                   decimal.NewFromInt(100),
+		ReduceOnly:              true,
+		SelfTradePreventionMode: exchange.SelfTradePreventionExpireBoth,
+		PriceMatch:              exchange.PriceMatchQueue,
+	}
+	m := toBnFuturesOrderParams(req)
+	assert.Equal(t, "true", m["reduceOnly"])
+	assert.Equal(t, exchange.SelfTradePreventionExpireBoth, m["selfTradePreventionMode"])
+	assert.Equal(t, exchange.PriceMatchQueue, m["priceMatch"])
+	assert.NotContains(t, m, "price")
+
+	// 双向持仓不发送 reduceOnly
+	req.PositionSide = exchange.PositionSideLong
+	m = toBnFuturesOrderParams(req)
+	assert.NotContains(t, m, "reduceOnly")
+
+	req.OrderType = exchange.OrderTypeStopMarket
+	req.StopPrice = decimal.NewFromInt(90)
+	req.ClosePosition = true
+	req.PriceMatch = ""
+	m = toBnFuturesOrderParams(req)
+	assert.Equal(t, "true", m["closePosition"])
+	assert.NotContains(t, m, "quantity")
+	assert.Equal(t, "90", m["stopPrice"])
+}
+
+func TestBnOrderPaths(t *testing.T) {
+	path, err := bnOrderPaths(exchange.MarketTypeMargin, false)
+	assert.Nil(t, err)
+	assert.Equal(t, "/sapi/v1/margin/order", path.order)
+
+	// 统一账户的杠杆和合约走 papi，现货不变
+	path, err = bnOrderPaths(exchange.MarketTypeMargin, true)
+	assert.Nil(t, err)
+	assert.Equal(t, bnPortfolioMarginEndpoint, path.endpoint)
+	assert.Equal(t, "/papi/v1/margin/myTrades", path.trades)
+
+	path, err = bnOrderPaths(exchange.MarketTypePerpetualUSDMargined, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "/papi/v1/um/order", path.order)
+
+	path, err = bnOrderPaths(exchange.MarketTypeSpot, true)
+	assert.Nil(t, err)
+	assert.Equal(t, "/api/v3/order", path.order)
+
+	path, err = bnOrderPaths(exchange.MarketTypePerpetualCoinMargined, false)
+	assert.Nil(t, err)
+	assert.Equal(t, bnDeliveryEndpoint, path.endpoint)
+	assert.Equal(t, "/dapi/v1/userTrades", path.trades)
+}
+
+func TestBnDeliveryPath(t *testing.T) {
+	assert.Equal(t, "/dapi/v1/order", bnDeliveryPath("/fapi/v1/order"))
+	assert.Equal(t, "/dapi/v1/balance", bnDeliveryPath("/fapi/v3/balance"))
+	assert.Equal(t, "/dapi/v1/positionRisk", bnDeliveryPath("/fapi/v2/positionRisk"))
+	assert.Equal(t, "/api/v3/order", bnDeliveryPath("/api/v3/order"))
+}
@@ -0,0 +1,101 @@
+package bnexc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/shopspring/decimal"
+)
+
+// setFuturesEndpoint 设置合约接口地址；币本位合约使用 dapi，路径与 fapi 一一对应，
+// 统一账户的币本位合约使用 papi 的 cm 接口
+func (b *binance) setFuturesEndpoint(r *bnhttp.Request, marketType exchange.MarketType) {
+	switch {
+	case strings.HasPrefix(r.Endpoint, "/papi/"):
+		if marketType.IsCoinMargined() {
+			r.Endpoint = strings.Replace(r.Endpoint, "/papi/v1/um/", "/papi/v1/cm/", 1)
+		}
+		b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	case marketType.IsCoinMargined():
+		r.Endpoint = bnDeliveryPath(r.Endpoint)
+		b.client.SetApiEndpoint(bnDeliveryEndpoint)
+	default:
+		b.client.SetApiEndpoint(bnFuturesEndpoint)
+	}
+}
+
+// bnDeliveryPath fapi 路径转换为 dapi 路径，dapi 只有 v1 版本，如 /fapi/v3/balance 对应 /dapi/v1/balance
+func bnDeliveryPath(path string) string {
+	rest, ok := strings.CutPrefix(path, "/fapi/")
+	if !ok {
+		return path
+	}
+	_, name, ok := strings.Cut(rest, "/")
+	if !ok {
+		return path
+	}
+	return "/dapi/v1/" + name
+}
+
+// ConvertContractCoin U 本位合约数量以币为单位，原样返回；币本位合约按标记价格换算张数和币的数量
+// typ：1-币转张 2-张转币; opTyp: open（舍位），close（四舍五入）
+func (b *binance) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string, opTyp string) (string, error) {
+	size, err := decimal.NewFromString(sz)
+	if err != nil {
+		return "", err
+	}
+	if !symbol.MarketType.IsCoinMargined() {
+		return size.String(), nil
+	}
+	price, err := b.coinMarkPrice(context.Background(), symbol.OriginalSymbol)
+	if err != nil {
+		return "", err
+	}
+	switch typ {
+	case "1":
+		contracts, err := exchange.CoinToContract(symbol, size, price)
+		if err != nil {
+			return "", err
+		}
+		if opTyp == "close" {
+			return contracts.Round(symbol.SizePrecision).String(), nil
+		}
+		return contracts.RoundFloor(symbol.SizePrecision).String(), nil
+	case "2":
+		coins, err := exchange.ContractToCoin(symbol, size, price)
+		if err != nil {
+			return "", err
+		}
+		return coins.String(), nil
+	}
+	return "", fmt.Errorf("invalid type: %v", typ)
+}
+
+// coinMarkPrice 币本位合约标记价格
+func (b *binance) coinMarkPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	r := &bnhttp.Request{
+		Method:   http.MethodGet,
+		Endpoint: "/dapi/v1/premiumIndex",
+		SecType:  bnhttp.SecTypeNone,
+	}
+	b.client.SetApiEndpoint(bnDeliveryEndpoint)
+	r = r.SetParams(bnhttp.Params{"symbol": symbol})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	// dapi 按交易对查询也返回数组
+	var res []*bnPremiumIndex
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if len(res) == 0 {
+		return decimal.Zero, exchange.ErrInvalidSymbol
+	}
+	return decimal.NewFromString(res[0].MarkPrice)
+}
@@ -25,6 +25,21 @@ type bnFuturesBalance struct {
 	MaxWithdrawAmount  string `json:"maxWithdrawAmount"`
 }
 
+type bnPmBalance struct {
+	Asset               string `json:"asset"`
+	TotalWalletBalance  string `json:"totalWalletBalance"`
+	CrossMarginAsset    string `json:"crossMarginAsset"`
+	CrossMarginBorrowed string `json:"crossMarginBorrowed"`
+	CrossMarginFree     string `json:"crossMarginFree"`
+	CrossMarginInterest string `json:"crossMarginInterest"`
+	CrossMarginLocked   string `json:"crossMarginLocked"`
+	UmWalletBalance     string `json:"umWalletBalance"`
+	UmUnrealizedPNL     string `json:"umUnrealizedPNL"`
+	CmWalletBalance     string `json:"cmWalletBalance"`
+	CmUnrealizedPNL     string `json:"cmUnrealizedPNL"`
+	UpdateTime          int64  `json:"updateTime"`
+}
+
 type bnSpotBalance struct {
 	Asset  string `json:"asset"`
 	Free   string `json:"free"`
@@ -251,6 +266,13 @@ type bnSpotCreateOrderResponse struct {
 	MarginBuyBorrowAsset  string        `json:"marginBuyBorrowAsset"`
 }
 
+type bnSpotCancelReplaceResponse struct {
+	CancelResult     string                     `json:"cancelResult"`
+	NewOrderResult   string                     `json:"newOrderResult"`
+	CancelResponse   *bnCancelOrderResponse     `json:"cancelResponse"`
+	NewOrderResponse *bnSpotCreateOrderResponse `json:"newOrderResponse"`
+}
+
 type bnMarginCreateOrderResponse struct {
 	Symbol                   string `json:"symbol"`
 	OrderID                  int64  `json:"orderId"`
@@ -306,6 +328,7 @@ type bnFuturesSearchOrderResponse struct {
 	Price             string `json:"price"`
 	FilledQuoteVolume string `json:"cumQuote"`
 	FilledVolume      string `json:"executedQty"`
+	CumBase           string `json:"cumBase"` // 币本位合约成交的币数量，没有 cumQuote
 	Side              string `json:"side"`
 	PositionSide      string `json:"positionSide"`
 	TimeInForce       string `json:"timeInForce"`
@@ -338,6 +361,9 @@ type bnFuturesTrades struct {
 	Time            int64  `json:"time"`
 	IsBuyer         bool   `json:"buyer"`
 	IsMaker         bool   `json:"maker"`
+	Side            string `json:"side"`
+	PositionSide    string `json:"positionSide"`
+	RealizedPnl     string `json:"realizedPnl"`
 }
 
 type bnPremiumIndex struct {
@@ -351,6 +377,13 @@ type bnPremiumIndex struct {
 	Time                 int64  `json:"time"`
 }
 
+type bnFundingRate struct {
+	Symbol      string `json:"symbol"`
+	FundingRate string `json:"fundingRate"`
+	FundingTime int64  `json:"fundingTime"`
+	MarkPrice   string `json:"markPrice"`
+}
+
 type bnMarginInterestRate struct {
 	Asset                  string `json:"asset"`
 	NextHourlyInterestRate string `json:"nextHourlyInterestRate"`
@@ -367,6 +400,16 @@ type bnDepthResponse struct {
 	Ask [][]string `json:"asks"`
 }
 
+// bnBatchOrderResponse 批量接口中单个订单的返回，失败时只有 code 和 msg
+type bnBatchOrderResponse struct {
+	Code          int64  `json:"code"`
+	Msg           string `json:"msg"`
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Status        string `json:"status"`
+}
+
 type bnCancelOrderResponse struct {
 	Symbol  string `json:"symbol"`
 	OrderID int64  `json:"orderId"`
@@ -415,3 +458,60 @@ type bnFuturesLeverageResponse struct {
 	Leverage   int64  `json:"leverage"`
 	MarginType string `json:"marginType"`
 }
+
+type bnExchangeInfo struct {
+	Symbols []*bnSymbolInfo `json:"symbols"`
+}
+
+type bnSymbolInfo struct {
+	Symbol                 string            `json:"symbol"`
+	Status                 string            `json:"status"`
+	ContractStatus         string            `json:"contractStatus"` // 币本位合约状态，没有 status
+	ContractSize           int64             `json:"contractSize"`   // 币本位合约每张的美元价值
+	BaseAsset              string            `json:"baseAsset"`
+	QuoteAsset             string            `json:"quoteAsset"`
+	ContractType           string            `json:"contractType"` // 合约类型 PERPETUAL, CURRENT_QUARTER, NEXT_QUARTER
+	DeliveryDate           int64             `json:"deliveryDate"`
+	OnboardDate            int64             `json:"onboardDate"`
+	IsMarginTradingAllowed bool              `json:"isMarginTradingAllowed"`
+	Filters                []*bnSymbolFilter `json:"filters"`
+}
+
+type bnSymbolFilter struct {
+	FilterType  string `json:"filterType"`
+	MinPrice    string `json:"minPrice"`
+	MaxPrice    string `json:"maxPrice"`
+	TickSize    string `json:"tickSize"`
+	MinQty      string `json:"minQty"`
+	MaxQty      string `json:"maxQty"`
+	StepSize    string `json:"stepSize"`
+	MinNotional string `json:"minNotional"` // 现货
+	Notional    string `json:"notional"`    // 合约
+}
+
+type bnOrderListResponse struct {
+	OrderListID       int64  `json:"orderListId"`
+	ContingencyType   string `json:"contingencyType"`
+	ListStatusType    string `json:"listStatusType"`
+	ListOrderStatus   string `json:"listOrderStatus"` // EXECUTING, ALL_DONE, REJECT
+	ListClientOrderID string `json:"listClientOrderId"`
+	TransactionTime   int64  `json:"transactionTime"`
+	Symbol            string `json:"symbol"`
+	Orders            []struct {
+		Symbol        string `json:"symbol"`
+		OrderID       int64  `json:"orderId"`
+		ClientOrderID string `json:"clientOrderId"`
+	} `json:"orders"`
+	OrderReports []*bnSpotSearchOrderReponse `json:"orderReports"` // 只有下单时返回
+}
+
+type bnFuturesIncome struct {
+	Symbol     string `json:"symbol"`
+	IncomeType string `json:"incomeType"`
+	Income     string `json:"income"`
+	Asset      string `json:"asset"`
+	Info       string `json:"info"`
+	Time       int64  `json:"time"`
+	TranID     int64  `json:"tranId"`
+	TradeID    string `json:"tradeId"`
+}
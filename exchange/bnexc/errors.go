@@ -0,0 +1,113 @@
+package bnexc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+)
+
+// bnErrors 币安错误码与统一错误类型的对应关系
+// https://developers.binance.com/docs/binance-spot-api-docs/errors
+var bnErrors = map[int64]error{
+	-1000: exchange.ErrServiceUnavailable,
+	-1001: exchange.ErrServiceUnavailable,
+	-1003: exchange.ErrRateLimitExceeded,
+	-1006: exchange.ErrExecutionStatusUnknown,
+	-1007: exchange.ErrExecutionStatusUnknown,
+	-1008: exchange.ErrServiceUnavailable,
+	-1015: exchange.ErrCreateOrderLimitExceeded,
+	-1021: exchange.ErrInvalidTimestamp,
+	-1022: exchange.ErrAuthenticationFailed,
+	-1121: exchange.ErrInvalidSymbol,
+	-1125: exchange.ErrListenKeyExpired,
+	-2011: exchange.ErrOrderNotFound,
+	-2013: exchange.ErrOrderNotFound,
+	-2014: exchange.ErrAuthenticationFailed,
+	-2015: exchange.ErrAuthenticationFailed,
+	-2018: exchange.ErrOrderNotEnoughBalance,
+	-2019: exchange.ErrOrderNotEnoughMargin,
+	-2021: exchange.ErrOrderRejected,
+	-2022: exchange.ErrReduceOnlyRejected,
+	-4015: exchange.ErrInvalidParameter,
+	-4116: exchange.ErrOrderAlreadyExists,
+	-4164: exchange.ErrOrderNotionalTooSmall,
+	-5022: exchange.ErrPostOnlyRejected,
+}
+
+const (
+	// bnNoNeedToChangeMarginType 保证金模式未变化
+	bnNoNeedToChangeMarginType = "-4046"
+	// bnNoNeedToChangePositionSide 持仓模式未变化
+	bnNoNeedToChangePositionSide = "-4059"
+)
+
+// bnRetryable 可以重试的统一错误类型
+var bnRetryable = map[error]bool{
+	exchange.ErrServiceUnavailable:       true,
+	exchange.ErrRateLimitExceeded:        true,
+	exchange.ErrCreateOrderLimitExceeded: true,
+	exchange.ErrInvalidTimestamp:         true,
+}
+
+// callAPI 调用接口并将币安错误转换为 *exchange.Error
+func (b *binance) callAPI(ctx context.Context, r *bnhttp.Request) ([]byte, error) {
+	data, err := b.client.CallAPI(ctx, r)
+	if err != nil {
+		return nil, bnError(err)
+	}
+	return data, nil
+}
+
+// bnError 将 *bnhttp.APIError 转换为 *exchange.Error，其他错误原样返回
+func bnError(err error) error {
+	var apiErr *bnhttp.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	target := bnErrorType(apiErr.Code, apiErr.Message)
+	return &exchange.Error{
+		Exchange:  exchange.BinanceExchange,
+		Code:      strconv.FormatInt(apiErr.Code, 10),
+		Message:   apiErr.Message,
+		Retryable: bnRetryable[target],
+		Err:       target,
+		Cause:     apiErr,
+	}
+}
+
+func bnErrorType(code int64, msg string) error {
+	if target, ok := bnErrors[code]; ok {
+		return target
+	}
+	switch {
+	case code == -1013:
+		// 过滤器校验失败，如 Filter failure: LOT_SIZE
+		switch {
+		case strings.Contains(msg, "NOTIONAL"):
+			return exchange.ErrOrderNotionalTooSmall
+		case strings.Contains(msg, "LOT_SIZE"):
+			return exchange.ErrInvalidOrderSize
+		case strings.Contains(msg, "PRICE_FILTER"):
+			return exchange.ErrInvalidOrderPrice
+		}
+		return exchange.ErrInvalidParameter
+	case code == -2010:
+		// 现货下单被拒绝，具体原因只在 msg 中
+		switch {
+		case strings.Contains(msg, "insufficient balance"):
+			return exchange.ErrOrderNotEnoughBalance
+		case strings.Contains(msg, "immediately match"):
+			return exchange.ErrPostOnlyRejected
+		case strings.Contains(msg, "Duplicate order"):
+			return exchange.ErrOrderAlreadyExists
+		}
+		return exchange.ErrOrderRejected
+	case code <= -1100 && code >= -1199:
+		return exchange.ErrInvalidParameter
+	}
+	return exchange.ErrUnknown
+}
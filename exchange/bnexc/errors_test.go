@@ -0,0 +1,43 @@
+package bnexc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBnError(t *testing.T) {
+	err := bnError(&bnhttp.APIError{Code: -2019, Message: "Margin is insufficient."})
+	assert.True(t, errors.Is(err, exchange.ErrOrderNotEnoughMargin))
+	assert.False(t, exchange.IsRetryable(err))
+	assert.Equal(t, "-2019", exchange.ErrorCode(err))
+
+	// 原始错误仍可通过 errors.As 获取
+	var apiErr *bnhttp.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, int64(-2019), apiErr.Code)
+
+	err = bnError(&bnhttp.APIError{Code: -1003, Message: "Too many requests."})
+	assert.True(t, errors.Is(err, exchange.ErrRateLimitExceeded))
+	assert.True(t, exchange.IsRetryable(err))
+
+	// 执行状态未知时订单可能已被接受，不能重试
+	err = bnError(&bnhttp.APIError{Code: -1007, Message: "Timeout waiting for response from backend server. Send status unknown; execution status unknown."})
+	assert.True(t, errors.Is(err, exchange.ErrExecutionStatusUnknown))
+	assert.False(t, exchange.IsRetryable(err))
+
+	err = bnError(&bnhttp.APIError{Code: -1013, Message: "Filter failure: LOT_SIZE"})
+	assert.True(t, errors.Is(err, exchange.ErrInvalidOrderSize))
+
+	err = bnError(&bnhttp.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."})
+	assert.True(t, errors.Is(err, exchange.ErrOrderNotEnoughBalance))
+
+	err = bnError(&bnhttp.APIError{Code: -9999, Message: "unknown"})
+	assert.True(t, errors.Is(err, exchange.ErrUnknown))
+
+	// 非接口错误原样返回
+	assert.Equal(t, exchange.ErrOrderNotFound, bnError(exchange.ErrOrderNotFound))
+}
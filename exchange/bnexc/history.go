@@ -0,0 +1,597 @@
+package bnexc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	bnSpotHistoryWindow    = int64(24 * time.Hour / time.Millisecond)     // 现货/杠杆历史查询单次最大时间跨度
+	bnFuturesHistoryWindow = int64(7 * 24 * time.Hour / time.Millisecond) // 合约历史查询单次最大时间跨度
+	bnHistoryPageLimit     = 1000
+	bnMarginOrdersLimit    = 500 // 杠杆历史订单单页上限
+)
+
+// bnHistoryPager 按时间窗口分页拉取历史数据，数据按时间升序返回；
+// 窗口内数据达到单页上限时以最后一条的时间作为下一页起点，并按 id 去重
+type bnHistoryPager[T any] struct {
+	cursor int64
+	end    int64
+	window int64
+	limit  int
+	lastID int64
+	fetch  func(ctx context.Context, start, end int64) ([]T, error)
+	key    func(T) (id int64, ts int64)
+}
+
+func newBnHistoryPager[T any](start, end, window int64, limit int,
+	fetch func(ctx context.Context, start, end int64) ([]T, error), key func(T) (int64, int64)) *bnHistoryPager[T] {
+	if end <= 0 {
+		end = time.Now().UnixMilli()
+	}
+	if start <= 0 {
+		start = end - window + 1
+	}
+	return &bnHistoryPager[T]{
+		cursor: start,
+		end:    end,
+		window: window,
+		limit:  limit,
+		lastID: -1,
+		fetch:  fetch,
+		key:    key,
+	}
+}
+
+func (p *bnHistoryPager[T]) next(ctx context.Context) ([]T, bool, error) {
+	if p.cursor > p.end {
+		return nil, true, nil
+	}
+	windowEnd := min(p.cursor+p.window-1, p.end)
+	items, err := p.fetch(ctx, p.cursor, windowEnd)
+	if err != nil {
+		return nil, false, err
+	}
+	result := make([]T, 0, len(items))
+	for _, v := range items {
+		id, _ := p.key(v)
+		if id <= p.lastID {
+			continue
+		}
+		result = append(result, v)
+	}
+	for _, v := range result {
+		if id, _ := p.key(v); id > p.lastID {
+			p.lastID = id
+		}
+	}
+	if len(items) >= p.limit {
+		_, ts := p.key(items[len(items)-1])
+		if len(result) == 0 && ts <= p.cursor {
+			// 同一毫秒内数据超过单页上限，跳过该毫秒避免死循环
+			ts = p.cursor + 1
+		}
+		p.cursor = ts
+	} else {
+		p.cursor = windowEnd + 1
+	}
+	return result, p.cursor > p.end, nil
+}
+
+// OrderHistory 币安历史订单接口必须指定交易对，现货/杠杆单次查询跨度 24 小时，合约 7 天
+func (b *binance) OrderHistory(req *exchange.GetOrderHistoryRequest) exchange.Iterator[*exchange.SearchOrderResponse] {
+	window, limit := bnFuturesHistoryWindow, bnHistoryPageLimit
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		window = bnSpotHistoryWindow
+	case exchange.MarketTypeMargin:
+		window, limit = bnSpotHistoryWindow, bnMarginOrdersLimit
+	}
+	pager := newBnHistoryPager(req.StartTime, req.EndTime, window, limit,
+		func(ctx context.Context, start, end int64) ([]*exchange.SearchOrderResponse, error) {
+			return b.fetchOrderHistory(ctx, req, start, end, limit)
+		},
+		func(o *exchange.SearchOrderResponse) (int64, int64) {
+			id, _ := strconv.ParseInt(o.OrderID, 10, 64)
+			return id, o.CreatedTime
+		})
+	return exchange.NewIterator(pager.next)
+}
+
+// TradeHistory 币安成交记录接口必须指定交易对，现货/杠杆单次查询跨度 24 小时，合约 7 天
+func (b *binance) TradeHistory(req *exchange.GetTradeHistoryRequest) exchange.Iterator[*exchange.SearchTradesResponse] {
+	window := bnFuturesHistoryWindow
+	if req.MarketType == exchange.MarketTypeSpot || req.MarketType == exchange.MarketTypeMargin {
+		window = bnSpotHistoryWindow
+	}
+	pager := newBnHistoryPager(req.StartTime, req.EndTime, window, bnHistoryPageLimit,
+		func(ctx context.Context, start, end int64) ([]*exchange.SearchTradesResponse, error) {
+			return b.fetchTradeHistory(ctx, req, start, end)
+		},
+		func(t *exchange.SearchTradesResponse) (int64, int64) {
+			id, _ := strconv.ParseInt(t.ID, 10, 64)
+			return id, t.Time
+		})
+	return exchange.NewIterator(pager.next)
+}
+
+// ClosedPositions 币安没有历史仓位接口，由合约成交记录还原仓位；
+// 只统计在查询时间范围内开仓并平仓的仓位，资金费不计入
+func (b *binance) ClosedPositions(req *exchange.GetClosedPositionsRequest) exchange.Iterator[*exchange.ClosedPosition] {
+	if req.MarketType != exchange.MarketTypeFuturesUSDMargined && req.MarketType != exchange.MarketTypePerpetualUSDMargined {
+		return exchange.NewIterator(func(ctx context.Context) ([]*exchange.ClosedPosition, bool, error) {
+			return nil, false, exchange.ErrInstrumentTypeNotSupported
+		})
+	}
+	trades := b.TradeHistory(&exchange.GetTradeHistoryRequest{
+		APIKey:           req.APIKey,
+		SecretKey:        req.SecretKey,
+		Symbol:           req.Symbol,
+		MarketType:       req.MarketType,
+		StartTime:        req.StartTime,
+		EndTime:          req.EndTime,
+		IsUnifiedAccount: req.IsUnifiedAccount,
+	})
+	tracker := newBnPositionTracker(req.MarketType)
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.ClosedPosition, bool, error) {
+		items, err := trades.Next(ctx)
+		if errors.Is(err, exchange.ErrIteratorDone) {
+			return nil, true, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		result := make([]*exchange.ClosedPosition, 0)
+		for _, v := range items {
+			if p := tracker.add(v); p != nil {
+				result = append(result, p)
+			}
+		}
+		return result, false, nil
+	})
+}
+
+// bnIncomeTypes 币安合约资金流水类型与账单类型的对应关系，未列出的为 OTHER
+var bnIncomeTypes = map[string]exchange.LedgerType{
+	"COMMISSION":                  exchange.LedgerTypeFee,
+	"FUNDING_FEE":                 exchange.LedgerTypeFundingFee,
+	"REALIZED_PNL":                exchange.LedgerTypeRealizedPnl,
+	"TRANSFER":                    exchange.LedgerTypeTransfer,
+	"INTERNAL_TRANSFER":           exchange.LedgerTypeTransfer,
+	"CROSS_COLLATERAL_TRANSFER":   exchange.LedgerTypeTransfer,
+	"STRATEGY_UMFUTURES_TRANSFER": exchange.LedgerTypeTransfer,
+	"INSURANCE_CLEAR":             exchange.LedgerTypeLiquidation,
+}
+
+// Ledger 查询合约资金流水，单次查询跨度 7 天，窗口内按页码翻页
+func (b *binance) Ledger(req *exchange.GetLedgerRequest) exchange.Iterator[*exchange.LedgerEntry] {
+	if !req.MarketType.IsContract() {
+		return exchange.NewIterator(func(ctx context.Context) ([]*exchange.LedgerEntry, bool, error) {
+			return nil, false, exchange.ErrInstrumentTypeNotSupported
+		})
+	}
+	// 只有一种流水类型对应时由接口过滤，其余在本地过滤
+	incomeType := ""
+	for k, v := range bnIncomeTypes {
+		if v == req.Type && v != exchange.LedgerTypeTransfer {
+			incomeType = k
+		}
+	}
+	end := req.EndTime
+	if end <= 0 {
+		end = time.Now().UnixMilli()
+	}
+	cursor := req.StartTime
+	if cursor <= 0 {
+		cursor = end - bnFuturesHistoryWindow + 1
+	}
+	page := 1
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.LedgerEntry, bool, error) {
+		if cursor > end {
+			return nil, true, nil
+		}
+		windowEnd := min(cursor+bnFuturesHistoryWindow-1, end)
+		items, err := b.fetchIncome(ctx, req, incomeType, cursor, windowEnd, page)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(items) >= bnHistoryPageLimit {
+			page++
+		} else {
+			cursor, page = windowEnd+1, 1
+		}
+		result := make([]*exchange.LedgerEntry, 0, len(items))
+		for _, v := range items {
+			entry, err := bnIncomeToLedgerEntry(v, req.MarketType)
+			if err != nil {
+				return nil, false, err
+			}
+			if req.Type == "" || entry.Type == req.Type {
+				result = append(result, entry)
+			}
+		}
+		return result, cursor > end, nil
+	})
+}
+
+func (b *binance) fetchIncome(ctx context.Context, req *exchange.GetLedgerRequest, incomeType string, start, end int64, page int) ([]*bnFuturesIncome, error) {
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodGet,
+		Endpoint:  "/fapi/v1/income",
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	if req.IsUnifiedAccount {
+		r.Endpoint = "/papi/v1/um/income"
+	}
+	b.setFuturesEndpoint(r, req.MarketType)
+	params := bnhttp.Params{
+		"startTime": start,
+		"endTime":   end,
+		"page":      page,
+		"limit":     bnHistoryPageLimit,
+	}
+	if req.Symbol != "" {
+		params["symbol"] = req.Symbol
+	}
+	if incomeType != "" {
+		params["incomeType"] = incomeType
+	}
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var res []*bnFuturesIncome
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func bnIncomeToLedgerEntry(v *bnFuturesIncome, marketType exchange.MarketType) (*exchange.LedgerEntry, error) {
+	amount, err := decimal.NewFromString(v.Income)
+	if err != nil {
+		return nil, err
+	}
+	typ, ok := bnIncomeTypes[v.IncomeType]
+	if !ok {
+		typ = exchange.LedgerTypeOther
+	}
+	return &exchange.LedgerEntry{
+		ID:         strconv.FormatInt(v.TranID, 10),
+		Symbol:     v.Symbol,
+		MarketType: marketType,
+		Type:       typ,
+		Asset:      v.Asset,
+		Amount:     amount,
+		TradeID:    v.TradeID,
+		Info:       v.IncomeType,
+		Time:       v.Time,
+	}, nil
+}
+
+func (b *binance) fetchOrderHistory(ctx context.Context, req *exchange.GetOrderHistoryRequest, start, end int64, limit int) ([]*exchange.SearchOrderResponse, error) {
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodGet,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	isFutures := false
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r.Endpoint = "/api/v3/allOrders"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeMargin:
+		r.Endpoint = "/sapi/v1/margin/allOrders"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		isFutures = true
+		r.Endpoint = "/fapi/v1/allOrders"
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/um/allOrders"
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+	default:
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	r = r.SetParams(bnhttp.Params{
+		"symbol":    req.Symbol,
+		"startTime": start,
+		"endTime":   end,
+		"limit":     limit,
+	})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*exchange.SearchOrderResponse, 0)
+	if isFutures {
+		var res []*bnFuturesSearchOrderResponse
+		err = bnhttp.Json.Unmarshal(data, &res)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res {
+			order, err := bnFuturesOrderToSearchOrder(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, order)
+		}
+		return result, nil
+	}
+
+	var res []*bnSpotSearchOrderReponse
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range res {
+		order, err := bnSpotOrderToSearchOrder(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+func (b *binance) fetchTradeHistory(ctx context.Context, req *exchange.GetTradeHistoryRequest, start, end int64) ([]*exchange.SearchTradesResponse, error) {
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodGet,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	isFutures := false
+	switch req.MarketType {
+	case exchange.MarketTypeSpot:
+		r.Endpoint = "/api/v3/myTrades"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeMargin:
+		r.Endpoint = "/sapi/v1/margin/myTrades"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		isFutures = true
+		r.Endpoint = "/fapi/v1/userTrades"
+		if req.IsUnifiedAccount {
+			r.Endpoint = "/papi/v1/um/userTrades"
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+	default:
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	r = r.SetParams(bnhttp.Params{
+		"symbol":    req.Symbol,
+		"startTime": start,
+		"endTime":   end,
+		"limit":     bnHistoryPageLimit,
+	})
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*exchange.SearchTradesResponse, 0)
+	if isFutures {
+		var res []*bnFuturesTrades
+		err = bnhttp.Json.Unmarshal(data, &res)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range res {
+			trade, err := bnFuturesTradeToSearchTrade(v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, trade)
+		}
+		return result, nil
+	}
+
+	var res []*bnSpotTrades
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range res {
+		trade, err := bnSpotTradeToSearchTrade(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, trade)
+	}
+	return result, nil
+}
+
+// bnPositionTracker 按成交记录累计仓位，仓位归零时生成已平仓记录
+type bnPositionTracker struct {
+	marketType exchange.MarketType
+	positions  map[string]*bnTrackedPosition // symbol:positionSide
+}
+
+type bnTrackedPosition struct {
+	size       decimal.Decimal // 单向持仓模式下为带符号数量
+	openSize   decimal.Decimal
+	openQuote  decimal.Decimal
+	closeSize  decimal.Decimal
+	closeQuote decimal.Decimal
+	pnl        decimal.Decimal
+	fee        decimal.Decimal
+	openTime   int64
+}
+
+func newBnPositionTracker(marketType exchange.MarketType) *bnPositionTracker {
+	return &bnPositionTracker{
+		marketType: marketType,
+		positions:  make(map[string]*bnTrackedPosition),
+	}
+}
+
+func (t *bnPositionTracker) add(trade *exchange.SearchTradesResponse) *exchange.ClosedPosition {
+	key := trade.Symbol + ":" + string(trade.PositionSide)
+	pos, ok := t.positions[key]
+	if !ok {
+		pos = &bnTrackedPosition{}
+		t.positions[key] = pos
+	}
+
+	// 统一转换为带符号的成交数量，多仓为正、空仓为负
+	delta := trade.Volume
+	if trade.Side == exchange.SideTypeSell {
+		delta = delta.Neg()
+	}
+	if trade.PositionSide == exchange.PositionSideShort && pos.size.IsZero() && delta.IsPositive() {
+		// 空仓在范围外开仓，无法还原
+		return nil
+	}
+	if trade.PositionSide == exchange.PositionSideLong && pos.size.IsZero() && delta.IsNegative() {
+		return nil
+	}
+
+	if pos.size.IsZero() || pos.size.Sign() == delta.Sign() {
+		if pos.size.IsZero() {
+			*pos = bnTrackedPosition{openTime: trade.Time}
+		}
+		pos.size = pos.size.Add(delta)
+		pos.openSize = pos.openSize.Add(trade.Volume)
+		pos.openQuote = pos.openQuote.Add(trade.Volume.Mul(trade.Price))
+		pos.fee = pos.fee.Add(trade.FeeCost)
+		return nil
+	}
+
+	closeSize := decimal.Min(delta.Abs(), pos.size.Abs())
+	side := exchange.PositionSideLong
+	if pos.size.IsNegative() {
+		side = exchange.PositionSideShort
+	}
+	// 按平仓比例分摊手续费，剩余部分为反向开仓
+	fee := trade.FeeCost.Mul(closeSize).Div(trade.Volume)
+	pos.closeSize = pos.closeSize.Add(closeSize)
+	pos.closeQuote = pos.closeQuote.Add(closeSize.Mul(trade.Price))
+	pos.pnl = pos.pnl.Add(trade.RealizedPnl)
+	pos.fee = pos.fee.Add(fee)
+	pos.size = pos.size.Add(withSign(closeSize, delta))
+	if !pos.size.IsZero() {
+		return nil
+	}
+
+	closed := &exchange.ClosedPosition{
+		Symbol:        trade.Symbol,
+		MarketType:    t.marketType,
+		PositionSide:  side,
+		OpenAvgPrice:  pos.openQuote.Div(pos.openSize),
+		CloseAvgPrice: pos.closeQuote.Div(pos.closeSize),
+		Size:          pos.closeSize,
+		Pnl:           pos.pnl,
+		Fee:           pos.fee,
+		FundingFee:    decimal.Zero,
+		RealizedPnl:   pos.pnl.Sub(pos.fee),
+		OpenTime:      pos.openTime,
+		CloseTime:     trade.Time,
+	}
+	*pos = bnTrackedPosition{}
+	if remain := delta.Abs().Sub(closeSize); remain.IsPositive() {
+		pos.openTime = trade.Time
+		pos.size = withSign(remain, delta)
+		pos.openSize = remain
+		pos.openQuote = remain.Mul(trade.Price)
+		pos.fee = trade.FeeCost.Sub(fee)
+	}
+	return closed
+}
+
+// withSign 返回与 ref 符号相同的 v
+func withSign(v, ref decimal.Decimal) decimal.Decimal {
+	if ref.IsNegative() {
+		return v.Neg()
+	}
+	return v
+}
+
+// FundingRateHistory 查询合约历史资金费率，数据按结算时间升序返回
+func (b *binance) FundingRateHistory(req *exchange.GetFundingRateHistoryRequest) exchange.Iterator[*exchange.FundingRate] {
+	if !req.MarketType.IsContract() {
+		return exchange.NewIterator(func(ctx context.Context) ([]*exchange.FundingRate, bool, error) {
+			return nil, false, exchange.ErrInstrumentTypeNotSupported
+		})
+	}
+	end := req.EndTime
+	if end <= 0 {
+		end = time.Now().UnixMilli()
+	}
+	cursor := req.StartTime
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.FundingRate, bool, error) {
+		r := &bnhttp.Request{
+			Method:   http.MethodGet,
+			Endpoint: "/fapi/v1/fundingRate",
+			SecType:  bnhttp.SecTypeNone,
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+		params := bnhttp.Params{
+			"endTime": end,
+			"limit":   bnHistoryPageLimit,
+		}
+		if req.Symbol != "" {
+			params["symbol"] = req.Symbol
+		}
+		if cursor > 0 {
+			params["startTime"] = cursor
+		}
+		r = r.SetParams(params)
+		data, err := b.callAPI(ctx, r)
+		if err != nil {
+			return nil, false, err
+		}
+		var items []*bnFundingRate
+		if err := bnhttp.Json.Unmarshal(data, &items); err != nil {
+			return nil, false, err
+		}
+		result := make([]*exchange.FundingRate, 0, len(items))
+		for _, v := range items {
+			rate, err := bnToFundingRate(v)
+			if err != nil {
+				return nil, false, err
+			}
+			result = append(result, rate)
+		}
+		if len(items) < bnHistoryPageLimit {
+			return result, true, nil
+		}
+		cursor = items[len(items)-1].FundingTime + 1
+		return result, cursor > end, nil
+	})
+}
+
+func bnToFundingRate(v *bnFundingRate) (*exchange.FundingRate, error) {
+	rate, err := decimal.NewFromString(v.FundingRate)
+	if err != nil {
+		return nil, err
+	}
+	markPrice := decimal.Zero
+	if v.MarkPrice != "" {
+		if markPrice, err = decimal.NewFromString(v.MarkPrice); err != nil {
+			return nil, err
+		}
+	}
+	return &exchange.FundingRate{
+		Symbol:      v.Symbol,
+		FundingRate: rate,
+		MarkPrice:   markPrice,
+		FundingTime: v.FundingTime,
+	}, nil
+}
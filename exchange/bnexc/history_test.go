@@ -0,0 +1,65 @@
+package bnexc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryPager(t *testing.T) {
+	type item struct{ id, ts int64 }
+	data := []item{{1, 10}, {2, 11}, {3, 11}, {4, 25}, {5, 40}}
+	pager := newBnHistoryPager(10, 40, 20, 2,
+		func(ctx context.Context, start, end int64) ([]item, error) {
+			result := make([]item, 0)
+			for _, v := range data {
+				if v.ts >= start && v.ts <= end && len(result) < 2 {
+					result = append(result, v)
+				}
+			}
+			return result, nil
+		},
+		func(v item) (int64, int64) { return v.id, v.ts })
+
+	items, err := exchange.Collect(context.Background(), exchange.NewIterator(pager.next))
+	assert.NoError(t, err)
+	assert.Len(t, items, 5)
+	for i, v := range items {
+		assert.Equal(t, int64(i+1), v.id)
+	}
+}
+
+func TestPositionTracker(t *testing.T) {
+	tracker := newBnPositionTracker(exchange.MarketTypePerpetualUSDMargined)
+	trade := func(side exchange.SideType, qty, price, pnl int64, ts int64) *exchange.SearchTradesResponse {
+		return &exchange.SearchTradesResponse{
+			Symbol:       "BTCUSDT",
+			Side:         side,
+			PositionSide: "BOTH",
+			Price:        decimal.NewFromInt(price),
+			Volume:       decimal.NewFromInt(qty),
+			FeeCost:      decimal.NewFromInt(qty),
+			RealizedPnl:  decimal.NewFromInt(pnl),
+			Time:         ts,
+		}
+	}
+
+	assert.Nil(t, tracker.add(trade(exchange.SideTypeBuy, 2, 100, 0, 1)))
+	assert.Nil(t, tracker.add(trade(exchange.SideTypeSell, 1, 110, 10, 2)))
+	// 卖出 3 个，平掉剩余 1 个多仓并反手开 2 个空仓
+	closed := tracker.add(trade(exchange.SideTypeSell, 3, 120, 20, 3))
+	assert.NotNil(t, closed)
+	assert.Equal(t, exchange.PositionSideLong, closed.PositionSide)
+	assert.True(t, decimal.NewFromInt(2).Equal(closed.Size))
+	assert.True(t, decimal.NewFromInt(115).Equal(closed.CloseAvgPrice))
+	assert.True(t, decimal.NewFromInt(26).Equal(closed.RealizedPnl))
+
+	closed = tracker.add(trade(exchange.SideTypeBuy, 2, 110, 20, 4))
+	assert.NotNil(t, closed)
+	assert.Equal(t, exchange.PositionSideShort, closed.PositionSide)
+	assert.True(t, decimal.NewFromInt(120).Equal(closed.OpenAvgPrice))
+	assert.Equal(t, int64(3), closed.OpenTime)
+}
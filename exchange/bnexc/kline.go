@@ -0,0 +1,194 @@
+package bnexc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	bnSpotKlineLimit    = 1000 // 现货K线单页上限
+	bnFuturesKlineLimit = 1500 // 合约K线单页上限
+)
+
+// bnKlineEndpoints 合约各类型K线接口，指数价格K线按 pair 查询
+var bnKlineEndpoints = map[exchange.KlineType]string{
+	exchange.KlineTypeTrade:        "/fapi/v1/klines",
+	exchange.KlineTypeMarkPrice:    "/fapi/v1/markPriceKlines",
+	exchange.KlineTypeIndexPrice:   "/fapi/v1/indexPriceKlines",
+	exchange.KlineTypePremiumIndex: "/fapi/v1/premiumIndexKlines",
+}
+
+func (b *binance) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceKlineRequest) ([]exchange.GetMarkPriceKlineResponse, error) {
+	klines, err := b.GetKline(ctx, &exchange.GetKlineRequest{
+		Symbol:     exchange.Symbol{OriginalSymbol: req.Symbol, UnifiedSymbol: req.Symbol},
+		Start:      req.Start,
+		End:        req.End,
+		Period:     req.Period,
+		MarketType: exchange.MarketTypePerpetualUSDMargined,
+		Type:       exchange.KlineTypeMarkPrice,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]exchange.GetMarkPriceKlineResponse, 0, len(klines))
+	for _, k := range klines {
+		result = append(result, exchange.GetMarkPriceKlineResponse{
+			Symbol:   k.Symbol,
+			OpenTime: k.OpenTime,
+			Open:     k.Open,
+			High:     k.High,
+			Low:      k.Low,
+			Close:    k.Close,
+			Confirm:  k.Confirm,
+		})
+	}
+	return result, nil
+}
+
+// GetKline 获取K线，现货只支持成交价K线，合约支持标记价格、指数价格和溢价指数K线
+func (b *binance) GetKline(ctx context.Context, req *exchange.GetKlineRequest) ([]exchange.GetKlineResponse, error) {
+	r := &bnhttp.Request{
+		Method:  http.MethodGet,
+		SecType: bnhttp.SecTypeNone,
+	}
+	params := bnhttp.Params{
+		"symbol":   req.Symbol.OriginalSymbol,
+		"interval": req.Period,
+	}
+	switch req.MarketType {
+	case exchange.MarketTypeSpot, exchange.MarketTypeMargin:
+		if req.Type != exchange.KlineTypeTrade {
+			return nil, exchange.ErrKlineTypeNotSupported
+		}
+		r.Endpoint = "/api/v3/klines"
+		b.client.SetApiEndpoint(bnSpotEndpoint)
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined,
+		exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		endpoint, ok := bnKlineEndpoints[req.Type]
+		if !ok {
+			return nil, exchange.ErrKlineTypeNotSupported
+		}
+		r.Endpoint = endpoint
+		if req.Type == exchange.KlineTypeIndexPrice {
+			// 交割合约 BTCUSDT_250328 的标的为 BTCUSDT，币本位 BTCUSD_PERP 的标的为 BTCUSD
+			delete(params, "symbol")
+			params["pair"], _, _ = strings.Cut(req.Symbol.OriginalSymbol, "_")
+		}
+		b.setFuturesEndpoint(r, req.MarketType)
+	default:
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+
+	if req.Start != 0 {
+		params["startTime"] = req.Start
+	}
+	if req.End != 0 {
+		params["endTime"] = req.End
+	}
+	if req.Limit != 0 {
+		params["limit"] = req.Limit
+	}
+
+	r = r.SetParams(params)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var klines [][]interface{}
+	err = bnhttp.Json.Unmarshal(data, &klines)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	result := make([]exchange.GetKlineResponse, 0, len(klines))
+	for _, k := range klines {
+		kline, err := bnToKline(k, req.Type == exchange.KlineTypeTrade, now)
+		if err != nil {
+			return nil, err
+		}
+		if req.MarketType.IsCoinMargined() && req.Type == exchange.KlineTypeTrade {
+			bnCoinKlineVolume(&kline, req.Symbol.CtVal)
+		}
+		kline.Symbol = req.Symbol.UnifiedSymbol
+		result = append(result, kline)
+	}
+	return result, nil
+}
+
+// KlineHistory 按时间窗口分页获取K线
+func (b *binance) KlineHistory(req *exchange.GetKlineHistoryRequest) exchange.Iterator[exchange.GetKlineResponse] {
+	limit := bnFuturesKlineLimit
+	if req.MarketType == exchange.MarketTypeSpot || req.MarketType == exchange.MarketTypeMargin {
+		limit = bnSpotKlineLimit
+	}
+	return exchange.NewKlineIterator(req, limit, func(ctx context.Context, start, end int64) ([]exchange.GetKlineResponse, error) {
+		return b.GetKline(ctx, &exchange.GetKlineRequest{
+			Symbol:     req.Symbol,
+			Start:      start,
+			End:        end,
+			Period:     req.Period,
+			Limit:      limit,
+			MarketType: req.MarketType,
+			Type:       req.Type,
+		})
+	})
+}
+
+// bnToKline 解析K线数组，标记价格等K线没有成交量；收盘时间早于当前时间的K线为已完结
+func bnToKline(k []interface{}, withVolume bool, now int64) (exchange.GetKlineResponse, error) {
+	if len(k) < 8 {
+		return exchange.GetKlineResponse{}, errors.New("invalid kline data")
+	}
+	prices := make([]decimal.Decimal, 0, 4)
+	for _, v := range k[1:5] {
+		s, _ := v.(string)
+		price, err := decimal.NewFromString(s)
+		if err != nil {
+			return exchange.GetKlineResponse{}, err
+		}
+		prices = append(prices, price)
+	}
+	openTime, _ := k[0].(float64)
+	closeTime, _ := k[6].(float64)
+	kline := exchange.GetKlineResponse{
+		OpenTime: int64(openTime),
+		Open:     prices[0],
+		High:     prices[1],
+		Low:      prices[2],
+		Close:    prices[3],
+		Confirm:  "0",
+	}
+	if int64(closeTime) < now {
+		kline.Confirm = "1"
+	}
+	if withVolume {
+		volume, err := decimal.NewFromString(k[5].(string))
+		if err != nil {
+			return exchange.GetKlineResponse{}, err
+		}
+		quoteVolume, err := decimal.NewFromString(k[7].(string))
+		if err != nil {
+			return exchange.GetKlineResponse{}, err
+		}
+		kline.Volume = volume
+		kline.QuoteVolume = quoteVolume
+	}
+	return kline, nil
+}
+
+// bnCoinKlineVolume 币本位合约K线的成交量为张数，成交额为币的数量；
+// 转换为成交量以币计，成交额以美元计（张数 * 面值），面值为零时成交额为零
+func bnCoinKlineVolume(kline *exchange.GetKlineResponse, ctVal decimal.Decimal) {
+	contracts := kline.Volume
+	kline.Volume = kline.QuoteVolume
+	kline.QuoteVolume = contracts.Mul(ctVal)
+}
@@ -0,0 +1,128 @@
+package bnexc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/bnhttp"
+	"github.com/shopspring/decimal"
+)
+
+// bnOrderPath 订单和成交接口的地址
+type bnOrderPath struct {
+	endpoint string
+	order    string
+	trades   string
+}
+
+// bnOrderPaths 统一账户的杠杆和合约使用 papi，现货仍使用现货账户接口
+func bnOrderPaths(marketType exchange.MarketType, unified bool) (bnOrderPath, error) {
+	switch marketType {
+	case exchange.MarketTypeSpot:
+		return bnOrderPath{bnSpotEndpoint, "/api/v3/order", "/api/v3/myTrades"}, nil
+	case exchange.MarketTypeMargin:
+		if unified {
+			return bnOrderPath{bnPortfolioMarginEndpoint, "/papi/v1/margin/order", "/papi/v1/margin/myTrades"}, nil
+		}
+		return bnOrderPath{bnSpotEndpoint, "/sapi/v1/margin/order", "/sapi/v1/margin/myTrades"}, nil
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		if unified {
+			return bnOrderPath{bnPortfolioMarginEndpoint, "/papi/v1/um/order", "/papi/v1/um/userTrades"}, nil
+		}
+		return bnOrderPath{bnFuturesEndpoint, "/fapi/v1/order", "/fapi/v1/userTrades"}, nil
+	case exchange.MarketTypeFuturesCoinMargined, exchange.MarketTypePerpetualCoinMargined:
+		if unified {
+			return bnOrderPath{bnPortfolioMarginEndpoint, "/papi/v1/cm/order", "/papi/v1/cm/userTrades"}, nil
+		}
+		return bnOrderPath{bnDeliveryEndpoint, "/dapi/v1/order", "/dapi/v1/userTrades"}, nil
+	}
+	return bnOrderPath{}, exchange.ErrInstrumentTypeNotSupported
+}
+
+// pmAssets 统一账户资产，杠杆返回全仓杠杆余额，合约返回 U 本位或币本位钱包余额加未实现盈亏
+func (b *binance) pmAssets(ctx context.Context, req *exchange.GetAssetsRequest) ([]exchange.Asset, error) {
+	r := &bnhttp.Request{
+		Method:    http.MethodGet,
+		Endpoint:  "/papi/v1/balance",
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	data, err := b.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*bnPmBalance, 0)
+	err = bnhttp.Json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]exchange.Asset, 0, len(res))
+	for _, v := range res {
+		var free, locked decimal.Decimal
+		if req.MarketType == exchange.MarketTypeMargin {
+			if free, err = decimal.NewFromString(v.CrossMarginFree); err != nil {
+				return nil, err
+			}
+			if locked, err = decimal.NewFromString(v.CrossMarginLocked); err != nil {
+				return nil, err
+			}
+		} else {
+			walletBalance, unrealizedPNL := v.UmWalletBalance, v.UmUnrealizedPNL
+			if req.MarketType.IsCoinMargined() {
+				walletBalance, unrealizedPNL = v.CmWalletBalance, v.CmUnrealizedPNL
+			}
+			wallet, err := decimal.NewFromString(walletBalance)
+			if err != nil {
+				return nil, err
+			}
+			upl, err := decimal.NewFromString(unrealizedPNL)
+			if err != nil {
+				return nil, err
+			}
+			free = wallet.Add(upl)
+		}
+		if free.IsZero() && locked.IsZero() {
+			continue
+		}
+		result = append(result, exchange.Asset{
+			AssetName:  v.Asset,
+			Free:       free,
+			Locked:     locked,
+			Exchange:   exchange.BinanceExchange,
+			MarketType: req.MarketType,
+		})
+	}
+	return result, nil
+}
+
+// pmBorrowOrRepay 统一账户全仓杠杆借币和还币
+func (b *binance) pmBorrowOrRepay(ctx context.Context, req *exchange.MarginBorrowOrRepayRequest) error {
+	if req.IsIsolated {
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	r := &bnhttp.Request{
+		APIKey:    req.APIKey,
+		SecretKey: req.SecretKey,
+		Method:    http.MethodPost,
+		SecType:   bnhttp.SecTypeSigned,
+	}
+	switch req.Typ {
+	case "BORROW":
+		r.Endpoint = "/papi/v1/marginLoan"
+	case "REPAY":
+		r.Endpoint = "/papi/v1/repayLoan"
+	default:
+		return errors.New("invalid borrow or repay type")
+	}
+	b.client.SetApiEndpoint(bnPortfolioMarginEndpoint)
+	r = r.SetFormParams(bnhttp.Params{
+		"asset":  req.Asset,
+		"amount": req.Amount,
+	})
+	_, err := b.callAPI(ctx, r)
+	return err
+}
@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInvalidContractPrice 币本位合约张币转换需要大于零的价格
+var ErrInvalidContractPrice = errors.New("invalid contract price")
+
+// IsContract 是否为合约市场
+func (m MarketType) IsContract() bool {
+	switch m {
+	case MarketTypeFuturesUSDMargined, MarketTypePerpetualUSDMargined,
+		MarketTypeFuturesCoinMargined, MarketTypePerpetualCoinMargined:
+		return true
+	}
+	return false
+}
+
+// IsCoinMargined 是否为币本位合约
+func (m MarketType) IsCoinMargined() bool {
+	return m == MarketTypeFuturesCoinMargined || m == MarketTypePerpetualCoinMargined
+}
+
+// IsPerpetual 是否为永续合约
+func (m MarketType) IsPerpetual() bool {
+	return m == MarketTypePerpetualUSDMargined || m == MarketTypePerpetualCoinMargined
+}
+
+// ContractToCoin 合约张数转换为币的数量；
+// U本位合约 币数量 = 张数 * 面值，币本位合约面值以美元计价，币数量 = 张数 * 面值 / 价格
+func ContractToCoin(symbol Symbol, contracts, price decimal.Decimal) (decimal.Decimal, error) {
+	if symbol.CtVal.IsZero() {
+		return contracts, nil
+	}
+	if !symbol.MarketType.IsCoinMargined() {
+		return contracts.Mul(symbol.CtVal), nil
+	}
+	if !price.IsPositive() {
+		return decimal.Zero, ErrInvalidContractPrice
+	}
+	return contracts.Mul(symbol.CtVal).Div(price), nil
+}
+
+// CoinToContract 币的数量转换为合约张数，未按精度取整
+func CoinToContract(symbol Symbol, coins, price decimal.Decimal) (decimal.Decimal, error) {
+	if symbol.CtVal.IsZero() {
+		return coins, nil
+	}
+	if !symbol.MarketType.IsCoinMargined() {
+		return coins.Div(symbol.CtVal), nil
+	}
+	if !price.IsPositive() {
+		return decimal.Zero, ErrInvalidContractPrice
+	}
+	return coins.Mul(price).Div(symbol.CtVal), nil
+}
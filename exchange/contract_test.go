@@ -0,0 +1,33 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContractCoinConversion(t *testing.T) {
+	linear := Symbol{MarketType: MarketTypePerpetualUSDMargined, CtVal: decimal.NewFromFloat(0.01)}
+	coins, err := ContractToCoin(linear, decimal.NewFromInt(5), decimal.Zero)
+	assert.Nil(t, err)
+	assert.True(t, decimal.NewFromFloat(0.05).Equal(coins))
+
+	// 币本位每张 100 美元，价格 50000 时 10 张为 0.02 个币
+	inverse := Symbol{MarketType: MarketTypePerpetualCoinMargined, CtVal: decimal.NewFromInt(100)}
+	coins, err = ContractToCoin(inverse, decimal.NewFromInt(10), decimal.NewFromInt(50000))
+	assert.Nil(t, err)
+	assert.True(t, decimal.NewFromFloat(0.02).Equal(coins))
+
+	contracts, err := CoinToContract(inverse, coins, decimal.NewFromInt(50000))
+	assert.Nil(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(contracts))
+
+	_, err = CoinToContract(inverse, coins, decimal.Zero)
+	assert.Equal(t, ErrInvalidContractPrice, err)
+
+	assert.True(t, MarketTypeFuturesCoinMargined.IsContract())
+	assert.False(t, MarketTypeMargin.IsContract())
+	assert.True(t, MarketTypePerpetualCoinMargined.IsPerpetual())
+	assert.Equal(t, "BTC-USD-PERP", UnifiedSymbolName("btc", "usd", MarketTypePerpetualCoinMargined, 0))
+}
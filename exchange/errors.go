@@ -0,0 +1,49 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error 交易所返回的错误，Err 为统一的错误类型，可用 errors.Is 判断，如 errors.Is(err, ErrOrderNotEnoughBalance)
+type Error struct {
+	Exchange  string
+	Code      string // 交易所原始错误码
+	Message   string // 交易所原始错误信息
+	Retryable bool   // 是否可以重试，如限频、系统繁忙、时间戳过期
+	Err       error
+	Cause     error // 原始错误，如 *bnhttp.APIError
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s error, code: %s, message: %s", e.Exchange, e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	if e.Cause != nil {
+		errs = append(errs, e.Cause)
+	}
+	return errs
+}
+
+// IsRetryable 判断错误是否可以重试
+func IsRetryable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Retryable
+	}
+	return false
+}
+
+// ErrorCode 获取交易所原始错误码，不是交易所错误时返回空
+func ErrorCode(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
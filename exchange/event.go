@@ -146,6 +146,61 @@ type MarkPriceEvent struct {
 	IsSettlement         bool // 是否结算,mockExchange专用
 }
 
+// FundingRateEvent 资金费率推送
+type FundingRateEvent struct {
+	Symbol          string
+	MarketType      MarketType
+	MarkPrice       decimal.Decimal // okx 不推送
+	IndexPrice      decimal.Decimal // okx 不推送
+	FundingRate     decimal.Decimal // 下一次结算的预测资金费率
+	NextFundingTime int64           // 下一次结算时间
+	Time            int64
+}
+
+// LiquidationEvent 强平订单推送
+type LiquidationEvent struct {
+	Symbol       string
+	MarketType   MarketType
+	Side         SideType        // 强平订单方向，SELL 为多头仓位被强平
+	PositionSide PositionSide    // okx 推送，binance 为空
+	Price        decimal.Decimal // binance 为成交均价，未成交时为订单价格；okx 为破产价格
+	Size         decimal.Decimal // binance U 本位为币，币本位和 okx 为张
+	Time         int64
+}
+
+// OpenInterestEvent 持仓量推送
+type OpenInterestEvent struct {
+	Symbol          string
+	MarketType      MarketType
+	OpenInterest    decimal.Decimal // binance U 本位为币，币本位和 okx 为张
+	OpenInterestCcy decimal.Decimal // 以币为单位，binance 币本位为空
+	Time            int64
+}
+
+// BookTickerEvent 最优挂单推送
+type BookTickerEvent struct {
+	Symbol     string
+	MarketType MarketType
+	BidPrice   decimal.Decimal
+	BidSize    decimal.Decimal
+	AskPrice   decimal.Decimal
+	AskSize    decimal.Decimal
+	UpdateID   int64 // binance 为 u，okx 为 seqId
+	Time       int64 // 交易所时间，binance 现货不推送时间，为本地接收时间
+}
+
+// DepthEvent 深度推送，IsSnapshot 为 true 时 Asks、Bids 为前 N 档快照，
+// 否则为本次变化的档位，数量为零表示删除该档；卖盘按价格升序，买盘按价格降序
+type DepthEvent struct {
+	Symbol     string
+	MarketType MarketType
+	Asks       [][]decimal.Decimal // [价格, 数量]
+	Bids       [][]decimal.Decimal
+	IsSnapshot bool
+	UpdateID   int64 // binance 为最后一次更新 ID，okx 为 seqId
+	Time       int64
+}
+
 type KlineEvent struct {
 	Symbol                   string
 	OpenTime                 int64
@@ -192,6 +247,10 @@ type SymbolUpdateEvent struct {
 	PricePrecision int32
 	// 头寸精度
 	SizePrecision int32
+	// 价格步长
+	TickSize decimal.Decimal
+	// 数量步长
+	StepSize decimal.Decimal
 	// 合约面值
 	CtVal decimal.Decimal
 	// 合约乘数
@@ -3,13 +3,25 @@ package exchange
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"github.com/go-gotop/kit/limiter"
 	"github.com/shopspring/decimal"
 )
 
 // PosMode ISOLATED 逐仓，CROSSED 全仓
 type PosMode string
 
+// PositionMode ONE_WAY 单向持仓，HEDGE 双向持仓
+type PositionMode string
+
+// KlineType 为空时为成交价K线，MARK_PRICE 标记价格，INDEX_PRICE 指数价格，PREMIUM_INDEX 溢价指数
+type KlineType string
+
+// LedgerType FEE, FUNDING_FEE, REALIZED_PNL, TRANSFER, INTEREST, LIQUIDATION, OTHER
+type LedgerType string
+
 // SideType BUY, SELL
 type SideType string
 
@@ -52,6 +64,12 @@ type TransactionStatus string
 // TimeInForce GTC, IOC, FOK, GTX, GTD
 type TimeInForce string
 
+// SelfTradePreventionMode NONE, EXPIRE_TAKER, EXPIRE_MAKER, EXPIRE_BOTH
+type SelfTradePreventionMode string
+
+// PriceMatch OPPONENT, OPPONENT_5, OPPONENT_10, OPPONENT_20, QUEUE, QUEUE_5, QUEUE_10, QUEUE_20
+type PriceMatch string
+
 // StrategyStatus NEW, START, STOP, DELETE
 type StrategyStatus string
 
@@ -65,6 +83,7 @@ const (
 	OkxExchange      = "OKX"
 	CoinBaseExchange = "COINBASE"
 	MockExchange     = "MOCK"
+	PaperExchange    = "PAPER"
 
 	AccountTypeClassic = "CLASSIC"
 	AccountTypeUnified = "UNIFIED"
@@ -84,10 +103,28 @@ const (
 	PosModeIsolated PosMode = "ISOLATED" // 逐仓
 	PosModeCross    PosMode = "CROSS"    // 全仓
 
-	MarketTypeSpot                 MarketType = "SPOT"                   // 现货
-	MarketTypeFuturesUSDMargined   MarketType = "FUTURES_USD_MARGINED"   // 期货
-	MarketTypePerpetualUSDMargined MarketType = "PERPETUAL_USD_MARGINED" // 永续
-	MarketTypeMargin               MarketType = "MARGIN"                 // 杠杆
+	PositionModeOneWay PositionMode = "ONE_WAY" // 单向持仓
+	PositionModeHedge  PositionMode = "HEDGE"   // 双向持仓
+
+	LedgerTypeFee         LedgerType = "FEE"          // 交易手续费
+	LedgerTypeFundingFee  LedgerType = "FUNDING_FEE"  // 资金费
+	LedgerTypeRealizedPnl LedgerType = "REALIZED_PNL" // 平仓盈亏
+	LedgerTypeTransfer    LedgerType = "TRANSFER"     // 资金划转
+	LedgerTypeInterest    LedgerType = "INTEREST"     // 借币利息
+	LedgerTypeLiquidation LedgerType = "LIQUIDATION"  // 强平
+	LedgerTypeOther       LedgerType = "OTHER"
+
+	KlineTypeTrade        KlineType = ""              // 成交价
+	KlineTypeMarkPrice    KlineType = "MARK_PRICE"    // 标记价格
+	KlineTypeIndexPrice   KlineType = "INDEX_PRICE"   // 指数价格
+	KlineTypePremiumIndex KlineType = "PREMIUM_INDEX" // 溢价指数
+
+	MarketTypeSpot                  MarketType = "SPOT"                    // 现货
+	MarketTypeFuturesUSDMargined    MarketType = "FUTURES_USD_MARGINED"    // 期货
+	MarketTypePerpetualUSDMargined  MarketType = "PERPETUAL_USD_MARGINED"  // 永续
+	MarketTypeMargin                MarketType = "MARGIN"                  // 杠杆
+	MarketTypeFuturesCoinMargined   MarketType = "FUTURES_COIN_MARGINED"   // 币本位交割，数量单位为张
+	MarketTypePerpetualCoinMargined MarketType = "PERPETUAL_COIN_MARGINED" // 币本位永续，数量单位为张
 
 	MarginTypeMargin   MarginType = "MARGIN"
 	MarginTypeIsolated MarginType = "ISOLATED"
@@ -108,6 +145,7 @@ const (
 	OrderTypeTakeProfit         OrderType = "TAKE_PROFIT"
 	OrderTypeTakeProfitMarket   OrderType = "TAKE_PROFIT_MARKET"
 	OrderTypeTrailingStopMarket OrderType = "TRAILING_STOP_MARKET"
+	OrderTypeOco                OrderType = "OCO" // 止盈止损二选一，只用于查询条件单
 
 	OrderStateTrade           OrderState = "TRADE"
 	OrderStateNew             OrderState = "NEW"
@@ -120,6 +158,14 @@ const (
 	// 标识系统异常订单
 	OrderStateUnusual OrderState = "UNUSUAL"
 
+	ExecutionStateNew      ExecutionState = "NEW"
+	ExecutionStateTrade    ExecutionState = "TRADE"
+	ExecutionStateCanceled ExecutionState = "CANCELED"
+	ExecutionStateRejected ExecutionState = "REJECTED"
+	ExecutionStateExpired  ExecutionState = "EXPIRED"
+	// 订单被修改
+	ExecutionStateAmendment ExecutionState = "AMENDMENT"
+
 	PositionStatusNew     PositionStatus = "NEW"
 	PositionStatusOpening PositionStatus = "OPENING"
 	PositionStatusHolding PositionStatus = "HOLDING"
@@ -148,6 +194,22 @@ const (
 	TimeInForceGTX TimeInForce = "GTX"
 	// GTD - Good Till Date 在特定时间之前有效，到期自动撤销
 	TimeInForceGTD TimeInForce = "GTD"
+
+	// 自成交保护，同一账户的买卖单将要成交时撤销的一方
+	SelfTradePreventionNone        SelfTradePreventionMode = "NONE"
+	SelfTradePreventionExpireTaker SelfTradePreventionMode = "EXPIRE_TAKER" // 撤销吃单方
+	SelfTradePreventionExpireMaker SelfTradePreventionMode = "EXPIRE_MAKER" // 撤销挂单方
+	SelfTradePreventionExpireBoth  SelfTradePreventionMode = "EXPIRE_BOTH"  // 双方都撤销
+
+	// 按盘口价格下单，OPPONENT 为对手价，QUEUE 为同向排队价，数字表示第几档
+	PriceMatchOpponent   PriceMatch = "OPPONENT"
+	PriceMatchOpponent5  PriceMatch = "OPPONENT_5"
+	PriceMatchOpponent10 PriceMatch = "OPPONENT_10"
+	PriceMatchOpponent20 PriceMatch = "OPPONENT_20"
+	PriceMatchQueue      PriceMatch = "QUEUE"
+	PriceMatchQueue5     PriceMatch = "QUEUE_5"
+	PriceMatchQueue10    PriceMatch = "QUEUE_10"
+	PriceMatchQueue20    PriceMatch = "QUEUE_20"
 )
 
 var (
@@ -167,6 +229,34 @@ var (
 	ErrRateLimitExceeded = errors.New("rate limit exceeded, IP ban imminent")
 	// ErrListenKeyExpired Stream listenKey 过期（适用binance）
 	ErrListenKeyExpired = errors.New("listen key expired")
+	// ErrOrderOptionNotSupported 交易所或市场不支持的下单选项
+	ErrOrderOptionNotSupported = errors.New("order option not supported")
+	// ErrMarginModePerOrder 交易所在下单时指定保证金模式（如 okx），不能单独设置，使用 CreateOrderRequest.MarginMode
+	ErrMarginModePerOrder = errors.New("margin mode is set per order")
+	// ErrKlineTypeNotSupported 交易所或市场不支持的K线类型
+	ErrKlineTypeNotSupported = errors.New("kline type not supported")
+	// ErrOrderRejected 订单被交易所拒绝
+	ErrOrderRejected = errors.New("order rejected")
+	// ErrReduceOnlyRejected 只减仓订单被拒绝，没有可减少的仓位
+	ErrReduceOnlyRejected = errors.New("reduce only order rejected")
+	// ErrPostOnlyRejected 只做 maker 订单会立即成交被拒绝
+	ErrPostOnlyRejected = errors.New("post only order rejected")
+	// ErrInvalidSymbol 交易对不存在
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	// ErrInvalidParameter 请求参数错误
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrAuthenticationFailed API Key、签名或密码错误
+	ErrAuthenticationFailed = errors.New("authentication failed")
+	// ErrPermissionDenied API Key 没有权限或账户被冻结
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInvalidTimestamp 请求时间戳超出交易所允许的范围
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+	// ErrServiceUnavailable 交易所系统繁忙或超时
+	ErrServiceUnavailable = errors.New("service unavailable")
+	// ErrExecutionStatusUnknown 请求已发送但执行结果未知，订单可能已被接受，应查询订单状态而不是重试
+	ErrExecutionStatusUnknown = errors.New("execution status unknown")
+	// ErrUnknown 未归类的交易所错误
+	ErrUnknown = errors.New("unknown error")
 )
 
 type GetDepthRequest struct {
@@ -192,8 +282,20 @@ type GetKlineRequest struct {
 	Start      int64
 	End        int64
 	Period     string
-	Limit      uint8
+	Limit      int
+	MarketType MarketType
+	Type       KlineType
+}
+
+// 按时间范围分页查询K线，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetKlineHistoryRequest struct {
+	Symbol     Symbol
 	MarketType MarketType
+	Type       KlineType
+	Period     string
+	StartTime  int64
+	EndTime    int64
+	Limiter    limiter.Limiter // 不为空时每页请求前检查限频，超限时等待
 }
 
 type GetKlineResponse struct {
@@ -250,9 +352,10 @@ type GetAccountConfigResponse struct {
 }
 
 type MarginInventoryRequest struct {
-	APIKey    string
-	SecretKey string
-	Typ       MarginType
+	APIKey     string
+	SecretKey  string
+	Passphrase string
+	Typ        MarginType
 }
 
 type MarginInventory struct {
@@ -260,27 +363,32 @@ type MarginInventory struct {
 }
 
 type MarginBorrowOrRepayRequest struct {
-	APIKey     string
-	SecretKey  string
-	Asset      string
-	IsIsolated bool   // 是否逐仓，默认false
-	Symbol     string // 逐仓交易对，配合逐仓使用
-	Amount     decimal.Decimal
-	Typ        string // BORROW, REPAY
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Asset            string
+	IsIsolated       bool   // 是否逐仓，默认false
+	Symbol           string // 逐仓交易对，配合逐仓使用
+	Amount           decimal.Decimal
+	Typ              string // BORROW, REPAY
+	IsUnifiedAccount bool   // 统一账户, 默认 false，统一账户不支持逐仓
 }
 
 type GetMarginInterestRateRequest struct {
 	APIKey     string
 	SecretKey  string
+	Passphrase string
 	Assets     string // 支持多资产查询，以逗号分隔，最多支持20个资产
 	IsIsolated bool   // 是否逐仓
 }
 
 type GetPositionRequest struct {
-	APIKey     string
-	SecretKey  string
-	Passphrase string
-	Symbol     string
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string
+	MarketType       MarketType // binance 区分 U 本位和币本位合约，为空时为 U 本位
+	IsUnifiedAccount bool       // 统一账户, 默认 false
 }
 
 type GetPositionHistoryRequest struct {
@@ -329,7 +437,8 @@ type GetMarginInterestRateResponse struct {
 }
 
 type GetFundingRate struct {
-	Symbol string
+	Symbol     string     // 为空时获取全部永续合约
+	MarketType MarketType // 为空时为 U 本位永续
 }
 
 type GetFundingRateResponse struct {
@@ -337,37 +446,116 @@ type GetFundingRateResponse struct {
 	MarkPrice            decimal.Decimal // 标记价格
 	IndexPrice           decimal.Decimal // 指数价格
 	EstimatedSettlePrice decimal.Decimal // 预估结算价，仅在交割开始前最后一小时有意义
-	LastFundingRate      decimal.Decimal // 最近更新的资金费率
+	LastFundingRate      decimal.Decimal // 上一次结算的资金费率
+	NextFundingRate      decimal.Decimal // 下一次结算的预测资金费率
 	NextFundingTime      int64           // 下一个资金费时间
 	InterestRate         decimal.Decimal // 标的资产基础利率
 	Time                 int64           // 更新时间
 }
 
-type GetAssetsRequest struct {
-	APIKey     string
-	SecretKey  string
-	Passphrase string
+// 分页查询历史资金费率，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetFundingRateHistoryRequest struct {
+	Symbol     string
 	MarketType MarketType
+	StartTime  int64
+	EndTime    int64
+}
+
+// 历史资金费率
+type FundingRate struct {
+	Symbol      string
+	FundingRate decimal.Decimal // 结算时的资金费率
+	MarkPrice   decimal.Decimal // 结算时的标记价格，okx 不返回
+	FundingTime int64           // 结算时间
+}
+
+type GetAssetsRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	MarketType       MarketType
+	IsUnifiedAccount bool // 统一账户, 默认 false
 }
 
 type CreateOrderRequest struct {
+	APIKey                  string
+	SecretKey               string
+	Passphrase              string // 秘钥 密码 (okex)
+	OrderTime               int64
+	Symbol                  Symbol
+	CtVal                   decimal.Decimal // 合约面值； 合约张数 = 合约数量 / 合约面值
+	ClientOrderID           string
+	Side                    SideType
+	OrderType               OrderType
+	PositionSide            PositionSide
+	TimeInForce             TimeInForce
+	MarginMode              PosMode // 合约和杠杆的保证金模式，为空时全仓；只用于 okx，币安使用 SetMarginMode
+	ExpireTime              int64   // GTD 订单过期时间，毫秒时间戳
+	MarketType              MarketType
+	Size                    decimal.Decimal
+	Price                   decimal.Decimal
+	StopPrice               decimal.Decimal         // 条件单触发价格，STOP、STOP_MARKET、TAKE_PROFIT、TAKE_PROFIT_MARKET 使用
+	ActivationPrice         decimal.Decimal         // 跟踪止损激活价格，为零时立即激活
+	CallbackRate            decimal.Decimal         // 跟踪止损回调比例，0.01 表示 1%
+	ReduceOnly              bool                    // 只减仓，用于单向持仓，双向持仓的平仓单本身只减仓
+	ClosePosition           bool                    // 触发后平掉全部仓位，只用于合约 STOP_MARKET、TAKE_PROFIT_MARKET，不需要设置 Size
+	SelfTradePreventionMode SelfTradePreventionMode // 自成交保护模式，为空时使用交易所默认设置
+	PriceMatch              PriceMatch              // 按盘口价格下单，只支持币安合约，设置后不需要设置 Price
+	IsUnifiedAccount        bool                    // 统一账户, 默认 false
+}
+
+// CreateOcoOrderRequest OCO 订单，止盈单和止损单其中一个触发或成交后另一个自动撤销
+type CreateOcoOrderRequest struct {
 	APIKey           string
 	SecretKey        string
-	Passphrase       string // 秘钥 密码 (okex)
-	OrderTime        int64
+	Passphrase       string
 	Symbol           Symbol
-	CtVal            decimal.Decimal // 合约面值； 合约张数 = 合约数量 / 合约面值
-	ClientOrderID    string
+	MarketType       MarketType
+	ClientOrderID    string // 订单组客户端ID，binance listClientOrderId，okx algoClOrdId
 	Side             SideType
-	OrderType        OrderType
 	PositionSide     PositionSide
-	TimeInForce      TimeInForce
-	MarketType       MarketType
 	Size             decimal.Decimal
-	Price            decimal.Decimal
-	IsUnifiedAccount bool // 统一账户, 默认 false
+	TakeProfit       OcoOrderLeg
+	StopLoss         OcoOrderLeg
+	IsUnifiedAccount bool
+}
+
+// OcoOrderLeg OCO 订单的一条腿
+type OcoOrderLeg struct {
+	ClientOrderID string          // 只有 binance 支持
+	TriggerPrice  decimal.Decimal // 触发价格，binance 止盈腿为零时挂 LIMIT_MAKER 单
+	Price         decimal.Decimal // 委托价格，为零时触发后市价成交
 }
 
+// AlgoOrderRequest 查询或撤销条件单，OrderID 与 ClientOrderID 二选一
+// binance 合约条件单为普通订单，OrderID/ClientOrderID 为订单号；binance 现货 OCO 为 orderListId/listClientOrderId；okx 为 algoId/algoClOrdId
+type AlgoOrderRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           Symbol
+	MarketType       MarketType
+	OrderID          string
+	ClientOrderID    string
+	IsUnifiedAccount bool
+}
+
+// AlgoOrderResponse 条件单，State 为 NEW 表示等待触发，FILLED 表示已触发，REJECTED 表示触发后下单失败
+type AlgoOrderResponse struct {
+	OrderID       string
+	ClientOrderID string
+	Symbol        string
+	OrderType     OrderType
+	State         OrderState
+	Side          SideType
+	PositionSide  PositionSide
+	Size          decimal.Decimal
+	Orders        []*SearchOrderResponse // 子订单，binance OCO 为两条腿，okx 为触发后生成的订单
+	CreatedTime   int64
+	UpdateTime    int64
+}
+
+// CreateOrderResponse 下单回执，State 为下单后的初始状态，IOC/FOK 和市价单可直接得到成交结果
 type CreateOrderResponse struct {
 	TransactTime     int64
 	Symbol           string
@@ -377,17 +565,56 @@ type CreateOrderResponse struct {
 	State            OrderState
 	PositionSide     PositionSide
 	Price            decimal.Decimal
+	AvgPrice         decimal.Decimal
 	OriginalQuantity decimal.Decimal
 	ExecutedQuantity decimal.Decimal
+	Fills            []*SearchTradesResponse // 立即成交明细，只有 binance 现货/杠杆和 paper 返回
 }
 
-type SearchOrderRequest struct {
-	APIKey        string
-	SecretKey     string
-	Passphrase    string
+// BatchOrderResult 批量下单/撤单中单个订单的结果，Err 不为空表示该订单失败
+type BatchOrderResult struct {
 	ClientOrderID string
-	MarketType    MarketType
-	Symbol        Symbol
+	OrderID       string
+	Err           error
+}
+
+// AmendOrderRequest 修改订单，NewSize、NewPrice 为零表示不修改
+type AmendOrderRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           Symbol
+	ClientOrderID    string // 原订单客户端订单号
+	OrderID          string // 原订单交易所订单号，与 ClientOrderID 二选一
+	NewClientOrderID string // 新客户端订单号，只有 binance 现货支持
+	Side             SideType
+	PositionSide     PositionSide
+	OrderType        OrderType
+	TimeInForce      TimeInForce
+	MarketType       MarketType
+	NewSize          decimal.Decimal
+	NewPrice         decimal.Decimal
+	IsUnifiedAccount bool // 统一账户, 默认 false
+}
+
+type AmendOrderResponse struct {
+	Symbol        string
+	ClientOrderID string
+	OrderID       string
+	State         OrderState
+	Price         decimal.Decimal
+	Size          decimal.Decimal
+	UpdateTime    int64
+}
+
+type SearchOrderRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	ClientOrderID    string
+	MarketType       MarketType
+	Symbol           Symbol
+	IsUnifiedAccount bool // 统一账户, 默认 false
 }
 
 type SearchOrderResponse struct {
@@ -413,37 +640,145 @@ type SearchOrderResponse struct {
 
 // 账户成交历史
 type SearchTradesRequest struct {
-	APIKey     string
-	SecretKey  string
-	Symbol     string
-	OrderID    string
-	MarketType MarketType
+	APIKey           string
+	SecretKey        string
+	Symbol           string
+	OrderID          string
+	MarketType       MarketType
+	IsUnifiedAccount bool // 统一账户, 默认 false
 }
 
 type SearchTradesResponse struct {
-	Symbol   string
-	ID       string
-	OrderID  string
-	Price    decimal.Decimal
-	Volume   decimal.Decimal
-	FeeCost  decimal.Decimal
-	FeeAsset string
-	Time     int64
-	By       string
+	Symbol        string
+	ID            string
+	OrderID       string
+	ClientOrderID string
+	Side          SideType
+	PositionSide  PositionSide
+	Price         decimal.Decimal
+	Volume        decimal.Decimal
+	FeeCost       decimal.Decimal
+	FeeAsset      string
+	RealizedPnl   decimal.Decimal // 合约平仓已实现盈亏，不含手续费
+	Time          int64
+	By            string
+}
+
+// 分页查询历史订单，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetOrderHistoryRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string // binance 必填
+	MarketType       MarketType
+	StartTime        int64
+	EndTime          int64
+	IsUnifiedAccount bool
 }
 
-type CancelOrderRequest struct {
-	APIKey        string
-	SecretKey     string
-	Passphrase    string
-	ClientOrderID string
+// 分页查询历史成交，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetTradeHistoryRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string // binance 必填
+	MarketType       MarketType
+	StartTime        int64
+	EndTime          int64
+	IsUnifiedAccount bool
+}
+
+// 分页查询已平仓仓位，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetClosedPositionsRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string // binance 必填
+	MarketType       MarketType
+	StartTime        int64
+	EndTime          int64
+	IsUnifiedAccount bool
+}
+
+// 已平仓仓位
+type ClosedPosition struct {
 	Symbol        string
 	MarketType    MarketType
+	PositionSide  PositionSide
+	OpenAvgPrice  decimal.Decimal // 开仓均价
+	CloseAvgPrice decimal.Decimal // 平仓均价
+	Size          decimal.Decimal // 累计平仓数量
+	Pnl           decimal.Decimal // 平仓收益，不含手续费和资金费
+	Fee           decimal.Decimal // 累计手续费，正数为支出
+	FundingFee    decimal.Decimal // 累计资金费，正数为支出
+	RealizedPnl   decimal.Decimal // 已实现盈亏，含手续费和资金费
+	OpenTime      int64
+	CloseTime     int64
+}
+
+// 分页查询账单流水，时间为毫秒时间戳，EndTime 为空时取当前时间
+type GetLedgerRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string
+	MarketType       MarketType
+	Type             LedgerType // 为空时查询全部类型
+	StartTime        int64
+	EndTime          int64
+	IsUnifiedAccount bool
+}
+
+// 账单流水
+type LedgerEntry struct {
+	ID         string
+	Symbol     string
+	MarketType MarketType
+	Type       LedgerType
+	Asset      string
+	Amount     decimal.Decimal // 资金变动，正数为收入，负数为支出
+	Balance    decimal.Decimal // 变动后余额，币安不返回
+	OrderID    string
+	TradeID    string
+	Info       string // 交易所原始账单类型
+	Time       int64
+}
+
+// 撤单，OrderID 不为空时优先按交易所订单ID撤单
+type CancelOrderRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	ClientOrderID    string
+	OrderID          string
+	Symbol           string
+	MarketType       MarketType
+	IsUnifiedAccount bool // 统一账户, 默认 false
 }
 
 type CancelOrderResponse struct {
 }
 
+// 查询当前挂单，Symbol 为空时查询该市场类型下的全部挂单
+type GetOpenOrdersRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string
+	MarketType       MarketType
+	IsUnifiedAccount bool
+}
+
+// 撤销全部挂单，Symbol 为空时撤销该市场类型下的全部挂单
+type CancelAllOrdersRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Symbol           string
+	MarketType       MarketType
+	IsUnifiedAccount bool
+}
+
 // 获取标的物杠杆配置
 type GetLeverageRequest struct {
 	APIKey     string
@@ -459,11 +794,42 @@ type GetLeverageResponse struct {
 	MarginType string
 }
 
+// SetPositionModeRequest 设置合约持仓模式，有持仓或挂单时交易所会拒绝修改
+type SetPositionModeRequest struct {
+	APIKey           string
+	SecretKey        string
+	Passphrase       string
+	Mode             PositionMode
+	MarketType       MarketType // binance 币本位合约单独设置，为空时为 U 本位
+	IsUnifiedAccount bool
+}
+
+// SetMarginModeRequest 设置合约交易对的保证金模式
+type SetMarginModeRequest struct {
+	APIKey     string
+	SecretKey  string
+	Passphrase string
+	Symbol     Symbol
+	MarketType MarketType
+	Mode       PosMode
+}
+
+// AdjustPositionMarginRequest 调整逐仓仓位保证金
+type AdjustPositionMarginRequest struct {
+	APIKey       string
+	SecretKey    string
+	Passphrase   string
+	Symbol       Symbol
+	MarketType   MarketType
+	PositionSide PositionSide    // 单向持仓为空
+	Amount       decimal.Decimal // 正数增加保证金，负数减少保证金
+}
+
 type TransferAssetRequest struct {
 	APIKey     string
 	SecretKey  string
 	Passphrase string
-	Type       string // 划转类型
+	Type       string // 划转类型，使用币安的划转类型，如 MAIN_UMFUTURE
 	Asset      string // 资产名称
 	Amount     decimal.Decimal
 }
@@ -485,6 +851,8 @@ type Symbol struct {
 	OriginalAsset string
 	// 统一资产名称
 	UnifiedAsset string
+	// 计价资产名称
+	QuoteAsset string
 	// 交易所
 	Exchange string
 	// 种类: SPOT, FUTURES
@@ -499,11 +867,17 @@ type Symbol struct {
 	MinPrice decimal.Decimal
 	// 最大价格
 	MaxPrice decimal.Decimal
+	// 最小名义价值
+	MinNotional decimal.Decimal
 	// 价格精度
 	PricePrecision int32
 	// 头寸精度
 	SizePrecision int32
-	// 合约面值
+	// 价格步长，价格需为其整数倍，如 0.05
+	TickSize decimal.Decimal
+	// 数量步长，数量需为其整数倍，合约为张数的步长
+	StepSize decimal.Decimal
+	// 合约面值，币本位合约为每张的美元价值
 	CtVal decimal.Decimal
 	// 合约乘数
 	CtMult decimal.Decimal
@@ -513,34 +887,98 @@ type Symbol struct {
 	ExpTime int64
 }
 
+const (
+	SymbolStatusEnabled  = "ENABLED"  // 交易中
+	SymbolStatusDisabled = "DISABLED" // 暂停交易或已下线
+)
+
+// StepPrecision 根据 tickSize/stepSize 计算小数位数，如 0.00100000 为 3
+func StepPrecision(step string) int32 {
+	i := strings.IndexByte(step, '.')
+	if i < 0 {
+		return 0
+	}
+	return int32(len(strings.TrimRight(step[i+1:], "0")))
+}
+
+// UnifiedSymbolName 生成统一交易对名称：现货/杠杆 BTC-USDT，永续 BTC-USDT-PERP，交割 BTC-USDT-250328，
+// 币本位合约的计价资产为 USD，如 BTC-USD-PERP
+func UnifiedSymbolName(base, quote string, marketType MarketType, expTime int64) string {
+	name := strings.ToUpper(base) + "-" + strings.ToUpper(quote)
+	switch marketType {
+	case MarketTypePerpetualUSDMargined, MarketTypePerpetualCoinMargined:
+		return name + "-PERP"
+	case MarketTypeFuturesUSDMargined, MarketTypeFuturesCoinMargined:
+		if expTime > 0 {
+			return name + "-" + time.UnixMilli(expTime).UTC().Format("060102")
+		}
+	}
+	return name
+}
+
 //go:generate mockgen -destination=../exchange/mocks/exchange.go -package=mkexchange . Exchange
 type Exchange interface {
 	Name() string
 	Assets(ctx context.Context, req *GetAssetsRequest) ([]Asset, error)
-	// Symbols(ctx context.Context, it InstrumentType) ([]Symbol, error)
-	CreateOrder(ctx context.Context, o *CreateOrderRequest) error
+	// 获取交易对列表
+	Symbols(ctx context.Context, marketType MarketType) ([]Symbol, error)
+	CreateOrder(ctx context.Context, o *CreateOrderRequest) (*CreateOrderResponse, error)
 	CancelOrder(ctx context.Context, o *CancelOrderRequest) error
+	// 批量下单，按请求顺序返回每个订单的结果；同一批订单需使用相同的账户和市场类型
+	BatchCreateOrders(ctx context.Context, o []*CreateOrderRequest) ([]*BatchOrderResult, error)
+	// 批量撤单，按请求顺序返回每个订单的结果；同一批订单需使用相同的账户和市场类型
+	BatchCancelOrders(ctx context.Context, o []*CancelOrderRequest) ([]*BatchOrderResult, error)
+	// 查询当前挂单
+	GetOpenOrders(ctx context.Context, req *GetOpenOrdersRequest) ([]*SearchOrderResponse, error)
+	// 撤销全部挂单
+	CancelAllOrders(ctx context.Context, req *CancelAllOrdersRequest) error
+	// 修改订单价格或数量
+	AmendOrder(ctx context.Context, o *AmendOrderRequest) (*AmendOrderResponse, error)
+	// 创建 OCO 订单
+	CreateOcoOrder(ctx context.Context, req *CreateOcoOrderRequest) (*AlgoOrderResponse, error)
+	// 撤销条件单
+	CancelAlgoOrder(ctx context.Context, req *AlgoOrderRequest) error
+	// 查询条件单
+	SearchAlgoOrder(ctx context.Context, req *AlgoOrderRequest) (*AlgoOrderResponse, error)
 	SearchOrder(ctx context.Context, o *SearchOrderRequest) (*SearchOrderResponse, error)
 	// 查询成交记录
 	SearchTrades(ctx context.Context, o *SearchTradesRequest) ([]*SearchTradesResponse, error)
 	// 获取资金费率
 	GetFundingRate(ctx context.Context, req *GetFundingRate) ([]*GetFundingRateResponse, error)
+	// 分页查询历史资金费率
+	FundingRateHistory(req *GetFundingRateHistoryRequest) Iterator[*FundingRate]
 	// 获取杠杠资产小时利率
 	GetMarginInterestRate(ctx context.Context, req *GetMarginInterestRateRequest) ([]*GetMarginInterestRateResponse, error)
 	// 杠杠借贷Or还款
 	MarginBorrowOrRepay(ctx context.Context, req *MarginBorrowOrRepayRequest) error
 	// 获取杠杠可用放贷库存
 	GetMarginInventory(ctx context.Context, req *MarginInventoryRequest) (*MarginInventory, error)
-	// okx 合约张币转换
+	// 合约张币转换，okx 合约和币本位合约的数量单位为张
 	ConvertContractCoin(typ string, symbol Symbol, sz string, opTyp string) (string, error)
 	// 获取当前持仓
 	GetPosition(ctx context.Context, req *GetPositionRequest) ([]*GetPositionResponse, error)
+	// 分页查询历史订单
+	OrderHistory(req *GetOrderHistoryRequest) Iterator[*SearchOrderResponse]
+	// 分页查询历史成交
+	TradeHistory(req *GetTradeHistoryRequest) Iterator[*SearchTradesResponse]
+	// 分页查询已平仓仓位
+	ClosedPositions(req *GetClosedPositionsRequest) Iterator[*ClosedPosition]
+	// 分页查询账单流水，包括手续费、资金费、平仓盈亏、划转、利息和强平
+	Ledger(req *GetLedgerRequest) Iterator[*LedgerEntry]
 	// 获取历史持仓
+	//
+	// Deprecated: 使用 ClosedPositions
 	GetHistoryPosition(ctx context.Context, req *GetPositionHistoryRequest) error
 	// 批量设置杠杠
 	SetLeverage(ctx context.Context, req *SetLeverageRequest) error
 	// 获取标的物杠杆配置
 	GetLeverage(ctx context.Context, req *GetLeverageRequest) (GetLeverageResponse, error)
+	// 设置合约持仓模式（单向/双向）
+	SetPositionMode(ctx context.Context, req *SetPositionModeRequest) error
+	// 设置合约保证金模式（全仓/逐仓）
+	SetMarginMode(ctx context.Context, req *SetMarginModeRequest) error
+	// 增加或减少逐仓保证金
+	AdjustPositionMargin(ctx context.Context, req *AdjustPositionMarginRequest) error
 	// 获取账户配置
 	GetAccountConfig(ctx context.Context, req *GetAccountConfigRequest) (GetAccountConfigResponse, error)
 	// 获取最大下单量
@@ -549,6 +987,8 @@ type Exchange interface {
 	GetMarkPriceKline(ctx context.Context, req *GetMarkPriceKlineRequest) ([]GetMarkPriceKlineResponse, error)
 	// 获取K线数据
 	GetKline(ctx context.Context, req *GetKlineRequest) ([]GetKlineResponse, error)
+	// 按时间范围分页查询K线，按开盘时间升序返回
+	KlineHistory(req *GetKlineHistoryRequest) Iterator[GetKlineResponse]
 	// 获取产品深度
 	GetDepth(ctx context.Context, req *GetDepthRequest) (GetDepthResponse, error)
 	// 获取最新价格
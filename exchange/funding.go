@@ -0,0 +1,122 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FundingRateCache 缓存各交易对的资金费率，超过 ttl 后从交易所重新获取；
+// 订阅资金费率推送时可调用 Update 更新缓存，减少接口请求
+type FundingRateCache struct {
+	ex    Exchange
+	ttl   time.Duration
+	rates map[string]*cachedFundingRate
+	mux   sync.RWMutex
+}
+
+type cachedFundingRate struct {
+	rate      GetFundingRateResponse
+	updatedAt time.Time
+}
+
+func NewFundingRateCache(ex Exchange, ttl time.Duration) *FundingRateCache {
+	return &FundingRateCache{
+		ex:    ex,
+		ttl:   ttl,
+		rates: make(map[string]*cachedFundingRate),
+	}
+}
+
+// Get 获取单个交易对的资金费率，缓存过期或已过结算时间时重新获取，marketType 为空时为 U 本位永续
+func (c *FundingRateCache) Get(ctx context.Context, symbol string, marketType MarketType) (*GetFundingRateResponse, error) {
+	if rate, ok := c.get(fundingRateKey(marketType, symbol)); ok {
+		return rate, nil
+	}
+	rates, err := c.ex.GetFundingRate(ctx, &GetFundingRate{Symbol: symbol, MarketType: marketType})
+	if err != nil {
+		return nil, err
+	}
+	c.store(marketType, rates)
+	for _, v := range rates {
+		if v != nil && v.Symbol == symbol {
+			rate := *v
+			return &rate, nil
+		}
+	}
+	return nil, ErrInvalidSymbol
+}
+
+// All 获取该市场类型全部交易对的资金费率，总是从交易所获取并刷新缓存
+func (c *FundingRateCache) All(ctx context.Context, marketType MarketType) ([]*GetFundingRateResponse, error) {
+	rates, err := c.ex.GetFundingRate(ctx, &GetFundingRate{MarketType: marketType})
+	if err != nil {
+		return nil, err
+	}
+	c.store(marketType, rates)
+	return rates, nil
+}
+
+// Update 使用资金费率推送更新缓存，推送的是预测资金费率，只更新 NextFundingRate、NextFundingTime 和标记、指数价格，
+// LastFundingRate 保留上一次结算的资金费率
+func (c *FundingRateCache) Update(evt *FundingRateEvent) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	key := fundingRateKey(evt.MarketType, evt.Symbol)
+	cached, ok := c.rates[key]
+	if !ok {
+		cached = &cachedFundingRate{rate: GetFundingRateResponse{Symbol: evt.Symbol}}
+		c.rates[key] = cached
+	}
+	rate := &cached.rate
+	if !evt.MarkPrice.IsZero() {
+		rate.MarkPrice = evt.MarkPrice
+	}
+	if !evt.IndexPrice.IsZero() {
+		rate.IndexPrice = evt.IndexPrice
+	}
+	rate.NextFundingRate = evt.FundingRate
+	rate.NextFundingTime = evt.NextFundingTime
+	cached.updatedAt = time.Now()
+}
+
+func (c *FundingRateCache) get(key string) (*GetFundingRateResponse, bool) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	cached, ok := c.rates[key]
+	if !ok {
+		return nil, false
+	}
+	now := time.Now()
+	if now.Sub(cached.updatedAt) > c.ttl {
+		return nil, false
+	}
+	if cached.rate.NextFundingTime > 0 && now.UnixMilli() >= cached.rate.NextFundingTime {
+		return nil, false
+	}
+	rate := cached.rate
+	return &rate, true
+}
+
+func (c *FundingRateCache) store(marketType MarketType, rates []*GetFundingRateResponse) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	now := time.Now()
+	for _, v := range rates {
+		if v == nil {
+			continue
+		}
+		c.rates[fundingRateKey(marketType, v.Symbol)] = &cachedFundingRate{rate: *v, updatedAt: now}
+	}
+}
+
+// fundingRateKey U 本位和币本位合约的交易对可能重名，缓存按市场类型区分
+func fundingRateKey(marketType MarketType, symbol string) string {
+	if marketType == "" {
+		marketType = MarketTypePerpetualUSDMargined
+	}
+	return string(marketType) + ":" + symbol
+}
@@ -0,0 +1,66 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type fundingRateExchange struct {
+	Exchange
+	calls       int
+	marketTypes []MarketType
+}
+
+func (e *fundingRateExchange) GetFundingRate(ctx context.Context, req *GetFundingRate) ([]*GetFundingRateResponse, error) {
+	e.calls++
+	e.marketTypes = append(e.marketTypes, req.MarketType)
+	return []*GetFundingRateResponse{{
+		Symbol:          "BTCUSDT",
+		LastFundingRate: decimal.RequireFromString("0.0003"),
+		NextFundingRate: decimal.RequireFromString("0.0001"),
+		NextFundingTime: time.Now().Add(time.Hour).UnixMilli(),
+	}}, nil
+}
+
+func TestFundingRateCache(t *testing.T) {
+	ex := &fundingRateExchange{}
+	cache := NewFundingRateCache(ex, time.Minute)
+
+	rate, err := cache.Get(context.Background(), "BTCUSDT", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "0.0001", rate.NextFundingRate.String())
+	_, err = cache.Get(context.Background(), "BTCUSDT", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, ex.calls)
+
+	// 推送更新缓存后不再请求接口
+	next := time.Now().Add(2 * time.Hour).UnixMilli()
+	cache.Update(&FundingRateEvent{
+		Symbol:          "BTCUSDT",
+		FundingRate:     decimal.RequireFromString("-0.0002"),
+		NextFundingTime: next,
+	})
+	rate, err = cache.Get(context.Background(), "BTCUSDT", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "-0.0002", rate.NextFundingRate.String())
+	// 推送不覆盖上一次结算的资金费率
+	assert.Equal(t, "0.0003", rate.LastFundingRate.String())
+	assert.Equal(t, next, rate.NextFundingTime)
+	assert.Equal(t, 1, ex.calls)
+
+	_, err = cache.Get(context.Background(), "ETHUSDT", "")
+	assert.ErrorIs(t, err, ErrInvalidSymbol)
+
+	// 币本位合约单独缓存，请求时带上市场类型
+	_, err = cache.Get(context.Background(), "BTCUSDT", MarketTypePerpetualCoinMargined)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, ex.calls)
+	assert.Equal(t, MarketTypePerpetualCoinMargined, ex.marketTypes[2])
+	_, err = cache.Get(context.Background(), "BTCUSDT", MarketTypePerpetualUSDMargined)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, ex.calls)
+}
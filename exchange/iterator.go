@@ -0,0 +1,59 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIteratorDone 迭代器数据已全部返回
+var ErrIteratorDone = errors.New("iterator done")
+
+// Iterator 分页迭代器，每次 Next 返回一页数据，数据取完后返回 ErrIteratorDone
+type Iterator[T any] interface {
+	Next(ctx context.Context) ([]T, error)
+}
+
+// PageFunc 获取一页数据，done 为 true 表示之后没有更多数据
+type PageFunc[T any] func(ctx context.Context) (items []T, done bool, err error)
+
+// NewIterator 使用分页函数创建迭代器，空页会自动跳过
+func NewIterator[T any](fn PageFunc[T]) Iterator[T] {
+	return &pageIterator[T]{fn: fn}
+}
+
+// Collect 读取迭代器的全部数据
+func Collect[T any](ctx context.Context, it Iterator[T]) ([]T, error) {
+	result := make([]T, 0)
+	for {
+		items, err := it.Next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		result = append(result, items...)
+	}
+}
+
+type pageIterator[T any] struct {
+	fn   PageFunc[T]
+	done bool
+}
+
+func (p *pageIterator[T]) Next(ctx context.Context) ([]T, error) {
+	for !p.done {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		items, done, err := p.fn(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.done = done
+		if len(items) > 0 {
+			return items, nil
+		}
+	}
+	return nil, ErrIteratorDone
+}
@@ -0,0 +1,124 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-gotop/kit/limiter"
+)
+
+// klineLimiterWait 超过限频时的等待间隔
+const klineLimiterWait = 200 * time.Millisecond
+
+// KlinePageFunc 获取开盘时间在 [start, end] 内的一页K线，返回顺序不限；
+// 数量超过交易所单页上限时应返回较早的K线
+type KlinePageFunc func(ctx context.Context, start, end int64) ([]GetKlineResponse, error)
+
+// NewKlineIterator 按时间窗口分页获取K线，每个窗口最多 pageLimit 根；
+// 返回的K线按开盘时间升序并去除窗口边界的重复K线
+func NewKlineIterator(req *GetKlineHistoryRequest, pageLimit int, fetch KlinePageFunc) Iterator[GetKlineResponse] {
+	period, err := ParseKlinePeriod(req.Period)
+	if err != nil {
+		return NewIterator(func(ctx context.Context) ([]GetKlineResponse, bool, error) {
+			return nil, false, err
+		})
+	}
+	end := req.EndTime
+	if end <= 0 {
+		end = time.Now().UnixMilli()
+	}
+	cursor := req.StartTime
+	if cursor <= 0 {
+		cursor = end - period.Milliseconds()*int64(pageLimit) + 1
+	}
+	window := period.Milliseconds() * int64(pageLimit)
+	lastOpenTime := int64(-1)
+	return NewIterator(func(ctx context.Context) ([]GetKlineResponse, bool, error) {
+		if cursor > end {
+			return nil, true, nil
+		}
+		if err := waitKlineLimiter(ctx, req); err != nil {
+			return nil, false, err
+		}
+		windowEnd := min(cursor+window-1, end)
+		items, err := fetch(ctx, cursor, windowEnd)
+		if err != nil {
+			return nil, false, err
+		}
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].OpenTime < items[j].OpenTime
+		})
+		result := make([]GetKlineResponse, 0, len(items))
+		for _, v := range items {
+			if v.OpenTime <= lastOpenTime || v.OpenTime < req.StartTime || v.OpenTime > end {
+				continue
+			}
+			result = append(result, v)
+			lastOpenTime = v.OpenTime
+		}
+		// 交易所单页返回数量可能小于窗口大小，从最后一根K线之后继续获取
+		if len(result) > 0 && lastOpenTime+period.Milliseconds() <= windowEnd {
+			cursor = lastOpenTime + 1
+		} else {
+			cursor = windowEnd + 1
+		}
+		return result, cursor > end, nil
+	})
+}
+
+// ParseKlinePeriod 解析K线周期，支持 1m/1h/1d/1w/1M 及 okx 的大写格式 1H/1D/1W，月按 31 天计算
+func ParseKlinePeriod(period string) (time.Duration, error) {
+	p := strings.TrimSuffix(period, "utc")
+	if len(p) < 2 {
+		return 0, errors.New("invalid kline period")
+	}
+	n, err := strconv.Atoi(p[:len(p)-1])
+	if err != nil || n <= 0 {
+		return 0, errors.New("invalid kline period")
+	}
+	var unit time.Duration
+	switch p[len(p)-1] {
+	case 's':
+		unit = time.Second
+	case 'm':
+		unit = time.Minute
+	case 'h', 'H':
+		unit = time.Hour
+	case 'd', 'D':
+		unit = 24 * time.Hour
+	case 'w', 'W':
+		unit = 7 * 24 * time.Hour
+	case 'M':
+		unit = 31 * 24 * time.Hour
+	default:
+		return 0, errors.New("invalid kline period")
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// waitKlineLimiter 按市场类型检查普通请求限频，超限时等待直到允许或 ctx 结束
+func waitKlineLimiter(ctx context.Context, req *GetKlineHistoryRequest) error {
+	if req.Limiter == nil {
+		return nil
+	}
+	allow := req.Limiter.FutureAllow
+	switch req.MarketType {
+	case MarketTypeSpot:
+		allow = req.Limiter.SpotAllow
+	case MarketTypeMargin:
+		allow = req.Limiter.MarginAllow
+	}
+	lr := &limiter.LimiterReq{LimiterType: limiter.NormalRequestLimit}
+	for !allow(lr) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(klineLimiterWait):
+		}
+	}
+	return nil
+}
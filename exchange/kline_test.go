@@ -0,0 +1,39 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKlineIterator(t *testing.T) {
+	minute := time.Minute.Milliseconds()
+	base := int64(1700000000000) - int64(1700000000000)%minute
+	start, end := base, base+250*minute-1
+	calls := 0
+	it := NewKlineIterator(&GetKlineHistoryRequest{Period: "1m", StartTime: start, EndTime: end}, 100,
+		func(ctx context.Context, s, e int64) ([]GetKlineResponse, error) {
+			calls++
+			// 包含上一页的边界K线，单页最多 60 根，倒序返回
+			items := make([]GetKlineResponse, 0)
+			for ts := max(s-s%minute-minute, base); ts <= e && len(items) < 60; ts += minute {
+				items = append([]GetKlineResponse{{OpenTime: ts}}, items...)
+			}
+			return items, nil
+		})
+	klines, err := Collect(context.Background(), it)
+	assert.Nil(t, err)
+	assert.Len(t, klines, 250)
+	for i, k := range klines {
+		assert.Equal(t, base+int64(i)*minute, k.OpenTime)
+	}
+	assert.Greater(t, calls, 3)
+
+	d, err := ParseKlinePeriod("4H")
+	assert.Nil(t, err)
+	assert.Equal(t, 4*time.Hour, d)
+	_, err = ParseKlinePeriod("1x")
+	assert.NotNil(t, err)
+}
@@ -123,7 +123,7 @@ func (m *mockExchange) GetAccountConfig(ctx context.Context, req *exchange.GetAc
 	return exchange.GetAccountConfigResponse{}, errors.New("not implemented")
 }
 
-func (m *mockExchange) CreateOrder(ctx context.Context, o *exchange.CreateOrderRequest) error {
+func (m *mockExchange) CreateOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
 	r := &mohttp.Request{
 		Method:    http.MethodPost,
 		Endpoint:  "/api/exchange/order",
@@ -147,14 +147,37 @@ func (m *mockExchange) CreateOrder(ctx context.Context, o *exchange.CreateOrderR
 	r = r.SetFormParams(params)
 	data, err := m.client.CallAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	res := &mockCreateOrderResponse{}
 	err = mohttp.Json.Unmarshal(data, res)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return nil
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		price = decimal.Zero
+	}
+	origQuantity, err := decimal.NewFromString(res.OriginalQuantity)
+	if err != nil {
+		origQuantity = decimal.Zero
+	}
+	executedQuantity, err := decimal.NewFromString(res.ExecutedQuantity)
+	if err != nil {
+		executedQuantity = decimal.Zero
+	}
+	return &exchange.CreateOrderResponse{
+		TransactTime:     res.TransactTime,
+		Symbol:           res.Symbol,
+		ClientOrderID:    res.ClientOrderID,
+		OrderID:          res.OrderID,
+		Side:             exchange.SideType(res.Side),
+		State:            exchange.OrderState(res.State),
+		PositionSide:     exchange.PositionSide(res.PositionSide),
+		Price:            price,
+		OriginalQuantity: origQuantity,
+		ExecutedQuantity: executedQuantity,
+	}, nil
 }
 
 func (m *mockExchange) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceKlineRequest) ([]exchange.GetMarkPriceKlineResponse, error) {
@@ -189,6 +212,90 @@ func (m *mockExchange) CancelOrder(ctx context.Context, o *exchange.CancelOrderR
 	return nil
 }
 
+func (m *mockExchange) BatchCreateOrders(ctx context.Context, o []*exchange.CreateOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) BatchCancelOrders(ctx context.Context, o []*exchange.CancelOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) GetOpenOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest) ([]*exchange.SearchOrderResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) CancelAllOrders(ctx context.Context, req *exchange.CancelAllOrdersRequest) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockExchange) OrderHistory(req *exchange.GetOrderHistoryRequest) exchange.Iterator[*exchange.SearchOrderResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.SearchOrderResponse, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) TradeHistory(req *exchange.GetTradeHistoryRequest) exchange.Iterator[*exchange.SearchTradesResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.SearchTradesResponse, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) KlineHistory(req *exchange.GetKlineHistoryRequest) exchange.Iterator[exchange.GetKlineResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]exchange.GetKlineResponse, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) FundingRateHistory(req *exchange.GetFundingRateHistoryRequest) exchange.Iterator[*exchange.FundingRate] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.FundingRate, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) Ledger(req *exchange.GetLedgerRequest) exchange.Iterator[*exchange.LedgerEntry] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.LedgerEntry, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) ClosedPositions(req *exchange.GetClosedPositionsRequest) exchange.Iterator[*exchange.ClosedPosition] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.ClosedPosition, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (m *mockExchange) Symbols(ctx context.Context, marketType exchange.MarketType) ([]exchange.Symbol, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) AmendOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) SetPositionMode(ctx context.Context, req *exchange.SetPositionModeRequest) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockExchange) SetMarginMode(ctx context.Context, req *exchange.SetMarginModeRequest) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockExchange) AdjustPositionMargin(ctx context.Context, req *exchange.AdjustPositionMarginRequest) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockExchange) CreateOcoOrder(ctx context.Context, req *exchange.CreateOcoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockExchange) CancelAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) error {
+	return errors.New("not implemented")
+}
+
+func (m *mockExchange) SearchAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *mockExchange) SearchOrder(ctx context.Context, o *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
 	return nil, nil
 }
@@ -0,0 +1,273 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrInvalidOrderPrice 价格小于等于零
+	ErrInvalidOrderPrice = errors.New("invalid order price")
+	// ErrInvalidOrderSize 数量小于等于零，或按精度取整后为零
+	ErrInvalidOrderSize = errors.New("invalid order size")
+	// ErrOrderPriceTooLow 价格低于最小价格
+	ErrOrderPriceTooLow = errors.New("order price too low")
+	// ErrOrderPriceTooHigh 价格高于最大价格
+	ErrOrderPriceTooHigh = errors.New("order price too high")
+	// ErrOrderSizeTooSmall 数量低于最小头寸
+	ErrOrderSizeTooSmall = errors.New("order size too small")
+	// ErrOrderSizeTooLarge 数量高于最大头寸
+	ErrOrderSizeTooLarge = errors.New("order size too large")
+	// ErrOrderNotionalTooSmall 名义价值低于最小名义价值
+	ErrOrderNotionalTooSmall = errors.New("order notional too small")
+)
+
+// OrderValidationError 订单未通过交易对过滤规则，Err 为上面的校验错误之一，可用 errors.Is 判断
+type OrderValidationError struct {
+	Symbol string
+	Field  string // price, size, notional
+	Value  decimal.Decimal
+	Limit  decimal.Decimal
+	Err    error
+}
+
+func (e *OrderValidationError) Error() string {
+	if e.Limit.IsZero() {
+		return fmt.Sprintf("%s: %v, %s: %s", e.Symbol, e.Err, e.Field, e.Value)
+	}
+	return fmt.Sprintf("%s: %v, %s: %s, limit: %s", e.Symbol, e.Err, e.Field, e.Value, e.Limit)
+}
+
+func (e *OrderValidationError) Unwrap() error {
+	return e.Err
+}
+
+type normalizeOptions struct {
+	referencePrice decimal.Decimal
+}
+
+type NormalizeOption func(*normalizeOptions)
+
+// WithReferencePrice 市价单用于校验最小名义价值的参考价格，不设置时市价单跳过名义价值校验
+func WithReferencePrice(price decimal.Decimal) NormalizeOption {
+	return func(o *normalizeOptions) {
+		o.referencePrice = price
+	}
+}
+
+// NormalizeOrder 按交易对过滤规则处理订单，返回处理后的副本，不修改原请求
+// 价格按 TickSize 的整数倍取整（买单向下、卖单向上，不会比原价更激进），数量按 StepSize 的整数倍向下取整，
+// 未加载步长时按 PricePrecision、SizePrecision 的小数位数取整，
+// 超出 MinSize/MaxSize、MinPrice/MaxPrice、MinNotional 的订单返回 *OrderValidationError。
+// 交易对 CtVal 不为零时（okx 合约），数量限制以张为单位，会先将 Size 换算为张再校验；币本位合约的 Size 本身以张为单位。
+// 交易对未加载任何过滤规则时原样返回。
+func NormalizeOrder(req *CreateOrderRequest, opts ...NormalizeOption) (*CreateOrderRequest, error) {
+	o := &normalizeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	normalized := *req
+	if !hasFilters(req.Symbol) {
+		return &normalized, nil
+	}
+	var err error
+	if normalized.Price, err = normalizePrice(req.Symbol, req.Side, req.Price); err != nil {
+		return nil, err
+	}
+	// 触发价格和激活价格同样按价格精度处理
+	if normalized.StopPrice, err = normalizePrice(req.Symbol, req.Side, req.StopPrice); err != nil {
+		return nil, err
+	}
+	if normalized.ActivationPrice, err = normalizePrice(req.Symbol, req.Side, req.ActivationPrice); err != nil {
+		return nil, err
+	}
+	// 全部平仓的条件单不需要数量
+	if req.ClosePosition && req.Size.IsZero() {
+		return &normalized, nil
+	}
+	if normalized.Size, err = normalizeSize(req.Symbol, req.Size); err != nil {
+		return nil, err
+	}
+	price := normalized.Price
+	if price.IsZero() {
+		price = normalized.StopPrice
+	}
+	if price.IsZero() {
+		price = o.referencePrice
+	}
+	if err := checkNotional(req.Symbol, price, normalized.Size); err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// NormalizeAmendOrder 按交易对过滤规则处理改单请求，NewSize、NewPrice 为零时不处理对应字段
+func NormalizeAmendOrder(req *AmendOrderRequest) (*AmendOrderRequest, error) {
+	normalized := *req
+	if !hasFilters(req.Symbol) {
+		return &normalized, nil
+	}
+	var err error
+	if normalized.NewPrice, err = normalizePrice(req.Symbol, req.Side, req.NewPrice); err != nil {
+		return nil, err
+	}
+	if !req.NewSize.IsZero() {
+		if normalized.NewSize, err = normalizeSize(req.Symbol, req.NewSize); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkNotional(req.Symbol, normalized.NewPrice, normalized.NewSize); err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// NewNormalizedExchange 包装交易所，下单、批量下单、改单前自动调用 NormalizeOrder
+// 批量下单中未通过校验的订单不会提交，错误写入对应的 BatchOrderResult
+func NewNormalizedExchange(ex Exchange) Exchange {
+	return &normalizedExchange{Exchange: ex}
+}
+
+type normalizedExchange struct {
+	Exchange
+}
+
+func (n *normalizedExchange) CreateOrder(ctx context.Context, req *CreateOrderRequest) (*CreateOrderResponse, error) {
+	normalized, err := NormalizeOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	return n.Exchange.CreateOrder(ctx, normalized)
+}
+
+func (n *normalizedExchange) AmendOrder(ctx context.Context, req *AmendOrderRequest) (*AmendOrderResponse, error) {
+	normalized, err := NormalizeAmendOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	return n.Exchange.AmendOrder(ctx, normalized)
+}
+
+func (n *normalizedExchange) BatchCreateOrders(ctx context.Context, reqs []*CreateOrderRequest) ([]*BatchOrderResult, error) {
+	result := make([]*BatchOrderResult, len(reqs))
+	valid := make([]*CreateOrderRequest, 0, len(reqs))
+	index := make([]int, 0, len(reqs))
+	for i, req := range reqs {
+		normalized, err := NormalizeOrder(req)
+		if err != nil {
+			result[i] = &BatchOrderResult{ClientOrderID: req.ClientOrderID, Err: err}
+			continue
+		}
+		valid = append(valid, normalized)
+		index = append(index, i)
+	}
+	if len(valid) == 0 {
+		return result, nil
+	}
+	submitted, err := n.Exchange.BatchCreateOrders(ctx, valid)
+	for j, res := range submitted {
+		if j < len(index) {
+			result[index[j]] = res
+		}
+	}
+	// 交易所未返回结果的订单按整体错误处理
+	for j, i := range index {
+		if result[i] == nil {
+			result[i] = &BatchOrderResult{ClientOrderID: valid[j].ClientOrderID, Err: err}
+		}
+	}
+	return result, err
+}
+
+// normalizePrice 价格为零（市价单）时不处理
+func normalizePrice(symbol Symbol, side SideType, price decimal.Decimal) (decimal.Decimal, error) {
+	if price.IsZero() {
+		return price, nil
+	}
+	name := symbol.OriginalSymbol
+	if price.IsNegative() {
+		return price, &OrderValidationError{Symbol: name, Field: "price", Value: price, Err: ErrInvalidOrderPrice}
+	}
+	price = roundStep(price, symbol.TickSize, symbol.PricePrecision, side == SideTypeSell)
+	if price.IsZero() {
+		return price, &OrderValidationError{Symbol: name, Field: "price", Value: price, Err: ErrInvalidOrderPrice}
+	}
+	if !symbol.MinPrice.IsZero() && price.LessThan(symbol.MinPrice) {
+		return price, &OrderValidationError{Symbol: name, Field: "price", Value: price, Limit: symbol.MinPrice, Err: ErrOrderPriceTooLow}
+	}
+	if !symbol.MaxPrice.IsZero() && price.GreaterThan(symbol.MaxPrice) {
+		return price, &OrderValidationError{Symbol: name, Field: "price", Value: price, Limit: symbol.MaxPrice, Err: ErrOrderPriceTooHigh}
+	}
+	return price, nil
+}
+
+// normalizeSize CtVal 不为零时按张取整和校验，返回值仍为币的数量
+func normalizeSize(symbol Symbol, size decimal.Decimal) (decimal.Decimal, error) {
+	name := symbol.OriginalSymbol
+	if symbol.MarketType.IsCoinMargined() {
+		symbol.CtVal = decimal.Zero
+	}
+	if !size.IsPositive() {
+		return size, &OrderValidationError{Symbol: name, Field: "size", Value: size, Err: ErrInvalidOrderSize}
+	}
+	// 合约张数 = 合约数量 / 合约面值
+	contracts := size
+	if !symbol.CtVal.IsZero() {
+		contracts = size.Div(symbol.CtVal)
+	}
+	contracts = roundStep(contracts, symbol.StepSize, symbol.SizePrecision, false)
+	if contracts.IsZero() {
+		return size, &OrderValidationError{Symbol: name, Field: "size", Value: size, Err: ErrInvalidOrderSize}
+	}
+	if !symbol.MinSize.IsZero() && contracts.LessThan(symbol.MinSize) {
+		return size, &OrderValidationError{Symbol: name, Field: "size", Value: contracts, Limit: symbol.MinSize, Err: ErrOrderSizeTooSmall}
+	}
+	if !symbol.MaxSize.IsZero() && contracts.GreaterThan(symbol.MaxSize) {
+		return size, &OrderValidationError{Symbol: name, Field: "size", Value: contracts, Limit: symbol.MaxSize, Err: ErrOrderSizeTooLarge}
+	}
+	if !symbol.CtVal.IsZero() {
+		return contracts.Mul(symbol.CtVal), nil
+	}
+	return contracts, nil
+}
+
+// roundStep 按步长取整为 step 的整数倍，如 tickSize 为 0.05 时 100.03 向下取整为 100，step 为零时按小数位数取整
+func roundStep(x, step decimal.Decimal, precision int32, ceil bool) decimal.Decimal {
+	if !step.IsPositive() {
+		if ceil {
+			return x.RoundCeil(precision)
+		}
+		return x.RoundFloor(precision)
+	}
+	n := x.Div(step)
+	if ceil {
+		n = n.Ceil()
+	} else {
+		n = n.Floor()
+	}
+	return n.Mul(step)
+}
+
+// checkNotional 价格或数量为零时跳过
+func checkNotional(symbol Symbol, price, size decimal.Decimal) error {
+	if symbol.MinNotional.IsZero() || price.IsZero() || size.IsZero() {
+		return nil
+	}
+	notional := price.Mul(size)
+	if notional.LessThan(symbol.MinNotional) {
+		return &OrderValidationError{Symbol: symbol.OriginalSymbol, Field: "notional", Value: notional, Limit: symbol.MinNotional, Err: ErrOrderNotionalTooSmall}
+	}
+	return nil
+}
+
+// hasFilters 交易对是否加载了过滤规则，未加载时精度为零值不能用于取整
+func hasFilters(s Symbol) bool {
+	return s.PricePrecision != 0 || s.SizePrecision != 0 ||
+		!s.TickSize.IsZero() || !s.StepSize.IsZero() ||
+		!s.MinSize.IsZero() || !s.MaxSize.IsZero() ||
+		!s.MinPrice.IsZero() || !s.MaxPrice.IsZero() ||
+		!s.MinNotional.IsZero()
+}
@@ -0,0 +1,127 @@
+package exchange
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeOrder(t *testing.T) {
+	symbol := Symbol{
+		OriginalSymbol: "BTCUSDT",
+		MinSize:        decimal.RequireFromString("0.001"),
+		MaxSize:        decimal.RequireFromString("100"),
+		MinPrice:       decimal.RequireFromString("0.1"),
+		MaxPrice:       decimal.RequireFromString("1000000"),
+		MinNotional:    decimal.RequireFromString("5"),
+		PricePrecision: 1,
+		SizePrecision:  3,
+	}
+
+	req := &CreateOrderRequest{
+		Symbol: symbol,
+		Side:   SideTypeBuy,
+		Price:  decimal.RequireFromString("60000.19"),
+		Size:   decimal.RequireFromString("0.0129"),
+	}
+	normalized, err := NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "60000.1", normalized.Price.String())
+	assert.Equal(t, "0.012", normalized.Size.String())
+	// 原请求不变
+	assert.Equal(t, "0.0129", req.Size.String())
+
+	req.Side = SideTypeSell
+	normalized, err = NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "60000.2", normalized.Price.String())
+
+	req.Size = decimal.RequireFromString("0.0009")
+	_, err = NormalizeOrder(req)
+	assert.True(t, errors.Is(err, ErrInvalidOrderSize))
+
+	req.Size = decimal.RequireFromString("101")
+	_, err = NormalizeOrder(req)
+	var verr *OrderValidationError
+	assert.True(t, errors.As(err, &verr))
+	assert.Equal(t, ErrOrderSizeTooLarge, verr.Err)
+	assert.Equal(t, "100", verr.Limit.String())
+
+	// 市价单使用参考价格校验名义价值
+	req.Price = decimal.Zero
+	req.Size = decimal.RequireFromString("0.001")
+	_, err = NormalizeOrder(req)
+	assert.NoError(t, err)
+	_, err = NormalizeOrder(req, WithReferencePrice(decimal.NewFromInt(1000)))
+	assert.True(t, errors.Is(err, ErrOrderNotionalTooSmall))
+
+	// 交易对未加载过滤规则时原样返回
+	req.Symbol = Symbol{OriginalSymbol: "BTCUSDT"}
+	req.Size = decimal.RequireFromString("0.0129")
+	normalized, err = NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0129", normalized.Size.String())
+}
+
+func TestNormalizeOrderContract(t *testing.T) {
+	// okx 合约数量限制以张为单位
+	req := &CreateOrderRequest{
+		Symbol: Symbol{
+			OriginalSymbol: "BTC-USDT-SWAP",
+			MinSize:        decimal.RequireFromString("0.1"),
+			MaxSize:        decimal.RequireFromString("1000"),
+			SizePrecision:  1,
+			CtVal:          decimal.RequireFromString("0.01"),
+		},
+		Side: SideTypeBuy,
+		Size: decimal.RequireFromString("0.0159"),
+	}
+	normalized, err := NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.015", normalized.Size.String())
+
+	req.Size = decimal.RequireFromString("0.0009")
+	_, err = NormalizeOrder(req)
+	assert.True(t, errors.Is(err, ErrInvalidOrderSize))
+
+	req.Size = decimal.RequireFromString("11")
+	_, err = NormalizeOrder(req)
+	assert.True(t, errors.Is(err, ErrOrderSizeTooLarge))
+}
+
+func TestNormalizeOrderStep(t *testing.T) {
+	// 步长不是 10 的幂时按步长的整数倍取整
+	req := &CreateOrderRequest{
+		Symbol: Symbol{
+			OriginalSymbol: "XYZUSDT",
+			PricePrecision: 2,
+			SizePrecision:  0,
+			TickSize:       decimal.RequireFromString("0.05"),
+			StepSize:       decimal.RequireFromString("10"),
+		},
+		Side:  SideTypeBuy,
+		Price: decimal.RequireFromString("100.03"),
+		Size:  decimal.RequireFromString("129"),
+	}
+	normalized, err := NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "100", normalized.Price.String())
+	assert.Equal(t, "120", normalized.Size.String())
+
+	req.Side = SideTypeSell
+	normalized, err = NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "100.05", normalized.Price.String())
+
+	req.Symbol.TickSize = decimal.RequireFromString("0.5")
+	req.Price = decimal.RequireFromString("100.2")
+	normalized, err = NormalizeOrder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "100.5", normalized.Price.String())
+
+	req.Size = decimal.RequireFromString("9")
+	_, err = NormalizeOrder(req)
+	assert.True(t, errors.Is(err, ErrInvalidOrderSize))
+}
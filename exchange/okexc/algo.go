@@ -0,0 +1,435 @@
+package okexc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/shopspring/decimal"
+)
+
+const okCancelAlgosLimit = 10 // 批量撤销策略委托每次最多订单数
+
+// okPendingAlgoOrdTypes 查询未触发策略委托时必须指定 ordType，只有 conditional 和 oco 可以一起查询
+var okPendingAlgoOrdTypes = []string{"conditional,oco", "trigger", "move_order_stop"}
+
+// isOkAlgoOrder 条件单通过策略委托接口下单
+func isOkAlgoOrder(orderType exchange.OrderType) bool {
+	switch orderType {
+	case exchange.OrderTypeStop, exchange.OrderTypeStopMarket,
+		exchange.OrderTypeTakeProfit, exchange.OrderTypeTakeProfitMarket,
+		exchange.OrderTypeTrailingStopMarket:
+		return true
+	}
+	return false
+}
+
+// createAlgoOrder 止损止盈使用计划委托 trigger，跟踪止损使用 move_order_stop，返回的订单号为 algoId
+func (o *okx) createAlgoOrder(ctx context.Context, req *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	params, err := o.toAlgoOrderParams(req)
+	if err != nil {
+		return nil, err
+	}
+	switch req.OrderType {
+	case exchange.OrderTypeTrailingStopMarket:
+		if !req.CallbackRate.IsPositive() {
+			return nil, errors.New("callback rate is required")
+		}
+		params["ordType"] = "move_order_stop"
+		params["callbackRatio"] = req.CallbackRate.String()
+		if !req.ActivationPrice.IsZero() {
+			params["activePx"] = req.ActivationPrice.String()
+		}
+	default:
+		triggerPx := req.StopPrice
+		if triggerPx.IsZero() {
+			triggerPx = req.Price
+		}
+		if req.ClosePosition {
+			// 全部平仓只支持止盈止损委托 conditional，触发后市价平仓
+			prefix := "sl"
+			if req.OrderType == exchange.OrderTypeTakeProfitMarket {
+				prefix = "tp"
+			}
+			params["ordType"] = "conditional"
+			params[prefix+"TriggerPx"] = triggerPx.String()
+			params[prefix+"OrdPx"] = "-1"
+			params["closeFraction"] = "1"
+			delete(params, "sz")
+			if _, ok := params["posSide"]; !ok {
+				params["reduceOnly"] = true
+			}
+			break
+		}
+		params["ordType"] = "trigger"
+		params["triggerPx"] = triggerPx.String()
+		params["orderPx"] = "-1"
+		if req.OrderType == exchange.OrderTypeStop || req.OrderType == exchange.OrderTypeTakeProfit {
+			params["orderPx"] = req.Price.String()
+		}
+	}
+
+	algoId, algoClOrdId, err := o.placeAlgoOrder(ctx, req.APIKey, req.SecretKey, req.Passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.CreateOrderResponse{
+		Symbol:           req.Symbol.OriginalSymbol,
+		ClientOrderID:    algoClOrdId,
+		OrderID:          algoId,
+		Side:             req.Side,
+		State:            exchange.OrderStateNew,
+		PositionSide:     req.PositionSide,
+		Price:            req.Price,
+		OriginalQuantity: req.Size,
+	}, nil
+}
+
+// CreateOcoOrder 使用策略委托 oco 下单，止盈止损触发后按委托价格下单，委托价格为零时市价成交
+func (o *okx) CreateOcoOrder(ctx context.Context, req *exchange.CreateOcoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	params, err := o.toAlgoOrderParams(&exchange.CreateOrderRequest{
+		Symbol:        req.Symbol,
+		ClientOrderID: req.ClientOrderID,
+		Side:          req.Side,
+		OrderType:     exchange.OrderTypeMarket,
+		PositionSide:  req.PositionSide,
+		MarketType:    req.MarketType,
+		Size:          req.Size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	params["ordType"] = "oco"
+	// okx 止盈腿必须有触发价格，未设置时以委托价格触发
+	tpTriggerPx := req.TakeProfit.TriggerPrice
+	if tpTriggerPx.IsZero() {
+		tpTriggerPx = req.TakeProfit.Price
+	}
+	params["tpTriggerPx"] = tpTriggerPx.String()
+	params["tpOrdPx"] = okAlgoOrderPx(req.TakeProfit.Price)
+	params["slTriggerPx"] = req.StopLoss.TriggerPrice.String()
+	params["slOrdPx"] = okAlgoOrderPx(req.StopLoss.Price)
+
+	algoId, algoClOrdId, err := o.placeAlgoOrder(ctx, req.APIKey, req.SecretKey, req.Passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.AlgoOrderResponse{
+		OrderID:       algoId,
+		ClientOrderID: algoClOrdId,
+		Symbol:        req.Symbol.OriginalSymbol,
+		OrderType:     exchange.OrderTypeOco,
+		State:         exchange.OrderStateNew,
+		Side:          req.Side,
+		PositionSide:  req.PositionSide,
+		Size:          req.Size,
+	}, nil
+}
+
+func (o *okx) CancelAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) error {
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/trade/cancel-algos",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	params := okhttp.Params{
+		"instId": req.Symbol.OriginalSymbol,
+	}
+	if req.OrderID != "" {
+		params["algoId"] = req.OrderID
+	} else {
+		params["algoClOrdId"] = req.ClientOrderID
+	}
+	r = r.SetJSONBody([]okhttp.Params{params})
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var response AlgoOrderActionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("error parsing response data: %v", err)
+	}
+	if len(response.Data) > 0 && response.Data[0].SCode != "0" {
+		return okError(response.Data[0].SCode, response.Data[0].SMsg)
+	}
+	if response.Code != "0" {
+		return okError(response.Code, response.Msg)
+	}
+	return nil
+}
+
+// SearchAlgoOrder 查询策略委托，合约数量由张转换为币
+func (o *okx) SearchAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "GET",
+		Endpoint:   "/api/v5/trade/order-algo",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	params := okhttp.Params{}
+	if req.OrderID != "" {
+		params["algoId"] = req.OrderID
+	} else {
+		params["algoClOrdId"] = req.ClientOrderID
+	}
+	r.SetParams(params)
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response AlgoOrdersResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return nil, exchange.ErrOrderNotFound
+	}
+	info := response.Data[0]
+	ctVal := req.Symbol.CtVal
+	if ctVal.IsZero() {
+		cache := &okCtValCache{o: o}
+		if ctVal, err = cache.get(ctx, info.InstType, info.InstID); err != nil {
+			return nil, err
+		}
+	}
+	return toAlgoOrderResponse(&info, ctVal)
+}
+
+// getPendingAlgoOrders 按策略类型分页查询未触发的策略委托，与 getPendingOrders 一样只保留与市场类型一致的合约
+func (o *okx) getPendingAlgoOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest, instType string) ([]AlgoOrderInfo, error) {
+	result := make([]AlgoOrderInfo, 0)
+	for _, ordType := range okPendingAlgoOrdTypes {
+		after := ""
+		for {
+			r := &okhttp.Request{
+				APIKey:     req.APIKey,
+				SecretKey:  req.SecretKey,
+				Passphrase: req.Passphrase,
+				Method:     "GET",
+				Endpoint:   "/api/v5/trade/orders-algo-pending",
+				SecType:    okhttp.SecTypeSigned,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			params := okhttp.Params{
+				"ordType":  ordType,
+				"instType": instType,
+				"limit":    okOrdersPageLimit,
+			}
+			if req.Symbol != "" {
+				params["instId"] = req.Symbol
+			}
+			if after != "" {
+				params["after"] = after
+			}
+			r.SetParams(params)
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, err
+			}
+
+			var response AlgoOrdersResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, okError(response.Code, response.Msg)
+			}
+			for _, v := range response.Data {
+				if okMatchMarketType(v.InstID, req.MarketType) {
+					result = append(result, v)
+				}
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				break
+			}
+			after = response.Data[len(response.Data)-1].AlgoID
+		}
+	}
+	return result, nil
+}
+
+// cancelAlgoOrders 通过 cancel-algos 批量撤销策略委托，每次最多 10 笔
+func (o *okx) cancelAlgoOrders(ctx context.Context, apiKey, secretKey, passphrase string, orders []AlgoOrderInfo) error {
+	errs := make([]error, 0)
+	for i := 0; i < len(orders); i += okCancelAlgosLimit {
+		chunk := orders[i:min(i+okCancelAlgosLimit, len(orders))]
+		params := make([]okhttp.Params, 0, len(chunk))
+		for _, v := range chunk {
+			params = append(params, okhttp.Params{
+				"instId": v.InstID,
+				"algoId": v.AlgoID,
+			})
+		}
+		r := &okhttp.Request{
+			APIKey:     apiKey,
+			SecretKey:  secretKey,
+			Passphrase: passphrase,
+			Method:     "POST",
+			Endpoint:   "/api/v5/trade/cancel-algos",
+			SecType:    okhttp.SecTypeSigned,
+		}
+		o.client.SetApiEndpoint(okEndpoint)
+		r = r.SetJSONBody(params)
+		data, err := o.callAPI(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		var response AlgoOrderActionResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return fmt.Errorf("error parsing response data: %v", err)
+		}
+		if len(response.Data) == 0 && response.Code != "0" {
+			return okError(response.Code, response.Msg)
+		}
+		for j, v := range response.Data {
+			if v.SCode != "0" {
+				algoId := v.AlgoId
+				if algoId == "" && j < len(chunk) {
+					algoId = chunk[j].AlgoID
+				}
+				errs = append(errs, fmt.Errorf("cancel algo order %s failed: %w", algoId, okError(v.SCode, v.SMsg)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (o *okx) placeAlgoOrder(ctx context.Context, apiKey, secretKey, passphrase string, params okhttp.Params) (string, string, error) {
+	r := &okhttp.Request{
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		Passphrase: passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/trade/order-algo",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	r = r.SetJSONBody(params)
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return "", "", err
+	}
+
+	var response AlgoOrderActionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", "", fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" || len(response.Data) == 0 || response.Data[0].SCode != "0" {
+		msg := response.Msg
+		code := response.Code
+		if len(response.Data) > 0 {
+			msg = response.Data[0].SMsg
+			code = response.Data[0].SCode
+		}
+		return "", "", okError(code, msg)
+	}
+	return response.Data[0].AlgoId, response.Data[0].AlgoClOrdId, nil
+}
+
+// toAlgoOrderParams 在普通下单参数基础上去掉委托价格，客户端订单号改为 algoClOrdId
+func (o *okx) toAlgoOrderParams(req *exchange.CreateOrderRequest) (okhttp.Params, error) {
+	params, err := o.toOrderParams(req)
+	if err != nil {
+		return nil, err
+	}
+	delete(params, "px")
+	delete(params, "clOrdId")
+	delete(params, "stpMode")
+	if req.ClientOrderID != "" {
+		params["algoClOrdId"] = req.ClientOrderID
+	}
+	return params, nil
+}
+
+// okAlgoOrderPx 委托价格为 -1 表示市价
+func okAlgoOrderPx(price decimal.Decimal) string {
+	if price.IsZero() {
+		return "-1"
+	}
+	return price.String()
+}
+
+func toAlgoOrderResponse(info *AlgoOrderInfo, ctVal decimal.Decimal) (*exchange.AlgoOrderResponse, error) {
+	size, err := decimal.NewFromString(info.Sz)
+	if err != nil {
+		return nil, err
+	}
+	if !ctVal.IsZero() && !okIsInverse(info.InstID) {
+		size = size.Mul(ctVal)
+	}
+	createdTime, err := strconv.ParseInt(info.CTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	updateTime, err := strconv.ParseInt(info.UTime, 10, 64)
+	if err != nil {
+		updateTime = createdTime
+	}
+	ordIds := info.OrdIdList
+	if len(ordIds) == 0 && info.OrdID != "" && info.OrdID != "0" {
+		ordIds = []string{info.OrdID}
+	}
+	orders := make([]*exchange.SearchOrderResponse, 0, len(ordIds))
+	for _, id := range ordIds {
+		orders = append(orders, &exchange.SearchOrderResponse{
+			OrderID: id,
+			Symbol:  info.InstID,
+		})
+	}
+	return &exchange.AlgoOrderResponse{
+		OrderID:       info.AlgoID,
+		ClientOrderID: info.AlgoClOrdID,
+		Symbol:        info.InstID,
+		OrderType:     OkxTAlgoOrderType(info.OrdType, info.OrdPx),
+		State:         OkxTAlgoOrderState(info.State),
+		Side:          OkxTSide(info.Side),
+		PositionSide:  OkxTPositionSide(info.PosSide),
+		Size:          size,
+		Orders:        orders,
+		CreatedTime:   createdTime,
+		UpdateTime:    updateTime,
+	}, nil
+}
+
+// toAlgoSearchOrderResponse 未触发的策略委托转为挂单，订单号为 algoId
+func toAlgoSearchOrderResponse(info *AlgoOrderInfo, ctVal decimal.Decimal) (*exchange.SearchOrderResponse, error) {
+	algo, err := toAlgoOrderResponse(info, ctVal)
+	if err != nil {
+		return nil, err
+	}
+	price, err := decimal.NewFromString(info.OrdPx)
+	if err != nil || price.IsNegative() {
+		// 委托价格 -1 表示触发后市价下单
+		price = decimal.Zero
+	}
+	return &exchange.SearchOrderResponse{
+		OrderID:       algo.OrderID,
+		ClientOrderID: algo.ClientOrderID,
+		State:         algo.State,
+		Symbol:        algo.Symbol,
+		Volume:        algo.Size,
+		Price:         price,
+		Side:          algo.Side,
+		PositionSide:  algo.PositionSide,
+		OrderType:     algo.OrderType,
+		CreatedTime:   algo.CreatedTime,
+		UpdateTime:    algo.UpdateTime,
+	}, nil
+}
@@ -21,6 +21,19 @@ func OkxPositionSide(positionSide exchange.PositionSide) string {
 	return strings.ToLower(string(positionSide))
 }
 
+// OkxStpMode okx 不能关闭自成交保护，NONE 时使用默认的 cancel_maker
+func OkxStpMode(mode exchange.SelfTradePreventionMode) string {
+	switch mode {
+	case exchange.SelfTradePreventionExpireTaker:
+		return "cancel_taker"
+	case exchange.SelfTradePreventionExpireMaker:
+		return "cancel_maker"
+	case exchange.SelfTradePreventionExpireBoth:
+		return "cancel_both"
+	}
+	return ""
+}
+
 func OkxPosMode(posMode exchange.PosMode) string {
 	return strings.ToLower(string(posMode))
 }
@@ -45,6 +58,108 @@ func OkxTMarketType(marketType string) exchange.OrderType {
 	return exchange.OrderType(strings.ToUpper(marketType))
 }
 
+// OkxInstType 市场类型转换为 okx 产品类型
+func OkxInstType(marketType exchange.MarketType) string {
+	switch marketType {
+	case exchange.MarketTypeSpot:
+		return "SPOT"
+	case exchange.MarketTypeMargin:
+		return "MARGIN"
+	case exchange.MarketTypePerpetualUSDMargined, exchange.MarketTypePerpetualCoinMargined:
+		return "SWAP"
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypeFuturesCoinMargined:
+		return "FUTURES"
+	}
+	return ""
+}
+
+// OkxTInstType okx 产品类型转换为统一市场类型
+func OkxTInstType(instType string) exchange.MarketType {
+	switch instType {
+	case "SWAP":
+		return exchange.MarketTypePerpetualUSDMargined
+	case "FUTURES":
+		return exchange.MarketTypeFuturesUSDMargined
+	case "MARGIN":
+		return exchange.MarketTypeMargin
+	}
+	return exchange.MarketTypeSpot
+}
+
+// OkxMarketType okx 产品类型和产品 ID 转换为统一市场类型，区分 U 本位和币本位合约
+func OkxMarketType(instType string, instId string) exchange.MarketType {
+	marketType := OkxTInstType(instType)
+	if !okIsInverse(instId) {
+		return marketType
+	}
+	switch marketType {
+	case exchange.MarketTypePerpetualUSDMargined:
+		return exchange.MarketTypePerpetualCoinMargined
+	case exchange.MarketTypeFuturesUSDMargined:
+		return exchange.MarketTypeFuturesCoinMargined
+	}
+	return marketType
+}
+
+// okIsInverse 币本位合约以 USD 计价，如 BTC-USD-SWAP、BTC-USD-250328
+func okIsInverse(instId string) bool {
+	parts := strings.Split(instId, "-")
+	return len(parts) >= 3 && parts[1] == "USD"
+}
+
+// okMatchMarketType 合约是否与市场类型的本位一致，SWAP 和 FUTURES 接口同时返回 U 本位和币本位合约
+func okMatchMarketType(instId string, marketType exchange.MarketType) bool {
+	return okIsInverse(instId) == marketType.IsCoinMargined()
+}
+
+// OkxTOrderState okx 订单状态转换为统一订单状态
+func OkxTOrderState(state string) exchange.OrderState {
+	switch state {
+	case "partially_filled":
+		return exchange.OrderStatePartiallyFilled
+	case "filled":
+		return exchange.OrderStateFilled
+	case "canceled", "mmp_canceled":
+		return exchange.OrderStateCanceled
+	case "rejected":
+		return exchange.OrderStateRejected
+	case "expired":
+		return exchange.OrderStateExpired
+	}
+	return exchange.OrderStateNew
+}
+
+// OkxTAlgoOrderState 策略委托状态，已生效表示已触发下单
+func OkxTAlgoOrderState(state string) exchange.OrderState {
+	switch state {
+	case "effective":
+		return exchange.OrderStateFilled
+	case "partially_effective":
+		return exchange.OrderStatePartiallyFilled
+	case "canceled":
+		return exchange.OrderStateCanceled
+	case "order_failed", "partially_failed":
+		return exchange.OrderStateRejected
+	}
+	return exchange.OrderStateNew
+}
+
+// OkxTAlgoOrderType 策略委托类型，计划委托按委托价格区分限价和市价
+func OkxTAlgoOrderType(ordType string, ordPx string) exchange.OrderType {
+	switch ordType {
+	case "oco", "conditional":
+		return exchange.OrderTypeOco
+	case "move_order_stop":
+		return exchange.OrderTypeTrailingStopMarket
+	case "trigger":
+		if ordPx == "-1" {
+			return exchange.OrderTypeStopMarket
+		}
+		return exchange.OrderTypeStop
+	}
+	return exchange.OrderType(strings.ToUpper(ordType))
+}
+
 type OrderInfo struct {
 	InstType      string `json:"instType"`
 	InstID        string `json:"instId"`
@@ -69,6 +184,82 @@ type OrderInfo struct {
 	CreateTime    string `json:"cTime"`     // 创建时间
 }
 
+type OrdersResponse struct {
+	Code string      `json:"code"`
+	Data []OrderInfo `json:"data"`
+	Msg  string      `json:"msg"`
+}
+
+type FillsResponse struct {
+	Code string `json:"code"`
+	Data []struct {
+		InstType string `json:"instType"`
+		InstID   string `json:"instId"`
+		TradeID  string `json:"tradeId"`
+		OrdID    string `json:"ordId"`
+		ClOrdID  string `json:"clOrdId"`
+		BillID   string `json:"billId"`
+		FillPx   string `json:"fillPx"`
+		FillSz   string `json:"fillSz"`
+		FillPnl  string `json:"fillPnl"` // 平仓收益
+		Side     string `json:"side"`
+		PosSide  string `json:"posSide"`
+		ExecType string `json:"execType"` // T: taker M: maker
+		FeeCcy   string `json:"feeCcy"`
+		Fee      string `json:"fee"` // 负数表示扣除手续费
+		Ts       string `json:"ts"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
+type PositionHistory struct {
+	InstType      string `json:"instType"`
+	InstID        string `json:"instId"`
+	MgnMode       string `json:"mgnMode"`
+	Type          string `json:"type"` // 平仓类型
+	PosSide       string `json:"posSide"`
+	Direction     string `json:"direction"`
+	OpenAvgPx     string `json:"openAvgPx"`
+	CloseAvgPx    string `json:"closeAvgPx"`
+	CloseTotalPos string `json:"closeTotalPos"` // 累计平仓量
+	Pnl           string `json:"pnl"`           // 平仓收益额
+	Fee           string `json:"fee"`           // 累计手续费
+	FundingFee    string `json:"fundingFee"`    // 累计资金费用
+	RealizedPnl   string `json:"realizedPnl"`   // 已实现收益
+	CTime         string `json:"cTime"`
+	UTime         string `json:"uTime"`
+}
+
+type PositionsHistoryResponse struct {
+	Code string            `json:"code"`
+	Data []PositionHistory `json:"data"`
+	Msg  string            `json:"msg"`
+}
+
+type InstrumentsResponse struct {
+	Code string `json:"code"`
+	Data []struct {
+		InstType  string `json:"instType"`
+		InstID    string `json:"instId"`
+		BaseCcy   string `json:"baseCcy"`
+		QuoteCcy  string `json:"quoteCcy"`
+		SettleCcy string `json:"settleCcy"`
+		CtVal     string `json:"ctVal"`    // 合约面值
+		CtMult    string `json:"ctMult"`   // 合约乘数
+		CtValCcy  string `json:"ctValCcy"` // 合约面值计价币种
+		CtType    string `json:"ctType"`   // linear: 正向合约 inverse: 反向合约
+		TickSz    string `json:"tickSz"`
+		LotSz     string `json:"lotSz"`
+		MinSz     string `json:"minSz"`
+		MaxLmtSz  string `json:"maxLmtSz"`
+		MaxMktSz  string `json:"maxMktSz"`
+		ListTime  string `json:"listTime"`
+		ExpTime   string `json:"expTime"`
+		State     string `json:"state"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
 type CreateOrderResponse struct {
 	Code string `json:"code"`
 	Data []struct {
@@ -139,6 +330,12 @@ type PositionsResponse struct {
 	Msg string `json:"msg"`
 }
 
+// BaseResponse 只需要检查 code 的接口返回
+type BaseResponse struct {
+	Code string `json:"code"`
+	Msg  string `json:"msg"`
+}
+
 type LeverageResponse struct {
 	Code string `json:"code"`
 	Data []struct {
@@ -203,6 +400,19 @@ type CancelOrderResponse struct {
 	Msg string `json:"msg"`
 }
 
+type AmendOrderResponse struct {
+	Code string `json:"code"`
+	Data []struct {
+		ClOrdId string `json:"clOrdId"`
+		OrdId   string `json:"ordId"`
+		ReqId   string `json:"reqId"`
+		SCode   string `json:"sCode"`
+		SMsg    string `json:"sMsg"`
+		Ts      string `json:"ts"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
 type TickerPriceResponse struct {
 	Code string `json:"code"`
 	Data []struct {
@@ -211,3 +421,120 @@ type TickerPriceResponse struct {
 	} `json:"data"`
 	Msg string `json:"msg"`
 }
+
+type AlgoOrderActionResponse struct {
+	Code string `json:"code"`
+	Data []struct {
+		AlgoId      string `json:"algoId"`
+		AlgoClOrdId string `json:"algoClOrdId"`
+		SCode       string `json:"sCode"`
+		SMsg        string `json:"sMsg"`
+	} `json:"data"`
+	Msg string `json:"msg"`
+}
+
+type AlgoOrderInfo struct {
+	InstType      string   `json:"instType"`
+	InstID        string   `json:"instId"`
+	OrdType       string   `json:"ordType"` // trigger, conditional, oco, move_order_stop
+	State         string   `json:"state"`   // live, pause, partially_effective, effective, canceled, order_failed, partially_failed
+	Side          string   `json:"side"`
+	PosSide       string   `json:"posSide"`
+	Sz            string   `json:"sz"`
+	AlgoID        string   `json:"algoId"`
+	AlgoClOrdID   string   `json:"algoClOrdId"`
+	OrdID         string   `json:"ordId"`
+	OrdIdList     []string `json:"ordIdList"`
+	TriggerPx     string   `json:"triggerPx"`
+	OrdPx         string   `json:"ordPx"`
+	TpTriggerPx   string   `json:"tpTriggerPx"`
+	SlTriggerPx   string   `json:"slTriggerPx"`
+	CallbackRatio string   `json:"callbackRatio"`
+	ActivePx      string   `json:"activePx"`
+	CTime         string   `json:"cTime"`
+	UTime         string   `json:"uTime"`
+}
+
+type AlgoOrdersResponse struct {
+	Code string          `json:"code"`
+	Data []AlgoOrderInfo `json:"data"`
+	Msg  string          `json:"msg"`
+}
+
+type Bill struct {
+	BillID   string `json:"billId"`
+	InstType string `json:"instType"`
+	InstID   string `json:"instId"`
+	Ccy      string `json:"ccy"`
+	Type     string `json:"type"`
+	SubType  string `json:"subType"`
+	BalChg   string `json:"balChg"`
+	Bal      string `json:"bal"`
+	Fee      string `json:"fee"`
+	Pnl      string `json:"pnl"`
+	OrdID    string `json:"ordId"`
+	TradeID  string `json:"tradeId"`
+	Ts       string `json:"ts"`
+}
+
+type BillsResponse struct {
+	Code string `json:"code"`
+	Data []Bill `json:"data"`
+	Msg  string `json:"msg"`
+}
+
+type FundingRateInfo struct {
+	InstType        string `json:"instType"`
+	InstID          string `json:"instId"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingRate string `json:"nextFundingRate"`
+	FundingTime     string `json:"fundingTime"`
+	NextFundingTime string `json:"nextFundingTime"`
+	SettFundingRate string `json:"settFundingRate"`
+	Ts              string `json:"ts"`
+}
+
+type FundingRateResponse struct {
+	Code string            `json:"code"`
+	Data []FundingRateInfo `json:"data"`
+	Msg  string            `json:"msg"`
+}
+
+type FundingRateHistory struct {
+	InstType     string `json:"instType"`
+	InstID       string `json:"instId"`
+	FundingRate  string `json:"fundingRate"`
+	RealizedRate string `json:"realizedRate"`
+	FundingTime  string `json:"fundingTime"`
+}
+
+type FundingRateHistoryResponse struct {
+	Code string               `json:"code"`
+	Data []FundingRateHistory `json:"data"`
+	Msg  string               `json:"msg"`
+}
+
+type InterestRate struct {
+	Ccy          string `json:"ccy"`
+	InterestRate string `json:"interestRate"`
+}
+
+type InterestRateResponse struct {
+	Code string         `json:"code"`
+	Data []InterestRate `json:"data"`
+	Msg  string         `json:"msg"`
+}
+
+type LoanQuota struct {
+	Basic []struct {
+		Ccy   string `json:"ccy"`
+		Rate  string `json:"rate"`
+		Quota string `json:"quota"`
+	} `json:"basic"`
+}
+
+type LoanQuotaResponse struct {
+	Code string      `json:"code"`
+	Data []LoanQuota `json:"data"`
+	Msg  string      `json:"msg"`
+}
@@ -0,0 +1,88 @@
+package okexc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+)
+
+// okErrors okx 错误码与统一错误类型的对应关系
+// https://www.okx.com/docs-v5/en/#error-code
+var okErrors = map[string]error{
+	"50001": exchange.ErrServiceUnavailable,
+	"50004": exchange.ErrServiceUnavailable,
+	"50011": exchange.ErrRateLimitExceeded,
+	"50013": exchange.ErrServiceUnavailable,
+	"50014": exchange.ErrInvalidParameter,
+	"50026": exchange.ErrServiceUnavailable,
+	"50061": exchange.ErrRateLimitExceeded,
+	"50101": exchange.ErrAuthenticationFailed,
+	"50102": exchange.ErrInvalidTimestamp,
+	"50103": exchange.ErrAuthenticationFailed,
+	"50104": exchange.ErrAuthenticationFailed,
+	"50105": exchange.ErrAuthenticationFailed,
+	"50110": exchange.ErrPermissionDenied,
+	"50111": exchange.ErrAuthenticationFailed,
+	"50112": exchange.ErrInvalidTimestamp,
+	"50113": exchange.ErrAuthenticationFailed,
+	"50119": exchange.ErrAuthenticationFailed,
+	"50120": exchange.ErrPermissionDenied,
+	"51000": exchange.ErrInvalidParameter,
+	"51001": exchange.ErrInvalidSymbol,
+	"51006": exchange.ErrInvalidOrderPrice,
+	"51008": exchange.ErrOrderNotEnoughBalance,
+	"51009": exchange.ErrOrderRejected,
+	"51016": exchange.ErrOrderAlreadyExists,
+	"51020": exchange.ErrOrderSizeTooSmall,
+	"51024": exchange.ErrPermissionDenied,
+	"51119": exchange.ErrOrderNotEnoughBalance,
+	"51121": exchange.ErrInvalidOrderSize,
+	"51127": exchange.ErrOrderNotEnoughBalance,
+	"51131": exchange.ErrOrderNotEnoughBalance,
+	"51169": exchange.ErrReduceOnlyRejected,
+	"51201": exchange.ErrOrderSizeTooLarge,
+	"51202": exchange.ErrOrderSizeTooLarge,
+	"51400": exchange.ErrOrderNotFound,
+	"51401": exchange.ErrOrderNotFound,
+	"51402": exchange.ErrOrderNotFound,
+	"51603": exchange.ErrOrderNotFound,
+}
+
+// okRetryable 可以重试的统一错误类型
+var okRetryable = map[error]bool{
+	exchange.ErrServiceUnavailable: true,
+	exchange.ErrRateLimitExceeded:  true,
+	exchange.ErrInvalidTimestamp:   true,
+}
+
+// callAPI 调用接口，HTTP 4xx/5xx 时将 okx 错误转换为 *exchange.Error
+func (o *okx) callAPI(ctx context.Context, r *okhttp.Request) ([]byte, error) {
+	data, err := o.client.CallAPI(ctx, r)
+	if err != nil {
+		var apiErr *okhttp.APIError
+		if errors.As(err, &apiErr) {
+			e := okError(apiErr.Code, apiErr.Message)
+			e.Cause = apiErr
+			return nil, e
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// okError 将 okx 返回的 code/msg 或 sCode/sMsg 转换为 *exchange.Error
+func okError(code, msg string) *exchange.Error {
+	target, ok := okErrors[code]
+	if !ok {
+		target = exchange.ErrUnknown
+	}
+	return &exchange.Error{
+		Exchange:  exchange.OkxExchange,
+		Code:      code,
+		Message:   msg,
+		Retryable: okRetryable[target],
+		Err:       target,
+	}
+}
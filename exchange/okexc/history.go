@@ -0,0 +1,553 @@
+package okexc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/shopspring/decimal"
+)
+
+// okHistoryPager okx 历史数据按时间倒序返回，使用 after 游标向更早的数据翻页
+type okHistoryPager[T any] struct {
+	after string
+	fetch func(ctx context.Context, after string) ([]T, string, error)
+}
+
+func (p *okHistoryPager[T]) next(ctx context.Context) ([]T, bool, error) {
+	items, cursor, err := p.fetch(ctx, p.after)
+	if err != nil {
+		return nil, false, err
+	}
+	p.after = cursor
+	return items, cursor == "", nil
+}
+
+// okCtValCache 按产品类型缓存合约面值，用于张币转换
+type okCtValCache struct {
+	o      *okx
+	ctVals map[string]map[string]decimal.Decimal
+}
+
+func (c *okCtValCache) get(ctx context.Context, instType string, instId string) (decimal.Decimal, error) {
+	if instType != "SWAP" && instType != "FUTURES" {
+		return decimal.Zero, nil
+	}
+	if c.ctVals == nil {
+		c.ctVals = make(map[string]map[string]decimal.Decimal)
+	}
+	if _, ok := c.ctVals[instType]; !ok {
+		ctVals, err := c.o.getCtVals(ctx, instType, "")
+		if err != nil {
+			return decimal.Zero, err
+		}
+		c.ctVals[instType] = ctVals
+	}
+	return c.ctVals[instType][instId], nil
+}
+
+// OrderHistory 查询近三个月的历史订单，合约数量由张转换为币
+func (o *okx) OrderHistory(req *exchange.GetOrderHistoryRequest) exchange.Iterator[*exchange.SearchOrderResponse] {
+	instType := OkxInstType(req.MarketType)
+	cache := &okCtValCache{o: o}
+	pager := &okHistoryPager[*exchange.SearchOrderResponse]{
+		fetch: func(ctx context.Context, after string) ([]*exchange.SearchOrderResponse, string, error) {
+			if instType == "" {
+				return nil, "", exchange.ErrInstrumentTypeNotSupported
+			}
+			r := &okhttp.Request{
+				APIKey:     req.APIKey,
+				SecretKey:  req.SecretKey,
+				Passphrase: req.Passphrase,
+				Method:     "GET",
+				Endpoint:   "/api/v5/trade/orders-history-archive",
+				SecType:    okhttp.SecTypeSigned,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			r.SetParams(okHistoryParams(instType, req.Symbol, req.StartTime, req.EndTime, after))
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var response OrdersResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, "", fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, "", okError(response.Code, response.Msg)
+			}
+			result := make([]*exchange.SearchOrderResponse, 0, len(response.Data))
+			for i := range response.Data {
+				ctVal, err := cache.get(ctx, response.Data[i].InstType, response.Data[i].InstID)
+				if err != nil {
+					return nil, "", err
+				}
+				order, err := o.toSearchOrderResponse(&response.Data[i], ctVal)
+				if err != nil {
+					return nil, "", err
+				}
+				result = append(result, order)
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				return result, "", nil
+			}
+			return result, response.Data[len(response.Data)-1].OrderID, nil
+		},
+	}
+	return exchange.NewIterator(pager.next)
+}
+
+// TradeHistory 查询近三个月的成交明细，合约数量由张转换为币
+func (o *okx) TradeHistory(req *exchange.GetTradeHistoryRequest) exchange.Iterator[*exchange.SearchTradesResponse] {
+	instType := OkxInstType(req.MarketType)
+	cache := &okCtValCache{o: o}
+	pager := &okHistoryPager[*exchange.SearchTradesResponse]{
+		fetch: func(ctx context.Context, after string) ([]*exchange.SearchTradesResponse, string, error) {
+			if instType == "" {
+				return nil, "", exchange.ErrInstrumentTypeNotSupported
+			}
+			r := &okhttp.Request{
+				APIKey:     req.APIKey,
+				SecretKey:  req.SecretKey,
+				Passphrase: req.Passphrase,
+				Method:     "GET",
+				Endpoint:   "/api/v5/trade/fills-history",
+				SecType:    okhttp.SecTypeSigned,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			r.SetParams(okHistoryParams(instType, req.Symbol, req.StartTime, req.EndTime, after))
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var response FillsResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, "", fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, "", okError(response.Code, response.Msg)
+			}
+			result := make([]*exchange.SearchTradesResponse, 0, len(response.Data))
+			for _, v := range response.Data {
+				ctVal, err := cache.get(ctx, v.InstType, v.InstID)
+				if err != nil {
+					return nil, "", err
+				}
+				price, err := decimal.NewFromString(v.FillPx)
+				if err != nil {
+					return nil, "", err
+				}
+				size, err := decimal.NewFromString(v.FillSz)
+				if err != nil {
+					return nil, "", err
+				}
+				if !ctVal.IsZero() {
+					size = size.Mul(ctVal)
+				}
+				fee, err := decimal.NewFromString(v.Fee)
+				if err != nil {
+					fee = decimal.Zero
+				}
+				pnl, err := decimal.NewFromString(v.FillPnl)
+				if err != nil {
+					pnl = decimal.Zero
+				}
+				ts, err := strconv.ParseInt(v.Ts, 10, 64)
+				if err != nil {
+					return nil, "", err
+				}
+				by := exchange.ByTaker
+				if v.ExecType == "M" {
+					by = exchange.ByMaker
+				}
+				result = append(result, &exchange.SearchTradesResponse{
+					Symbol:        v.InstID,
+					ID:            v.TradeID,
+					OrderID:       v.OrdID,
+					ClientOrderID: v.ClOrdID,
+					Side:          OkxTSide(v.Side),
+					PositionSide:  OkxTPositionSide(v.PosSide),
+					Price:         price,
+					Volume:        size,
+					// okx 手续费为负数表示扣除
+					FeeCost:     fee.Neg(),
+					FeeAsset:    v.FeeCcy,
+					RealizedPnl: pnl,
+					Time:        ts,
+					By:          by,
+				})
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				return result, "", nil
+			}
+			return result, response.Data[len(response.Data)-1].BillID, nil
+		},
+	}
+	return exchange.NewIterator(pager.next)
+}
+
+// ClosedPositions 查询近三个月的已平仓仓位，MarketType 为空时查询全部产品类型
+func (o *okx) ClosedPositions(req *exchange.GetClosedPositionsRequest) exchange.Iterator[*exchange.ClosedPosition] {
+	instType := OkxInstType(req.MarketType)
+	cache := &okCtValCache{o: o}
+	pager := &okHistoryPager[*exchange.ClosedPosition]{
+		fetch: func(ctx context.Context, after string) ([]*exchange.ClosedPosition, string, error) {
+			r := &okhttp.Request{
+				APIKey:     req.APIKey,
+				SecretKey:  req.SecretKey,
+				Passphrase: req.Passphrase,
+				Method:     "GET",
+				Endpoint:   "/api/v5/account/positions-history",
+				SecType:    okhttp.SecTypeSigned,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			// 仓位历史只支持按更新时间翻页，after 为空时从 EndTime 开始
+			if after == "" && req.EndTime > 0 {
+				after = strconv.FormatInt(req.EndTime+1, 10)
+			}
+			params := okhttp.Params{
+				"limit": okOrdersPageLimit,
+			}
+			if instType != "" {
+				params["instType"] = instType
+			}
+			if req.Symbol != "" {
+				params["instId"] = req.Symbol
+			}
+			if after != "" {
+				params["after"] = after
+			}
+			r.SetParams(params)
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var response PositionsHistoryResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, "", fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, "", okError(response.Code, response.Msg)
+			}
+			result := make([]*exchange.ClosedPosition, 0, len(response.Data))
+			for _, v := range response.Data {
+				closeTime, err := strconv.ParseInt(v.UTime, 10, 64)
+				if err != nil {
+					return nil, "", err
+				}
+				if closeTime < req.StartTime {
+					return result, "", nil
+				}
+				ctVal, err := cache.get(ctx, v.InstType, v.InstID)
+				if err != nil {
+					return nil, "", err
+				}
+				position, err := toClosedPosition(v, ctVal)
+				if err != nil {
+					return nil, "", err
+				}
+				result = append(result, position)
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				return result, "", nil
+			}
+			return result, response.Data[len(response.Data)-1].UTime, nil
+		},
+	}
+	return exchange.NewIterator(pager.next)
+}
+
+// okBillTypes okx 账单类型与统一账单类型的对应关系，交易账单拆分为手续费和平仓盈亏，未列出的为 OTHER
+var okBillTypes = map[string]exchange.LedgerType{
+	"1": exchange.LedgerTypeTransfer,
+	"5": exchange.LedgerTypeLiquidation,
+	"7": exchange.LedgerTypeInterest,
+	"8": exchange.LedgerTypeFundingFee,
+}
+
+// Ledger 查询近三个月的账单流水，MarketType 为空时查询全部产品类型
+func (o *okx) Ledger(req *exchange.GetLedgerRequest) exchange.Iterator[*exchange.LedgerEntry] {
+	instType := OkxInstType(req.MarketType)
+	billType := ""
+	switch req.Type {
+	case exchange.LedgerTypeFee, exchange.LedgerTypeRealizedPnl:
+		billType = "2"
+	default:
+		for k, v := range okBillTypes {
+			if v == req.Type {
+				billType = k
+			}
+		}
+	}
+	pager := &okHistoryPager[*exchange.LedgerEntry]{
+		fetch: func(ctx context.Context, after string) ([]*exchange.LedgerEntry, string, error) {
+			r := &okhttp.Request{
+				APIKey:     req.APIKey,
+				SecretKey:  req.SecretKey,
+				Passphrase: req.Passphrase,
+				Method:     "GET",
+				Endpoint:   "/api/v5/account/bills-archive",
+				SecType:    okhttp.SecTypeSigned,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			params := okHistoryParams(instType, req.Symbol, req.StartTime, req.EndTime, after)
+			if instType == "" {
+				delete(params, "instType")
+			}
+			if billType != "" {
+				params["type"] = billType
+			}
+			r.SetParams(params)
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var response BillsResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, "", fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, "", okError(response.Code, response.Msg)
+			}
+			result := make([]*exchange.LedgerEntry, 0, len(response.Data))
+			for _, v := range response.Data {
+				entries, err := toLedgerEntries(v)
+				if err != nil {
+					return nil, "", err
+				}
+				for _, e := range entries {
+					if req.Type == "" || e.Type == req.Type {
+						result = append(result, e)
+					}
+				}
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				return result, "", nil
+			}
+			return result, response.Data[len(response.Data)-1].BillID, nil
+		},
+	}
+	return exchange.NewIterator(pager.next)
+}
+
+func toLedgerEntries(v Bill) ([]*exchange.LedgerEntry, error) {
+	ts, err := strconv.ParseInt(v.Ts, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	newEntry := func(typ exchange.LedgerType, amount string) (*exchange.LedgerEntry, error) {
+		amt, err := decimal.NewFromString(amount)
+		if err != nil {
+			return nil, err
+		}
+		bal, err := decimal.NewFromString(v.Bal)
+		if err != nil {
+			bal = decimal.Zero
+		}
+		return &exchange.LedgerEntry{
+			ID:         v.BillID,
+			Symbol:     v.InstID,
+			MarketType: OkxMarketType(v.InstType, v.InstID),
+			Type:       typ,
+			Asset:      v.Ccy,
+			Amount:     amt,
+			Balance:    bal,
+			OrderID:    v.OrdID,
+			TradeID:    v.TradeID,
+			Info:       v.Type + ":" + v.SubType,
+			Time:       ts,
+		}, nil
+	}
+
+	if v.Type != "2" {
+		typ, ok := okBillTypes[v.Type]
+		if !ok {
+			typ = exchange.LedgerTypeOther
+		}
+		entry, err := newEntry(typ, v.BalChg)
+		if err != nil {
+			return nil, err
+		}
+		return []*exchange.LedgerEntry{entry}, nil
+	}
+
+	// 交易账单的 fee 为负数表示扣除，与统一账单的符号一致
+	result := make([]*exchange.LedgerEntry, 0, 2)
+	for typ, amount := range map[exchange.LedgerType]string{
+		exchange.LedgerTypeFee:         v.Fee,
+		exchange.LedgerTypeRealizedPnl: v.Pnl,
+	} {
+		if amount == "" {
+			continue
+		}
+		entry, err := newEntry(typ, amount)
+		if err != nil {
+			return nil, err
+		}
+		if !entry.Amount.IsZero() {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+// FundingRateHistory 查询永续合约历史资金费率，按结算时间倒序返回
+func (o *okx) FundingRateHistory(req *exchange.GetFundingRateHistoryRequest) exchange.Iterator[*exchange.FundingRate] {
+	pager := &okHistoryPager[*exchange.FundingRate]{
+		fetch: func(ctx context.Context, after string) ([]*exchange.FundingRate, string, error) {
+			if !req.MarketType.IsPerpetual() {
+				return nil, "", exchange.ErrInstrumentTypeNotSupported
+			}
+			r := &okhttp.Request{
+				Method:   "GET",
+				Endpoint: "/api/v5/public/funding-rate-history",
+				SecType:  okhttp.SecTypeNone,
+			}
+			o.client.SetApiEndpoint(okEndpoint)
+			// 按结算时间翻页，after 为空时从 EndTime 开始
+			if after == "" && req.EndTime > 0 {
+				after = strconv.FormatInt(req.EndTime+1, 10)
+			}
+			params := okhttp.Params{
+				"instId": req.Symbol,
+				"limit":  okOrdersPageLimit,
+			}
+			if after != "" {
+				params["after"] = after
+			}
+			r.SetParams(params)
+			data, err := o.callAPI(ctx, r)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var response FundingRateHistoryResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, "", fmt.Errorf("error parsing response data: %v", err)
+			}
+			if response.Code != "0" {
+				return nil, "", okError(response.Code, response.Msg)
+			}
+			result := make([]*exchange.FundingRate, 0, len(response.Data))
+			for _, v := range response.Data {
+				fundingTime, err := strconv.ParseInt(v.FundingTime, 10, 64)
+				if err != nil {
+					return nil, "", err
+				}
+				if fundingTime < req.StartTime {
+					return result, "", nil
+				}
+				// realizedRate 为实际收取的资金费率
+				rate := v.RealizedRate
+				if rate == "" {
+					rate = v.FundingRate
+				}
+				fundingRate, err := decimal.NewFromString(rate)
+				if err != nil {
+					return nil, "", err
+				}
+				result = append(result, &exchange.FundingRate{
+					Symbol:      v.InstID,
+					FundingRate: fundingRate,
+					FundingTime: fundingTime,
+				})
+			}
+			if len(response.Data) < okOrdersPageLimit {
+				return result, "", nil
+			}
+			return result, response.Data[len(response.Data)-1].FundingTime, nil
+		},
+	}
+	return exchange.NewIterator(pager.next)
+}
+
+func okHistoryParams(instType string, instId string, begin, end int64, after string) okhttp.Params {
+	params := okhttp.Params{
+		"instType": instType,
+		"limit":    okOrdersPageLimit,
+	}
+	if instId != "" {
+		params["instId"] = instId
+	}
+	if begin > 0 {
+		params["begin"] = begin
+	}
+	if end > 0 {
+		params["end"] = end
+	}
+	if after != "" {
+		params["after"] = after
+	}
+	return params
+}
+
+func toClosedPosition(v PositionHistory, ctVal decimal.Decimal) (*exchange.ClosedPosition, error) {
+	size, err := decimal.NewFromString(v.CloseTotalPos)
+	if err != nil {
+		return nil, err
+	}
+	if !ctVal.IsZero() {
+		size = size.Mul(ctVal)
+	}
+	openAvgPx, err := decimal.NewFromString(v.OpenAvgPx)
+	if err != nil {
+		openAvgPx = decimal.Zero
+	}
+	closeAvgPx, err := decimal.NewFromString(v.CloseAvgPx)
+	if err != nil {
+		closeAvgPx = decimal.Zero
+	}
+	pnl, err := decimal.NewFromString(v.Pnl)
+	if err != nil {
+		pnl = decimal.Zero
+	}
+	fee, err := decimal.NewFromString(v.Fee)
+	if err != nil {
+		fee = decimal.Zero
+	}
+	fundingFee, err := decimal.NewFromString(v.FundingFee)
+	if err != nil {
+		fundingFee = decimal.Zero
+	}
+	realizedPnl, err := decimal.NewFromString(v.RealizedPnl)
+	if err != nil {
+		realizedPnl = decimal.Zero
+	}
+	openTime, err := strconv.ParseInt(v.CTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	closeTime, err := strconv.ParseInt(v.UTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	// 买卖模式下 posSide 为 net，方向由 direction 给出
+	positionSide := exchange.PositionSideLong
+	if v.PosSide == "short" || (v.PosSide == "net" && v.Direction == "short") {
+		positionSide = exchange.PositionSideShort
+	}
+
+	return &exchange.ClosedPosition{
+		Symbol:        v.InstID,
+		MarketType:    OkxMarketType(v.InstType, v.InstID),
+		PositionSide:  positionSide,
+		OpenAvgPrice:  openAvgPx,
+		CloseAvgPrice: closeAvgPx,
+		Size:          size,
+		Pnl:           pnl,
+		// okx 手续费和资金费为负数表示支出
+		Fee:         fee.Neg(),
+		FundingFee:  fundingFee.Neg(),
+		RealizedPnl: realizedPnl,
+		OpenTime:    openTime,
+		CloseTime:   closeTime,
+	}, nil
+}
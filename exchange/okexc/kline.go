@@ -0,0 +1,182 @@
+package okexc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/shopspring/decimal"
+)
+
+// okHistoryKlineLimit 历史K线接口单页上限
+const okHistoryKlineLimit = 100
+
+// okKlineEndpoints 各类型K线的最近数据接口和历史数据接口，okx 没有溢价指数K线
+var okKlineEndpoints = map[exchange.KlineType][2]string{
+	exchange.KlineTypeTrade:      {"/api/v5/market/candles", "/api/v5/market/history-candles"},
+	exchange.KlineTypeMarkPrice:  {"/api/v5/market/mark-price-candles", "/api/v5/market/history-mark-price-candles"},
+	exchange.KlineTypeIndexPrice: {"/api/v5/market/index-candles", "/api/v5/market/history-index-candles"},
+}
+
+// GetKline 获取最近的K线，Start、End 不包含边界，按开盘时间倒序返回
+func (o *okx) GetKline(ctx context.Context, req *exchange.GetKlineRequest) ([]exchange.GetKlineResponse, error) {
+	klines, err := o.getKlines(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no data")
+	}
+	return klines, nil
+}
+
+// KlineHistory 使用历史K线接口按时间窗口分页获取K线
+func (o *okx) KlineHistory(req *exchange.GetKlineHistoryRequest) exchange.Iterator[exchange.GetKlineResponse] {
+	return exchange.NewKlineIterator(req, okHistoryKlineLimit, func(ctx context.Context, start, end int64) ([]exchange.GetKlineResponse, error) {
+		// okx 的 before、after 不包含边界
+		return o.getKlines(ctx, &exchange.GetKlineRequest{
+			Symbol:     req.Symbol,
+			Start:      start - 1,
+			End:        end + 1,
+			Period:     req.Period,
+			Limit:      okHistoryKlineLimit,
+			MarketType: req.MarketType,
+			Type:       req.Type,
+		}, true)
+	})
+}
+
+func (o *okx) getKlines(ctx context.Context, req *exchange.GetKlineRequest, history bool) ([]exchange.GetKlineResponse, error) {
+	endpoints, ok := okKlineEndpoints[req.Type]
+	if !ok {
+		return nil, exchange.ErrKlineTypeNotSupported
+	}
+	r := &okhttp.Request{
+		Method:   "GET",
+		Endpoint: endpoints[0],
+		SecType:  okhttp.SecTypeNone,
+	}
+	if history {
+		r.Endpoint = endpoints[1]
+	}
+
+	o.client.SetApiEndpoint(okEndpoint)
+
+	instId := req.Symbol.OriginalSymbol
+	if req.Type == exchange.KlineTypeIndexPrice {
+		instId = okIndexInstId(instId)
+	}
+	params := okhttp.Params{
+		"instId": instId,
+		"bar":    okKlineBar(req.Period),
+	}
+
+	if req.Start > 0 {
+		params["before"] = req.Start
+	}
+
+	if req.End > 0 {
+		params["after"] = req.End
+	}
+
+	if req.Limit > 0 {
+		params["limit"] = req.Limit
+	}
+
+	r.SetParams(params)
+
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response KlineResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+
+	klines := make([]exchange.GetKlineResponse, 0, len(response.Data))
+	for _, item := range response.Data {
+		kline, err := toKline(item, req.MarketType, req.Type == exchange.KlineTypeTrade)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// toKline 成交价K线为 [ts,o,h,l,c,vol,volCcy,volCcyQuote,confirm]，标记价格和指数K线为 [ts,o,h,l,c,confirm]
+func toKline(item []string, marketType exchange.MarketType, withVolume bool) (exchange.GetKlineResponse, error) {
+	if len(item) < 6 || (withVolume && len(item) < 9) {
+		return exchange.GetKlineResponse{}, errors.New("invalid kline data")
+	}
+	openTime, err := strconv.ParseInt(item[0], 10, 64)
+	if err != nil {
+		return exchange.GetKlineResponse{}, err
+	}
+	prices := make([]decimal.Decimal, 0, 4)
+	for _, v := range item[1:5] {
+		price, err := decimal.NewFromString(v)
+		if err != nil {
+			return exchange.GetKlineResponse{}, err
+		}
+		prices = append(prices, price)
+	}
+	kline := exchange.GetKlineResponse{
+		OpenTime: openTime,
+		Open:     prices[0],
+		High:     prices[1],
+		Low:      prices[2],
+		Close:    prices[3],
+		Confirm:  item[5],
+	}
+	if !withVolume {
+		return kline, nil
+	}
+	volume, err := decimal.NewFromString(item[5])
+	if err != nil {
+		return exchange.GetKlineResponse{}, err
+	}
+	if marketType.IsContract() {
+		// 合约成交量使用币的数量
+		volume, err = decimal.NewFromString(item[6])
+		if err != nil {
+			return exchange.GetKlineResponse{}, err
+		}
+	}
+	quoteVolume, err := decimal.NewFromString(item[7])
+	if err != nil {
+		return exchange.GetKlineResponse{}, err
+	}
+	kline.Volume = volume
+	kline.QuoteVolume = quoteVolume
+	kline.Confirm = item[8]
+	return kline, nil
+}
+
+// okKlineBar okx 小时及以上周期使用大写，如 1H、1D
+func okKlineBar(period string) string {
+	if strings.HasSuffix(period, "s") || strings.HasSuffix(period, "m") || strings.HasSuffix(period, "utc") {
+		return period
+	}
+	return strings.ToUpper(period)
+}
+
+// okIndexInstId 指数K线使用指数名称，如 BTC-USDT-SWAP 对应 BTC-USDT
+func okIndexInstId(instId string) string {
+	parts := strings.Split(instId, "-")
+	if len(parts) < 2 {
+		return instId
+	}
+	return parts[0] + "-" + parts[1]
+}
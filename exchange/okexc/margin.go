@@ -0,0 +1,197 @@
+package okexc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/requests/okhttp"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	okAccountFunding = "6"  // 资金账户
+	okAccountTrading = "18" // 交易账户
+)
+
+// okTransferTypes 币安划转类型与 okx 转出、转入账户的对应关系；
+// okx 的现货、杠杆和合约共用交易账户，它们之间的划转无需操作
+var okTransferTypes = map[string][2]string{
+	"FUNDING_MAIN":     {okAccountFunding, okAccountTrading},
+	"FUNDING_MARGIN":   {okAccountFunding, okAccountTrading},
+	"FUNDING_UMFUTURE": {okAccountFunding, okAccountTrading},
+	"MAIN_FUNDING":     {okAccountTrading, okAccountFunding},
+	"MARGIN_FUNDING":   {okAccountTrading, okAccountFunding},
+	"UMFUTURE_FUNDING": {okAccountTrading, okAccountFunding},
+	"MAIN_UMFUTURE":    {okAccountTrading, okAccountTrading},
+	"UMFUTURE_MAIN":    {okAccountTrading, okAccountTrading},
+	"MAIN_MARGIN":      {okAccountTrading, okAccountTrading},
+	"MARGIN_MAIN":      {okAccountTrading, okAccountTrading},
+	"UMFUTURE_MARGIN":  {okAccountTrading, okAccountTrading},
+	"MARGIN_UMFUTURE":  {okAccountTrading, okAccountTrading},
+}
+
+// TransferAsset 资金账户与交易账户之间划转，划转类型使用币安的类型
+func (o *okx) TransferAsset(ctx context.Context, req *exchange.TransferAssetRequest) error {
+	params, err := toTransferParams(req)
+	if err != nil || params == nil {
+		return err
+	}
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/asset/transfer",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	r = r.SetJSONBody(params)
+	return o.callBaseAPI(ctx, r)
+}
+
+// toTransferParams 交易账户内部的划转无需操作，返回 nil
+func toTransferParams(req *exchange.TransferAssetRequest) (okhttp.Params, error) {
+	accounts, ok := okTransferTypes[req.Type]
+	if !ok {
+		return nil, errors.New("unsupported transfer type")
+	}
+	if accounts[0] == accounts[1] {
+		return nil, nil
+	}
+	return okhttp.Params{
+		"ccy":  req.Asset,
+		"amt":  req.Amount.String(),
+		"from": accounts[0],
+		"to":   accounts[1],
+	}, nil
+}
+
+// GetMarginInterestRate 获取借币小时利率，okx 全仓和逐仓利率相同
+func (o *okx) GetMarginInterestRate(ctx context.Context, req *exchange.GetMarginInterestRateRequest) ([]*exchange.GetMarginInterestRateResponse, error) {
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "GET",
+		Endpoint:   "/api/v5/account/interest-rate",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	// 接口只支持查询单个币种，多个币种时查询全部后过滤
+	assets := make(map[string]bool)
+	for _, v := range strings.Split(req.Assets, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			assets[strings.ToUpper(v)] = true
+		}
+	}
+	if len(assets) == 1 {
+		r.SetParam("ccy", strings.ToUpper(strings.TrimSpace(req.Assets)))
+	}
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response InterestRateResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	result := make([]*exchange.GetMarginInterestRateResponse, 0, len(response.Data))
+	for _, v := range response.Data {
+		if len(assets) > 0 && !assets[v.Ccy] {
+			continue
+		}
+		rate, err := decimal.NewFromString(v.InterestRate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &exchange.GetMarginInterestRateResponse{
+			Asset:                  v.Ccy,
+			NextHourlyInterestRate: rate,
+		})
+	}
+	return result, nil
+}
+
+// MarginBorrowOrRepay 全仓使用现货模式手动借币还币，逐仓使用一键借币模式，需要指定交易对
+func (o *okx) MarginBorrowOrRepay(ctx context.Context, req *exchange.MarginBorrowOrRepayRequest) error {
+	endpoint, params, err := toBorrowRepayParams(req)
+	if err != nil {
+		return err
+	}
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   endpoint,
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	r = r.SetJSONBody(params)
+	return o.callBaseAPI(ctx, r)
+}
+
+// toBorrowRepayParams 返回借币还币的接口地址和参数
+func toBorrowRepayParams(req *exchange.MarginBorrowOrRepayRequest) (string, okhttp.Params, error) {
+	var side string
+	switch req.Typ {
+	case "BORROW":
+		side = "borrow"
+	case "REPAY":
+		side = "repay"
+	default:
+		return "", nil, errors.New("invalid borrow or repay type")
+	}
+	params := okhttp.Params{
+		"ccy":  req.Asset,
+		"side": side,
+		"amt":  req.Amount.String(),
+	}
+	if !req.IsIsolated {
+		return "/api/v5/account/spot-manual-borrow-repay", params, nil
+	}
+	if req.Symbol == "" {
+		return "", nil, errors.New("symbol is required for isolated margin")
+	}
+	params["instId"] = req.Symbol
+	return "/api/v5/account/quick-margin-borrow-repay", params, nil
+}
+
+// GetMarginInventory okx 没有平台可借库存接口，返回当前账户等级下各币种的借币额度
+func (o *okx) GetMarginInventory(ctx context.Context, req *exchange.MarginInventoryRequest) (*exchange.MarginInventory, error) {
+	r := &okhttp.Request{
+		Method:   "GET",
+		Endpoint: "/api/v5/public/interest-rate-loan-quota",
+		SecType:  okhttp.SecTypeNone,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response LoanQuotaResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	assets := make(map[string]string)
+	for _, v := range response.Data {
+		for _, b := range v.Basic {
+			assets[strings.ToUpper(b.Ccy)] = b.Quota
+		}
+	}
+	return &exchange.MarginInventory{
+		Assets: assets,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package okexc
+
+import (
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferParams(t *testing.T) {
+	tests := []struct {
+		typ      string
+		from, to string // 为空表示无需划转
+		err      bool
+	}{
+		{typ: "FUNDING_MAIN", from: okAccountFunding, to: okAccountTrading},
+		{typ: "FUNDING_MARGIN", from: okAccountFunding, to: okAccountTrading},
+		{typ: "FUNDING_UMFUTURE", from: okAccountFunding, to: okAccountTrading},
+		{typ: "MAIN_FUNDING", from: okAccountTrading, to: okAccountFunding},
+		{typ: "MARGIN_FUNDING", from: okAccountTrading, to: okAccountFunding},
+		{typ: "UMFUTURE_FUNDING", from: okAccountTrading, to: okAccountFunding},
+		// 现货、杠杆和合约共用交易账户
+		{typ: "MAIN_UMFUTURE"},
+		{typ: "UMFUTURE_MAIN"},
+		{typ: "MAIN_MARGIN"},
+		{typ: "MARGIN_MAIN"},
+		{typ: "UMFUTURE_MARGIN"},
+		{typ: "MARGIN_UMFUTURE"},
+		{typ: "MAIN_CMFUTURE", err: true},
+		{typ: "", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			params, err := toTransferParams(&exchange.TransferAssetRequest{
+				Type:   tt.typ,
+				Asset:  "USDT",
+				Amount: decimal.NewFromFloat(1.5),
+			})
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.from == "" {
+				assert.Nil(t, params)
+				return
+			}
+			assert.Equal(t, tt.from, params["from"])
+			assert.Equal(t, tt.to, params["to"])
+			assert.Equal(t, "USDT", params["ccy"])
+			assert.Equal(t, "1.5", params["amt"])
+		})
+	}
+}
+
+func TestBorrowRepayParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *exchange.MarginBorrowOrRepayRequest
+		endpoint string
+		side     string
+		instId   string
+		err      bool
+	}{
+		{
+			name:     "cross borrow",
+			req:      &exchange.MarginBorrowOrRepayRequest{Typ: "BORROW"},
+			endpoint: "/api/v5/account/spot-manual-borrow-repay",
+			side:     "borrow",
+		},
+		{
+			name:     "cross repay",
+			req:      &exchange.MarginBorrowOrRepayRequest{Typ: "REPAY"},
+			endpoint: "/api/v5/account/spot-manual-borrow-repay",
+			side:     "repay",
+		},
+		{
+			name:     "isolated borrow",
+			req:      &exchange.MarginBorrowOrRepayRequest{Typ: "BORROW", IsIsolated: true, Symbol: "BTC-USDT"},
+			endpoint: "/api/v5/account/quick-margin-borrow-repay",
+			side:     "borrow",
+			instId:   "BTC-USDT",
+		},
+		{
+			name:     "isolated repay",
+			req:      &exchange.MarginBorrowOrRepayRequest{Typ: "REPAY", IsIsolated: true, Symbol: "BTC-USDT"},
+			endpoint: "/api/v5/account/quick-margin-borrow-repay",
+			side:     "repay",
+			instId:   "BTC-USDT",
+		},
+		{
+			name: "isolated without symbol",
+			req:  &exchange.MarginBorrowOrRepayRequest{Typ: "BORROW", IsIsolated: true},
+			err:  true,
+		},
+		{
+			name: "invalid type",
+			req:  &exchange.MarginBorrowOrRepayRequest{Typ: "borrow"},
+			err:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Asset = "USDT"
+			tt.req.Amount = decimal.NewFromInt(100)
+			endpoint, params, err := toBorrowRepayParams(tt.req)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.endpoint, endpoint)
+			assert.Equal(t, tt.side, params["side"])
+			assert.Equal(t, "USDT", params["ccy"])
+			assert.Equal(t, "100", params["amt"])
+			if tt.instId == "" {
+				assert.NotContains(t, params, "instId")
+			} else {
+				assert.Equal(t, tt.instId, params["instId"])
+			}
+		})
+	}
+}
@@ -15,6 +15,9 @@ import (
 
 const (
 	okEndpoint = "https://www.okx.com"
+
+	okBatchOrdersLimit = 20  // 批量下单/撤单每次最多订单数
+	okOrdersPageLimit  = 100 // 订单列表每页最多条数
 )
 
 func NewOkx(cli *okhttp.Client) exchange.Exchange {
@@ -31,10 +34,6 @@ func (o *okx) Name() string {
 	return exchange.OkxExchange
 }
 
-func (o *okx) TransferAsset(ctx context.Context, req *exchange.TransferAssetRequest) error {
-	return errors.New("not implemented")
-}
-
 func (o *okx) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (exchange.GetDepthResponse, error) {
 	r := &okhttp.Request{
 		Method:   "GET",
@@ -51,7 +50,7 @@ func (o *okx) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (exch
 
 	r.SetParams(params)
 
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return exchange.GetDepthResponse{}, err
 	}
@@ -62,7 +61,7 @@ func (o *okx) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (exch
 	}
 
 	if response.Code != "0" {
-		return exchange.GetDepthResponse{}, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return exchange.GetDepthResponse{}, okError(response.Code, response.Msg)
 	}
 
 	if response.Data == nil || len(response.Data) == 0 {
@@ -145,7 +144,7 @@ func (o *okx) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([]exc
 
 	o.client.SetApiEndpoint(okEndpoint)
 
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -156,7 +155,7 @@ func (o *okx) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([]exc
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return nil, okError(response.Code, response.Msg)
 	}
 
 	var assets []exchange.Asset
@@ -212,7 +211,7 @@ func (o *okx) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceK
 	}
 
 	r.SetParams(params)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +221,7 @@ func (o *okx) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceK
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return nil, okError(response.Code, response.Msg)
 	}
 
 	var klines []exchange.GetMarkPriceKlineResponse
@@ -261,146 +260,129 @@ func (o *okx) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceK
 	return klines, nil
 }
 
-func (o *okx) GetKline(ctx context.Context, req *exchange.GetKlineRequest) ([]exchange.GetKlineResponse, error) {
+func (o *okx) GetTickerPrice(ctx context.Context, symbol string, marketType exchange.MarketType) (decimal.Decimal, error) {
 	r := &okhttp.Request{
 		Method:   "GET",
-		Endpoint: "/api/v5/market/candles",
+		Endpoint: "/api/v5/market/ticker",
 		SecType:  okhttp.SecTypeNone,
 	}
 
 	o.client.SetApiEndpoint(okEndpoint)
 
 	params := okhttp.Params{
-		"instId": req.Symbol.OriginalSymbol,
-		"bar":    strings.ToUpper(req.Period),
-	}
-
-	if req.Start > 0 {
-		params["before"] = req.Start
-	}
-
-	if req.End > 0 {
-		params["after"] = req.End
+		"instId": symbol,
 	}
 
-	if req.Limit > 0 {
-		params["limit"] = req.Limit
+	if marketType == exchange.MarketTypeSpot {
+		params["instType"] = "SPOT"
+	} else if marketType.IsContract() {
+		params["instType"] = "FUTURES"
+	} else {
+		return decimal.Zero, exchange.ErrInstrumentTypeNotSupported
 	}
 
 	r.SetParams(params)
-
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
-		return nil, err
+		return decimal.Zero, err
 	}
 
-	var response KlineResponse
+	var response TickerPriceResponse
 	if err := json.Unmarshal(data, &response); err != nil {
-		return nil, fmt.Errorf("error parsing response data: %v", err)
+		return decimal.Zero, fmt.Errorf("error parsing response data: %v", err)
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return decimal.Zero, okError(response.Code, response.Msg)
 	}
 
-	if len(response.Data) == 0 {
-		return nil, fmt.Errorf("no data")
+	price, err := decimal.NewFromString(response.Data[0].Last)
+	if err != nil {
+		return decimal.Zero, err
 	}
 
-	var klines []exchange.GetKlineResponse
-	for _, item := range response.Data {
-		openTime, err := strconv.ParseInt(item[0], 10, 64)
+	return price, nil
+}
+
+// Symbols 按市场类型返回 U 本位或币本位合约，数量相关字段单位为张
+func (o *okx) Symbols(ctx context.Context, marketType exchange.MarketType) ([]exchange.Symbol, error) {
+	instType := OkxInstType(marketType)
+	if instType == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	response, err := o.getInstruments(ctx, instType, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]exchange.Symbol, 0, len(response.Data))
+	for _, v := range response.Data {
+		// U 本位合约为 linear，币本位合约为 inverse
+		if (instType == "SWAP" || instType == "FUTURES") && (v.CtType == "inverse") != marketType.IsCoinMargined() {
+			continue
+		}
+		base, quote := v.BaseCcy, v.QuoteCcy
+		if parts := strings.Split(v.InstID, "-"); len(parts) >= 2 {
+			base, quote = parts[0], parts[1]
+		}
+		minSize, err := decimal.NewFromString(v.MinSz)
 		if err != nil {
 			return nil, err
 		}
-		open, err := decimal.NewFromString(item[1])
+		maxSize, err := decimal.NewFromString(v.MaxLmtSz)
 		if err != nil {
-			return nil, err
+			maxSize = decimal.Zero
 		}
-		high, err := decimal.NewFromString(item[2])
+		ctVal, err := decimal.NewFromString(v.CtVal)
 		if err != nil {
-			return nil, err
+			ctVal = decimal.Zero
 		}
-		low, err := decimal.NewFromString(item[3])
+		ctMult, err := decimal.NewFromString(v.CtMult)
 		if err != nil {
-			return nil, err
+			ctMult = decimal.Zero
 		}
-		close, err := decimal.NewFromString(item[4])
+		listTime, err := strconv.ParseInt(v.ListTime, 10, 64)
 		if err != nil {
-			return nil, err
+			listTime = 0
 		}
-		volume, err := decimal.NewFromString(item[5])
+		expTime, err := strconv.ParseInt(v.ExpTime, 10, 64)
 		if err != nil {
-			return nil, err
+			expTime = 0
 		}
-		if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
-			volume, err = decimal.NewFromString(item[6])
-			if err != nil {
-				return nil, err
-			}
+		tickSize, err := decimal.NewFromString(v.TickSz)
+		if err != nil {
+			return nil, err
 		}
-		quoteVolume, err := decimal.NewFromString(item[7])
+		stepSize, err := decimal.NewFromString(v.LotSz)
 		if err != nil {
 			return nil, err
 		}
-		kline := exchange.GetKlineResponse{
-			OpenTime:    openTime,
-			Open:        open,
-			High:        high,
-			Low:         low,
-			Close:       close,
-			Volume:      volume,
-			QuoteVolume: quoteVolume,
-			Confirm:     item[8],
+		status := exchange.SymbolStatusDisabled
+		if v.State == "live" {
+			status = exchange.SymbolStatusEnabled
 		}
-		klines = append(klines, kline)
-	}
-
-	return klines, nil
-}
-
-func (o *okx) GetTickerPrice(ctx context.Context, symbol string, marketType exchange.MarketType) (decimal.Decimal, error) {
-	r := &okhttp.Request{
-		Method:   "GET",
-		Endpoint: "/api/v5/market/ticker",
-		SecType:  okhttp.SecTypeNone,
-	}
-
-	o.client.SetApiEndpoint(okEndpoint)
-
-	params := okhttp.Params{
-		"instId": symbol,
-	}
-
-	if marketType == exchange.MarketTypeSpot {
-		params["instType"] = "SPOT"
-	} else if marketType == exchange.MarketTypeFuturesUSDMargined || marketType == exchange.MarketTypePerpetualUSDMargined {
-		params["instType"] = "FUTURES"
-	} else {
-		return decimal.Zero, exchange.ErrInstrumentTypeNotSupported
-	}
-
-	r.SetParams(params)
-	data, err := o.client.CallAPI(ctx, r)
-	if err != nil {
-		return decimal.Zero, err
-	}
-
-	var response TickerPriceResponse
-	if err := json.Unmarshal(data, &response); err != nil {
-		return decimal.Zero, fmt.Errorf("error parsing response data: %v", err)
-	}
-
-	if response.Code != "0" {
-		return decimal.Zero, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
-	}
-
-	price, err := decimal.NewFromString(response.Data[0].Last)
-	if err != nil {
-		return decimal.Zero, err
+		result = append(result, exchange.Symbol{
+			OriginalSymbol: v.InstID,
+			UnifiedSymbol:  exchange.UnifiedSymbolName(base, quote, marketType, expTime),
+			OriginalAsset:  base,
+			UnifiedAsset:   base,
+			QuoteAsset:     quote,
+			Exchange:       exchange.OkxExchange,
+			MarketType:     marketType,
+			Status:         status,
+			MinSize:        minSize,
+			MaxSize:        maxSize,
+			PricePrecision: exchange.StepPrecision(v.TickSz),
+			SizePrecision:  exchange.StepPrecision(v.LotSz),
+			TickSize:       tickSize,
+			StepSize:       stepSize,
+			CtVal:          ctVal,
+			CtMult:         ctMult,
+			ListTime:       listTime,
+			ExpTime:        expTime,
+		})
 	}
-
-	return price, nil
+	return result, nil
 }
 
 func (o *okx) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConfigRequest) (exchange.GetAccountConfigResponse, error) {
@@ -415,7 +397,7 @@ func (o *okx) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConf
 
 	o.client.SetApiEndpoint(okEndpoint)
 
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return exchange.GetAccountConfigResponse{}, err
 	}
@@ -426,7 +408,7 @@ func (o *okx) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConf
 	}
 
 	if response.Code != "0" {
-		return exchange.GetAccountConfigResponse{}, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return exchange.GetAccountConfigResponse{}, okError(response.Code, response.Msg)
 	}
 
 	if response.Data == nil || len(response.Data) == 0 {
@@ -441,7 +423,11 @@ func (o *okx) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConf
 	}, nil
 }
 
-func (o *okx) CreateOrder(ctx context.Context, req *exchange.CreateOrderRequest) error {
+// CreateOrder okx 下单接口只返回订单号，市价单和 IOC/FOK 订单会再查询一次订单获取成交结果；条件单使用策略委托
+func (o *okx) CreateOrder(ctx context.Context, req *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	if isOkAlgoOrder(req.OrderType) {
+		return o.createAlgoOrder(ctx, req)
+	}
 	r := &okhttp.Request{
 		APIKey:     req.APIKey,
 		SecretKey:  req.SecretKey,
@@ -455,22 +441,20 @@ func (o *okx) CreateOrder(ctx context.Context, req *exchange.CreateOrderRequest)
 
 	params, err := o.toOrderParams(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	r = r.SetJSONBody(params)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var responseData CreateOrderResponse
 	if err := json.Unmarshal(data, &responseData); err != nil {
-		return fmt.Errorf("error parsing response data: %v", err)
+		return nil, fmt.Errorf("error parsing response data: %v", err)
 	}
 
-	fmt.Println("创建订单结果:", responseData)
-
 	// 检查 code 值
 	if responseData.Code != "0" || len(responseData.Data) == 0 || responseData.Data[0].SCode != "0" {
 		// 处理错误或特定条件
@@ -480,9 +464,72 @@ func (o *okx) CreateOrder(ctx context.Context, req *exchange.CreateOrderRequest)
 			msg = responseData.Data[0].SMsg
 			code = responseData.Data[0].SCode
 		}
-		return fmt.Errorf("operation failed, code: %s, message: %s", code, msg)
+		return nil, okError(code, msg)
 	}
-	return nil
+
+	ts, err := strconv.ParseInt(responseData.Data[0].Ts, 10, 64)
+	if err != nil {
+		ts = 0
+	}
+	ack := &exchange.CreateOrderResponse{
+		TransactTime:     ts,
+		Symbol:           req.Symbol.OriginalSymbol,
+		ClientOrderID:    responseData.Data[0].ClOrdId,
+		OrderID:          responseData.Data[0].OrdId,
+		Side:             req.Side,
+		State:            exchange.OrderStateNew,
+		PositionSide:     req.PositionSide,
+		Price:            req.Price,
+		OriginalQuantity: req.Size,
+	}
+	if req.OrderType != exchange.OrderTypeMarket && req.TimeInForce != exchange.TimeInForceIOC && req.TimeInForce != exchange.TimeInForceFOK {
+		return ack, nil
+	}
+	order, err := o.getOrder(ctx, req.APIKey, req.SecretKey, req.Passphrase, req.Symbol.OriginalSymbol, ack.OrderID, req.Symbol.CtVal)
+	if err != nil {
+		// 订单已提交成功，查询失败时仍返回下单回执
+		return ack, nil
+	}
+	ack.State = order.State
+	ack.AvgPrice = order.AvgPrice
+	ack.ExecutedQuantity = order.FilledVolume
+	if order.UpdateTime > 0 {
+		ack.TransactTime = order.UpdateTime
+	}
+	return ack, nil
+}
+
+// getOrder 按交易所订单号查询订单
+func (o *okx) getOrder(ctx context.Context, apiKey, secretKey, passphrase, instId, ordId string, ctVal decimal.Decimal) (*exchange.SearchOrderResponse, error) {
+	r := &okhttp.Request{
+		APIKey:     apiKey,
+		SecretKey:  secretKey,
+		Passphrase: passphrase,
+		Method:     "GET",
+		Endpoint:   "/api/v5/trade/order",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	r.SetParams(okhttp.Params{
+		"instId": instId,
+		"ordId":  ordId,
+	})
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response OrdersResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	if len(response.Data) == 0 {
+		return nil, exchange.ErrOrderNotFound
+	}
+	return o.toSearchOrderResponse(&response.Data[0], ctVal)
 }
 
 func (o *okx) CancelOrder(ctx context.Context, req *exchange.CancelOrderRequest) error {
@@ -497,30 +544,377 @@ func (o *okx) CancelOrder(ctx context.Context, req *exchange.CancelOrderRequest)
 
 	o.client.SetApiEndpoint(okEndpoint)
 
+	r = r.SetJSONBody(toCancelOrderParams(req))
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var responseData CancelOrderResponse
+	if err := json.Unmarshal(data, &responseData); err != nil {
+		return fmt.Errorf("error parsing response data: %v", err)
+	}
+
+	// 撤单失败时 code 为 1，具体原因在 sCode 中
+	if responseData.Code != "0" {
+		if len(responseData.Data) > 0 && responseData.Data[0].SCode != "0" {
+			return okError(responseData.Data[0].SCode, responseData.Data[0].SMsg)
+		}
+		return okError(responseData.Code, responseData.Msg)
+	}
+
+	return nil
+}
+
+// BatchCreateOrders 批量下单，每次最多 20 笔；部分失败时 code 为 1 或 2，按 sCode 返回单个订单的错误
+func (o *okx) BatchCreateOrders(ctx context.Context, req []*exchange.CreateOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	result := make([]*exchange.BatchOrderResult, 0, len(req))
+	for i := 0; i < len(req); i += okBatchOrdersLimit {
+		chunk := req[i:min(i+okBatchOrdersLimit, len(req))]
+		params := make([]okhttp.Params, 0, len(chunk))
+		for _, v := range chunk {
+			if v.APIKey != req[0].APIKey {
+				return nil, errors.New("batch orders must share the same account")
+			}
+			p, err := o.toOrderParams(v)
+			if err != nil {
+				return nil, err
+			}
+			params = append(params, p)
+		}
+
+		r := &okhttp.Request{
+			APIKey:     req[0].APIKey,
+			SecretKey:  req[0].SecretKey,
+			Passphrase: req[0].Passphrase,
+			Method:     "POST",
+			Endpoint:   "/api/v5/trade/batch-orders",
+			SecType:    okhttp.SecTypeSigned,
+		}
+		o.client.SetApiEndpoint(okEndpoint)
+		r = r.SetJSONBody(params)
+		data, err := o.callAPI(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		var responseData CreateOrderResponse
+		if err := json.Unmarshal(data, &responseData); err != nil {
+			return nil, fmt.Errorf("error parsing response data: %v", err)
+		}
+		if len(responseData.Data) != len(chunk) {
+			return nil, okError(responseData.Code, responseData.Msg)
+		}
+		for j, v := range responseData.Data {
+			result = append(result, toBatchOrderResult(chunk[j].ClientOrderID, v.OrdId, v.SCode, v.SMsg))
+		}
+	}
+	return result, nil
+}
+
+// BatchCancelOrders 批量撤单，每次最多 20 笔
+func (o *okx) BatchCancelOrders(ctx context.Context, req []*exchange.CancelOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	result := make([]*exchange.BatchOrderResult, 0, len(req))
+	for i := 0; i < len(req); i += okBatchOrdersLimit {
+		chunk := req[i:min(i+okBatchOrdersLimit, len(req))]
+		params := make([]okhttp.Params, 0, len(chunk))
+		for _, v := range chunk {
+			if v.APIKey != req[0].APIKey {
+				return nil, errors.New("batch orders must share the same account")
+			}
+			params = append(params, toCancelOrderParams(v))
+		}
+
+		r := &okhttp.Request{
+			APIKey:     req[0].APIKey,
+			SecretKey:  req[0].SecretKey,
+			Passphrase: req[0].Passphrase,
+			Method:     "POST",
+			Endpoint:   "/api/v5/trade/cancel-batch-orders",
+			SecType:    okhttp.SecTypeSigned,
+		}
+		o.client.SetApiEndpoint(okEndpoint)
+		r = r.SetJSONBody(params)
+		data, err := o.callAPI(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		var responseData CancelOrderResponse
+		if err := json.Unmarshal(data, &responseData); err != nil {
+			return nil, fmt.Errorf("error parsing response data: %v", err)
+		}
+		if len(responseData.Data) != len(chunk) {
+			return nil, okError(responseData.Code, responseData.Msg)
+		}
+		for j, v := range responseData.Data {
+			// 撤单失败时 ordId 可能为空，使用请求中的订单ID
+			orderID := v.OrdId
+			if orderID == "" {
+				orderID = chunk[j].OrderID
+			}
+			result = append(result, toBatchOrderResult(chunk[j].ClientOrderID, orderID, v.SCode, v.SMsg))
+		}
+	}
+	return result, nil
+}
+
+// toCancelOrderParams 手动或其他工具下的订单没有 clOrdId，有订单ID时优先使用 ordId
+func toCancelOrderParams(req *exchange.CancelOrderRequest) okhttp.Params {
 	params := okhttp.Params{
 		"instId": req.Symbol,
 	}
+	if req.OrderID != "" {
+		params["ordId"] = req.OrderID
+	} else {
+		params["clOrdId"] = req.ClientOrderID
+	}
+	return params
+}
+
+// GetOpenOrders 分页查询未成交订单和未触发的策略委托，策略委托的订单号为 algoId，合约数量由张转换为币
+func (o *okx) GetOpenOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest) ([]*exchange.SearchOrderResponse, error) {
+	instType := OkxInstType(req.MarketType)
+	if instType == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	var ctVals map[string]decimal.Decimal
+	if instType == "SWAP" || instType == "FUTURES" {
+		var err error
+		ctVals, err = o.getCtVals(ctx, instType, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := o.getPendingOrders(ctx, req, instType)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*exchange.SearchOrderResponse, 0, len(orders))
+	for i := range orders {
+		order, err := o.toSearchOrderResponse(&orders[i], ctVals[orders[i].InstID])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, order)
+	}
+
+	algoOrders, err := o.getPendingAlgoOrders(ctx, req, instType)
+	if err != nil {
+		return nil, err
+	}
+	for i := range algoOrders {
+		order, err := toAlgoSearchOrderResponse(&algoOrders[i], ctVals[algoOrders[i].InstID])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, order)
+	}
+	return result, nil
+}
+
+// getPendingOrders 分页查询未成交订单，SWAP 和 FUTURES 同时返回 U 本位和币本位合约，只保留与市场类型一致的订单
+func (o *okx) getPendingOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest, instType string) ([]OrderInfo, error) {
+	result := make([]OrderInfo, 0)
+	after := ""
+	for {
+		r := &okhttp.Request{
+			APIKey:     req.APIKey,
+			SecretKey:  req.SecretKey,
+			Passphrase: req.Passphrase,
+			Method:     "GET",
+			Endpoint:   "/api/v5/trade/orders-pending",
+			SecType:    okhttp.SecTypeSigned,
+		}
+		o.client.SetApiEndpoint(okEndpoint)
+		params := okhttp.Params{
+			"instType": instType,
+			"limit":    okOrdersPageLimit,
+		}
+		if req.Symbol != "" {
+			params["instId"] = req.Symbol
+		}
+		if after != "" {
+			params["after"] = after
+		}
+		r.SetParams(params)
+		data, err := o.callAPI(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		var response OrdersResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("error parsing response data: %v", err)
+		}
+		if response.Code != "0" {
+			return nil, okError(response.Code, response.Msg)
+		}
+		for _, v := range response.Data {
+			if okMatchMarketType(v.InstID, req.MarketType) {
+				result = append(result, v)
+			}
+		}
+		if len(response.Data) < okOrdersPageLimit {
+			break
+		}
+		after = response.Data[len(response.Data)-1].OrderID
+	}
+	return result, nil
+}
+
+// CancelAllOrders okx 没有撤销全部挂单接口，查询挂单和策略委托后分别批量撤销
+func (o *okx) CancelAllOrders(ctx context.Context, req *exchange.CancelAllOrdersRequest) error {
+	instType := OkxInstType(req.MarketType)
+	if instType == "" {
+		return exchange.ErrInstrumentTypeNotSupported
+	}
+	query := &exchange.GetOpenOrdersRequest{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Symbol:     req.Symbol,
+		MarketType: req.MarketType,
+	}
+	orders, err := o.getPendingOrders(ctx, query, instType)
+	if err != nil {
+		return err
+	}
+	algoOrders, err := o.getPendingAlgoOrders(ctx, query, instType)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, 0)
+	if len(orders) > 0 {
+		cancels := make([]*exchange.CancelOrderRequest, 0, len(orders))
+		for _, v := range orders {
+			cancels = append(cancels, &exchange.CancelOrderRequest{
+				APIKey:        req.APIKey,
+				SecretKey:     req.SecretKey,
+				Passphrase:    req.Passphrase,
+				ClientOrderID: v.ClientOrderID,
+				OrderID:       v.OrderID,
+				Symbol:        v.InstID,
+				MarketType:    req.MarketType,
+			})
+		}
+		results, err := o.BatchCancelOrders(ctx, cancels)
+		if err != nil {
+			return err
+		}
+		for _, v := range results {
+			if v.Err != nil {
+				errs = append(errs, fmt.Errorf("cancel order %s failed: %w", v.OrderID, v.Err))
+			}
+		}
+	}
+	if len(algoOrders) > 0 {
+		if err := o.cancelAlgoOrders(ctx, req.APIKey, req.SecretKey, req.Passphrase, algoOrders); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AmendOrder 修改未成交订单，okx 改单不能修改 clOrdId，不支持 NewClientOrderID
+func (o *okx) AmendOrder(ctx context.Context, req *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	if req.ClientOrderID == "" && req.OrderID == "" {
+		return nil, errors.New("client order id or order id is required")
+	}
+	if req.NewClientOrderID != "" {
+		return nil, errors.New("okx amend order does not support new client order id")
+	}
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/trade/amend-order",
+		SecType:    okhttp.SecTypeSigned,
+	}
 
+	o.client.SetApiEndpoint(okEndpoint)
+
+	params := okhttp.Params{
+		"instId": req.Symbol.OriginalSymbol,
+	}
 	if req.ClientOrderID != "" {
 		params["clOrdId"] = req.ClientOrderID
+	} else {
+		params["ordId"] = req.OrderID
+	}
+	if !req.NewSize.IsZero() {
+		sz := req.NewSize.String()
+		if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+			// 合约类型要将币转位张
+			opType := "open"
+			if req.Side == exchange.SideTypeSell && req.PositionSide == exchange.PositionSideLong ||
+				req.Side == exchange.SideTypeBuy && req.PositionSide == exchange.PositionSideShort {
+				opType = "close"
+			}
+			var err error
+			sz, err = o.ConvertContractCoin("1", req.Symbol, sz, opType)
+			if err != nil {
+				return nil, err
+			}
+		}
+		params["newSz"] = sz
+	}
+	if !req.NewPrice.IsZero() {
+		params["newPx"] = req.NewPrice.String()
 	}
 
 	r = r.SetJSONBody(params)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var responseData CancelOrderResponse
+	var responseData AmendOrderResponse
 	if err := json.Unmarshal(data, &responseData); err != nil {
-		return fmt.Errorf("error parsing response data: %v", err)
+		return nil, fmt.Errorf("error parsing response data: %v", err)
 	}
 
-	if responseData.Code != "0" {
-		return fmt.Errorf("operation failed, code: %s, message: %s", responseData.Code, responseData.Msg)
+	if responseData.Code != "0" || len(responseData.Data) == 0 || responseData.Data[0].SCode != "0" {
+		msg := responseData.Msg
+		code := responseData.Code
+		if len(responseData.Data) > 0 {
+			msg = responseData.Data[0].SMsg
+			code = responseData.Data[0].SCode
+		}
+		return nil, okError(code, msg)
+	}
+
+	res := responseData.Data[0]
+	ts, _ := strconv.ParseInt(res.Ts, 10, 64)
+	// 改单成功后订单仍为挂单状态，实际成交情况以订单推送为准
+	ack := &exchange.AmendOrderResponse{
+		Symbol:        req.Symbol.OriginalSymbol,
+		ClientOrderID: res.ClOrdId,
+		OrderID:       res.OrdId,
+		State:         exchange.OrderStateNew,
+		Price:         req.NewPrice,
+		Size:          req.NewSize,
+		UpdateTime:    ts,
+	}
+	if !req.NewPrice.IsZero() && !req.NewSize.IsZero() {
+		return ack, nil
+	}
+	// 改单异步生效，未修改的价格或数量从订单查询中获取，查询失败时只返回已修改的字段
+	order, err := o.getOrder(ctx, req.APIKey, req.SecretKey, req.Passphrase, req.Symbol.OriginalSymbol, res.OrdId, req.Symbol.CtVal)
+	if err != nil {
+		return ack, nil
 	}
-
-	return nil
+	if req.NewPrice.IsZero() {
+		ack.Price = order.Price
+	}
+	if req.NewSize.IsZero() {
+		ack.Size = order.Volume
+	}
+	ack.State = order.State
+	return ack, nil
 }
 
 func (o *okx) SearchOrder(ctx context.Context, req *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
@@ -543,7 +937,7 @@ func (o *okx) SearchOrder(ctx context.Context, req *exchange.SearchOrderRequest)
 	// var err error
 
 	r.SetParams(params)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -590,8 +984,8 @@ func (o *okx) SearchOrder(ctx context.Context, req *exchange.SearchOrderRequest)
 		return nil, err
 	}
 
-	if orderInfo.InstType == "FUTURES" || orderInfo.InstType == "SWAP" {
-		// 合约类型要将张转位币
+	if (orderInfo.InstType == "FUTURES" || orderInfo.InstType == "SWAP") && !okIsInverse(orderInfo.InstID) {
+		// U 本位合约要将张转位币
 		orderInfo.AccFillSz, err = o.ConvertContractCoin("2", req.Symbol, orderInfo.AccFillSz, "close")
 		if err != nil {
 			return nil, err
@@ -646,20 +1040,69 @@ func (o *okx) SearchTrades(ctx context.Context, req *exchange.SearchTradesReques
 	return nil, nil
 }
 
+// GetFundingRate 获取永续合约当前资金费率，Symbol 为空时获取全部
 func (o *okx) GetFundingRate(ctx context.Context, req *exchange.GetFundingRate) ([]*exchange.GetFundingRateResponse, error) {
-	return nil, nil
-}
-
-func (o *okx) GetMarginInterestRate(ctx context.Context, req *exchange.GetMarginInterestRateRequest) ([]*exchange.GetMarginInterestRateResponse, error) {
-	return nil, nil
-}
+	r := &okhttp.Request{
+		Method:   "GET",
+		Endpoint: "/api/v5/public/funding-rate",
+		SecType:  okhttp.SecTypeNone,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	instId := req.Symbol
+	if instId == "" {
+		instId = "ANY"
+	}
+	r.SetParams(okhttp.Params{"instId": instId})
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
 
-func (o *okx) MarginBorrowOrRepay(ctx context.Context, req *exchange.MarginBorrowOrRepayRequest) error {
-	return nil
+	var response FundingRateResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	res := make([]*exchange.GetFundingRateResponse, 0, len(response.Data))
+	for i := range response.Data {
+		rate, err := toFundingRateResponse(&response.Data[i])
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, rate)
+	}
+	return res, nil
 }
 
-func (o *okx) GetMarginInventory(ctx context.Context, req *exchange.MarginInventoryRequest) (*exchange.MarginInventory, error) {
-	return nil, nil
+// toFundingRateResponse okx 的 fundingRate 为下一次结算的预测资金费率，settFundingRate 为最近结算的资金费率
+func toFundingRateResponse(info *FundingRateInfo) (*exchange.GetFundingRateResponse, error) {
+	nextRate, err := decimal.NewFromString(info.FundingRate)
+	if err != nil {
+		return nil, err
+	}
+	lastRate := decimal.Zero
+	if info.SettFundingRate != "" {
+		if lastRate, err = decimal.NewFromString(info.SettFundingRate); err != nil {
+			return nil, err
+		}
+	}
+	fundingTime, err := strconv.ParseInt(info.FundingTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	ts, err := strconv.ParseInt(info.Ts, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.GetFundingRateResponse{
+		Symbol:          info.InstID,
+		LastFundingRate: lastRate,
+		NextFundingRate: nextRate,
+		NextFundingTime: fundingTime,
+		Time:            ts,
+	}, nil
 }
 
 func (o *okx) GetPosition(ctx context.Context, req *exchange.GetPositionRequest) ([]*exchange.GetPositionResponse, error) {
@@ -675,7 +1118,7 @@ func (o *okx) GetPosition(ctx context.Context, req *exchange.GetPositionRequest)
 	r.SetParam("instId", req.Symbol)
 
 	o.client.SetApiEndpoint(okEndpoint)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -686,7 +1129,7 @@ func (o *okx) GetPosition(ctx context.Context, req *exchange.GetPositionRequest)
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return nil, okError(response.Code, response.Msg)
 	}
 
 	var positions []*exchange.GetPositionResponse
@@ -767,14 +1210,7 @@ func (o *okx) GetPosition(ctx context.Context, req *exchange.GetPositionRequest)
 			return nil, err
 		}
 
-		marketType := exchange.MarketTypeSpot
-		if item.InstType == "SWAP" {
-			marketType = exchange.MarketTypePerpetualUSDMargined
-		} else if item.InstType == "FUTURES" {
-			marketType = exchange.MarketTypeFuturesUSDMargined
-		} else if item.InstType == "MARGIN" {
-			marketType = exchange.MarketTypeMargin
-		}
+		marketType := OkxMarketType(item.InstType, item.InstID)
 
 		position := exchange.GetPositionResponse{
 			Symbol:       item.InstID,
@@ -813,7 +1249,7 @@ func (o *okx) GetHistoryPosition(ctx context.Context, req *exchange.GetPositionH
 	}
 	r.SetParam("before", "1725942111000")
 	o.client.SetApiEndpoint(okEndpoint)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return err
 	}
@@ -837,7 +1273,7 @@ func (o *okx) SetLeverage(ctx context.Context, req *exchange.SetLeverageRequest)
 	}
 	r.SetJSONBody(params)
 	o.client.SetApiEndpoint(okEndpoint)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return err
 	}
@@ -848,15 +1284,94 @@ func (o *okx) SetLeverage(ctx context.Context, req *exchange.SetLeverageRequest)
 	}
 
 	if response.Code != "0" {
-		return fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return okError(response.Code, response.Msg)
 	}
 
 	if response.Data == nil || len(response.Data) == 0 {
-		return fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return okError(response.Code, response.Msg)
 	}
 
 	if response.Data[0].Lever != req.Lever {
-		return fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return okError(response.Code, response.Msg)
+	}
+	return nil
+}
+
+// SetPositionMode 设置合约持仓模式，对账户下所有合约生效
+func (o *okx) SetPositionMode(ctx context.Context, req *exchange.SetPositionModeRequest) error {
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/account/set-position-mode",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	r.SetJSONBody(toPositionModeParams(req.Mode))
+	o.client.SetApiEndpoint(okEndpoint)
+	return o.callBaseAPI(ctx, r)
+}
+
+func toPositionModeParams(mode exchange.PositionMode) okhttp.Params {
+	posMode := "net_mode"
+	if mode == exchange.PositionModeHedge {
+		posMode = "long_short_mode"
+	}
+	return okhttp.Params{"posMode": posMode}
+}
+
+// SetMarginMode okx 的保证金模式在下单时通过 tdMode 指定，总是返回 exchange.ErrMarginModePerOrder
+func (o *okx) SetMarginMode(ctx context.Context, req *exchange.SetMarginModeRequest) error {
+	return exchange.ErrMarginModePerOrder
+}
+
+// AdjustPositionMargin 调整逐仓仓位保证金，单向持仓的 posSide 为 net
+func (o *okx) AdjustPositionMargin(ctx context.Context, req *exchange.AdjustPositionMarginRequest) error {
+	if req.Amount.IsZero() {
+		return errors.New("amount is required")
+	}
+	r := &okhttp.Request{
+		APIKey:     req.APIKey,
+		SecretKey:  req.SecretKey,
+		Passphrase: req.Passphrase,
+		Method:     "POST",
+		Endpoint:   "/api/v5/account/position/margin-balance",
+		SecType:    okhttp.SecTypeSigned,
+	}
+	r.SetJSONBody(toPositionMarginParams(req))
+	o.client.SetApiEndpoint(okEndpoint)
+	return o.callBaseAPI(ctx, r)
+}
+
+// toPositionMarginParams 正数增加保证金，负数减少保证金
+func toPositionMarginParams(req *exchange.AdjustPositionMarginRequest) okhttp.Params {
+	params := okhttp.Params{
+		"instId":  req.Symbol.OriginalSymbol,
+		"posSide": "net",
+		"type":    "add",
+		"amt":     req.Amount.Abs().String(),
+	}
+	if req.PositionSide != "" {
+		params["posSide"] = OkxPositionSide(req.PositionSide)
+	}
+	if req.Amount.IsNegative() {
+		params["type"] = "reduce"
+	}
+	return params
+}
+
+// callBaseAPI 调用只需要检查 code 的接口
+func (o *okx) callBaseAPI(ctx context.Context, r *okhttp.Request) error {
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return err
+	}
+	var response BaseResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return okError(response.Code, response.Msg)
 	}
 	return nil
 }
@@ -873,7 +1388,7 @@ func (o *okx) GetLeverage(ctx context.Context, req *exchange.GetLeverageRequest)
 	r.SetParam("instId", req.Symbol)
 	r.SetParam("mgnMode", OkxPosMode(exchange.PosModeCross))
 	o.client.SetApiEndpoint(okEndpoint)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return exchange.GetLeverageResponse{}, err
 	}
@@ -884,11 +1399,11 @@ func (o *okx) GetLeverage(ctx context.Context, req *exchange.GetLeverageRequest)
 	}
 
 	if response.Code != "0" {
-		return exchange.GetLeverageResponse{}, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return exchange.GetLeverageResponse{}, okError(response.Code, response.Msg)
 	}
 
 	if response.Data == nil || len(response.Data) == 0 {
-		return exchange.GetLeverageResponse{}, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return exchange.GetLeverageResponse{}, okError(response.Code, response.Msg)
 	}
 
 	return exchange.GetLeverageResponse{
@@ -916,7 +1431,7 @@ func (o *okx) GetMaxSize(ctx context.Context, req *exchange.GetMaxSizeRequest) (
 		"leverage": req.Leverage,
 	}
 	r.SetParams(httpParams)
-	data, err := o.client.CallAPI(ctx, r)
+	data, err := o.callAPI(ctx, r)
 	if err != nil {
 		return nil, err
 	}
@@ -927,7 +1442,7 @@ func (o *okx) GetMaxSize(ctx context.Context, req *exchange.GetMaxSizeRequest) (
 	}
 
 	if response.Code != "0" {
-		return nil, fmt.Errorf("operation failed, code: %s, message: %s", response.Code, response.Msg)
+		return nil, okError(response.Code, response.Msg)
 	}
 
 	if response.Data == nil || len(response.Data) == 0 {
@@ -957,6 +1472,7 @@ func (o *okx) GetMaxSize(ctx context.Context, req *exchange.GetMaxSizeRequest) (
 }
 
 // typ：1-币转账 2-张转币; symbol: 交易对; sz：数量; opTyp: open（舍位），close（四舍五入）
+// 币本位合约面值以美元计价，按标记价格换算
 func (o *okx) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string, opTyp string) (string, error) {
 	if opTyp == "" {
 		opTyp = "open"
@@ -966,6 +1482,9 @@ func (o *okx) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string,
 	if err != nil {
 		return "", err
 	}
+	if symbol.MarketType.IsCoinMargined() || okIsInverse(symbol.OriginalSymbol) {
+		return o.convertInverseContractCoin(typ, symbol, size, opTyp)
+	}
 	if typ == "1" {
 		// 币转张, 数量除以张的面值
 		if symbol.CtVal.IsZero() {
@@ -982,6 +1501,32 @@ func (o *okx) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string,
 	return "", fmt.Errorf("invalid type: %v", typ)
 }
 
+func (o *okx) convertInverseContractCoin(typ string, symbol exchange.Symbol, size decimal.Decimal, opTyp string) (string, error) {
+	instType := "FUTURES"
+	if strings.HasSuffix(symbol.OriginalSymbol, "-SWAP") {
+		instType = "SWAP"
+	}
+	price, err := o.getMarketPrice(symbol.OriginalSymbol, instType)
+	if err != nil {
+		return "", err
+	}
+	switch typ {
+	case "1":
+		contracts, err := exchange.CoinToContract(symbol, size, price)
+		if err != nil {
+			return "", err
+		}
+		return o.sizePrecision(contracts, symbol, opTyp).String(), nil
+	case "2":
+		coins, err := exchange.ContractToCoin(symbol, size, price)
+		if err != nil {
+			return "", err
+		}
+		return coins.String(), nil
+	}
+	return "", fmt.Errorf("invalid type: %v", typ)
+}
+
 func (o *okx) getMarketPrice(instId string, instType string) (decimal.Decimal, error) {
 	r := &okhttp.Request{
 		Method:   "GET",
@@ -992,7 +1537,7 @@ func (o *okx) getMarketPrice(instId string, instType string) (decimal.Decimal, e
 		"instType": instType,
 	})
 	o.client.SetApiEndpoint(okEndpoint)
-	data, err := o.client.CallAPI(context.Background(), r)
+	data, err := o.callAPI(context.Background(), r)
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -1017,16 +1562,144 @@ func (o *okx) getMarketPrice(instId string, instType string) (decimal.Decimal, e
 	return mkp, nil
 }
 
+// getInstruments 获取产品信息，instId 为空时获取该产品类型下全部产品
+func (o *okx) getInstruments(ctx context.Context, instType string, instId string) (*InstrumentsResponse, error) {
+	r := &okhttp.Request{
+		Method:   "GET",
+		Endpoint: "/api/v5/public/instruments",
+		SecType:  okhttp.SecTypeNone,
+	}
+	o.client.SetApiEndpoint(okEndpoint)
+	params := okhttp.Params{
+		"instType": instType,
+	}
+	if instId != "" {
+		params["instId"] = instId
+	}
+	r.SetParams(params)
+	data, err := o.callAPI(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var response InstrumentsResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error parsing response data: %v", err)
+	}
+	if response.Code != "0" {
+		return nil, okError(response.Code, response.Msg)
+	}
+	return &response, nil
+}
+
+// getCtVals 获取合约面值，instId 为空时获取该产品类型下全部合约；
+// 币本位合约的数量以张为单位，不需要转换，不返回面值
+func (o *okx) getCtVals(ctx context.Context, instType string, instId string) (map[string]decimal.Decimal, error) {
+	response, err := o.getInstruments(ctx, instType, instId)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]decimal.Decimal, len(response.Data))
+	for _, v := range response.Data {
+		if v.CtType == "inverse" {
+			continue
+		}
+		ctVal, err := decimal.NewFromString(v.CtVal)
+		if err != nil {
+			return nil, err
+		}
+		result[v.InstID] = ctVal
+	}
+	return result, nil
+}
+
+// toSearchOrderResponse ctVal 不为零时将 U 本位合约张数转换为币的数量
+func (o *okx) toSearchOrderResponse(info *OrderInfo, ctVal decimal.Decimal) (*exchange.SearchOrderResponse, error) {
+	size, err := decimal.NewFromString(info.Sz)
+	if err != nil {
+		return nil, err
+	}
+	filledVolume, err := decimal.NewFromString(info.AccFillSz)
+	if err != nil {
+		return nil, err
+	}
+	if !ctVal.IsZero() && !okIsInverse(info.InstID) {
+		size = size.Mul(ctVal)
+		filledVolume = filledVolume.Mul(ctVal)
+	}
+	avgPrice, err := decimal.NewFromString(info.AvgPx)
+	if err != nil {
+		avgPrice = decimal.Zero
+	}
+	px, err := decimal.NewFromString(info.Px)
+	if err != nil {
+		px = decimal.Zero
+	}
+	fee, err := decimal.NewFromString(info.Fee)
+	if err != nil {
+		fee = decimal.Zero
+	}
+	updateTime, err := strconv.ParseInt(info.UpdateTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	createdTime, err := strconv.ParseInt(info.CreateTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchange.SearchOrderResponse{
+		OrderID:           info.OrderID,
+		ClientOrderID:     info.ClientOrderID,
+		State:             OkxTOrderState(info.State),
+		Symbol:            info.InstID,
+		AvgPrice:          avgPrice,
+		Volume:            size,
+		Price:             px,
+		FilledQuoteVolume: filledVolume.Mul(avgPrice),
+		FilledVolume:      filledVolume,
+		FeeCost:           fee,
+		FeeAsset:          info.FeeCcy,
+		Side:              OkxTSide(info.Side),
+		PositionSide:      OkxTPositionSide(info.PosSide),
+		OrderType:         OkxTOrderType(info.OrderType),
+		CreatedTime:       createdTime,
+		UpdateTime:        updateTime,
+	}, nil
+}
+
 func (o *okx) toOrderParams(req *exchange.CreateOrderRequest) (okhttp.Params, error) {
+	// okx 不支持按盘口价格下单，平仓只支持市价止盈止损策略委托，现货没有只减仓；
+	// okx 没有 GTD 订单，请求头 expTime 只是下单请求的截止时间，已挂出的订单不会过期
+	if req.PriceMatch != "" || req.TimeInForce == exchange.TimeInForceGTD ||
+		req.ClosePosition && req.OrderType != exchange.OrderTypeStopMarket && req.OrderType != exchange.OrderTypeTakeProfitMarket ||
+		req.ReduceOnly && req.MarketType == exchange.MarketTypeSpot {
+		return nil, exchange.ErrOrderOptionNotSupported
+	}
 	m := okhttp.Params{
 		"instId":  req.Symbol.OriginalSymbol,
 		"side":    OkxSide(req.Side),
 		"ordType": OkxOrderType(req.OrderType),
 	}
+	// okx 限价单的有效方式通过 ordType 指定
+	if req.OrderType == exchange.OrderTypeLimit {
+		switch req.TimeInForce {
+		case exchange.TimeInForceIOC:
+			m["ordType"] = "ioc"
+		case exchange.TimeInForceFOK:
+			m["ordType"] = "fok"
+		case exchange.TimeInForceGTX:
+			m["ordType"] = "post_only"
+		}
+	}
 
-	if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+	if req.MarketType.IsCoinMargined() {
+		// 币本位合约数量以张为单位
+		m["tdMode"] = okTdMode(req.MarginMode)
+		m["sz"] = req.Size.String()
+	} else if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
 		// 合约类型要将币转位张
-		m["tdMode"] = OkxPosMode(exchange.PosModeCross) // 默认全仓
+		m["tdMode"] = okTdMode(req.MarginMode)
 		opType := "open"
 		if req.Side == exchange.SideTypeSell && req.PositionSide == exchange.PositionSideLong ||
 			req.Side == exchange.SideTypeBuy && req.PositionSide == exchange.PositionSideShort {
@@ -1045,7 +1718,7 @@ func (o *okx) toOrderParams(req *exchange.CreateOrderRequest) (okhttp.Params, er
 		m["sz"] = fmt.Sprintf("%v", req.Size)
 	} else if req.MarketType == exchange.MarketTypeMargin {
 		// okx 杠杠买入时，size 为 计价货币，所以这里要转换
-		m["tdMode"] = OkxPosMode(exchange.PosModeCross) // 默认全仓
+		m["tdMode"] = okTdMode(req.MarginMode)
 		if req.Side == exchange.SideTypeSell {
 			m["sz"] = fmt.Sprintf("%v", req.Size)
 		} else {
@@ -1071,13 +1744,28 @@ func (o *okx) toOrderParams(req *exchange.CreateOrderRequest) (okhttp.Params, er
 		m["clOrdId"] = req.ClientOrderID
 	}
 
-	if (req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined) && req.PositionSide != "" {
+	if req.MarketType.IsContract() && req.PositionSide != "" {
 		m["posSide"] = OkxPositionSide(req.PositionSide)
+	} else if req.ReduceOnly {
+		// 只减仓只用于杠杆和单向持仓合约
+		m["reduceOnly"] = true
+	}
+
+	if stpMode := OkxStpMode(req.SelfTradePreventionMode); stpMode != "" {
+		m["stpMode"] = stpMode
 	}
 
 	return m, nil
 }
 
+// okTdMode 下单的保证金模式，默认全仓
+func okTdMode(mode exchange.PosMode) string {
+	if mode == "" {
+		return OkxPosMode(exchange.PosModeCross)
+	}
+	return OkxPosMode(mode)
+}
+
 // size 精度处理
 func (h *okx) sizePrecision(size decimal.Decimal, symbol exchange.Symbol, opType string) decimal.Decimal {
 	orderQuantity := size
@@ -1134,3 +1822,14 @@ func (h *okx) posSideToPositionSide(instType string, posSide string, pos string,
 	}
 	return exchange.PositionSide(""), fmt.Errorf("invalid posSide: %v", posSide)
 }
+
+func toBatchOrderResult(clientOrderID, orderID, sCode, sMsg string) *exchange.BatchOrderResult {
+	res := &exchange.BatchOrderResult{
+		ClientOrderID: clientOrderID,
+		OrderID:       orderID,
+	}
+	if sCode != "0" {
+		res.Err = okError(sCode, sMsg)
+	}
+	return res
+}
@@ -0,0 +1,89 @@
+package okexc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCancelOrderParams(t *testing.T) {
+	m := toCancelOrderParams(&exchange.CancelOrderRequest{Symbol: "BTC-USDT", ClientOrderID: "a"})
+	assert.Equal(t, "a", m["clOrdId"])
+	assert.NotContains(t, m, "ordId")
+
+	// 没有客户端订单ID的订单（手动下单等）按订单ID撤单
+	m = toCancelOrderParams(&exchange.CancelOrderRequest{Symbol: "BTC-USDT", OrderID: "123"})
+	assert.Equal(t, "BTC-USDT", m["instId"])
+	assert.Equal(t, "123", m["ordId"])
+	assert.NotContains(t, m, "clOrdId")
+
+	m = toCancelOrderParams(&exchange.CancelOrderRequest{Symbol: "BTC-USDT", ClientOrderID: "a", OrderID: "123"})
+	assert.Equal(t, "123", m["ordId"])
+	assert.NotContains(t, m, "clOrdId")
+}
+
+func TestAlgoSearchOrderResponse(t *testing.T) {
+	// 未触发的计划委托作为挂单返回，订单号为 algoId，合约张数转换为币
+	order, err := toAlgoSearchOrderResponse(&AlgoOrderInfo{
+		InstID:      "BTC-USDT-SWAP",
+		OrdType:     "trigger",
+		State:       "live",
+		Side:        "sell",
+		Sz:          "2",
+		AlgoID:      "1",
+		AlgoClOrdID: "a",
+		OrdPx:       "-1",
+		CTime:       "1700000000000",
+		UTime:       "1700000000000",
+	}, decimal.RequireFromString("0.01"))
+	assert.NoError(t, err)
+	assert.Equal(t, "1", order.OrderID)
+	assert.Equal(t, "a", order.ClientOrderID)
+	assert.Equal(t, exchange.OrderStateNew, order.State)
+	assert.Equal(t, exchange.OrderTypeStopMarket, order.OrderType)
+	assert.True(t, order.Price.IsZero())
+	assert.Equal(t, "0.02", order.Volume.String())
+}
+
+func TestMatchMarketType(t *testing.T) {
+	assert.True(t, okMatchMarketType("BTC-USDT-SWAP", exchange.MarketTypePerpetualUSDMargined))
+	assert.False(t, okMatchMarketType("BTC-USD-SWAP", exchange.MarketTypePerpetualUSDMargined))
+	assert.True(t, okMatchMarketType("BTC-USD-250328", exchange.MarketTypeFuturesCoinMargined))
+	assert.False(t, okMatchMarketType("BTC-USDT-250328", exchange.MarketTypeFuturesCoinMargined))
+	assert.True(t, okMatchMarketType("BTC-USDT", exchange.MarketTypeSpot))
+}
+
+func TestPositionModeParams(t *testing.T) {
+	assert.Equal(t, "long_short_mode", toPositionModeParams(exchange.PositionModeHedge)["posMode"])
+	assert.Equal(t, "net_mode", toPositionModeParams(exchange.PositionModeOneWay)["posMode"])
+}
+
+func TestPositionMarginParams(t *testing.T) {
+	// 单向持仓 posSide 为 net，正数增加保证金
+	m := toPositionMarginParams(&exchange.AdjustPositionMarginRequest{
+		Symbol: exchange.Symbol{OriginalSymbol: "BTC-USDT-SWAP"},
+		Amount: decimal.NewFromInt(10),
+	})
+	assert.Equal(t, "BTC-USDT-SWAP", m["instId"])
+	assert.Equal(t, "net", m["posSide"])
+	assert.Equal(t, "add", m["type"])
+	assert.Equal(t, "10", m["amt"])
+
+	// 负数减少保证金，金额取绝对值
+	m = toPositionMarginParams(&exchange.AdjustPositionMarginRequest{
+		Symbol:       exchange.Symbol{OriginalSymbol: "BTC-USDT-SWAP"},
+		PositionSide: exchange.PositionSideShort,
+		Amount:       decimal.NewFromFloat(-2.5),
+	})
+	assert.Equal(t, "short", m["posSide"])
+	assert.Equal(t, "reduce", m["type"])
+	assert.Equal(t, "2.5", m["amt"])
+}
+
+func TestSetMarginMode(t *testing.T) {
+	err := (&okx{}).SetMarginMode(context.Background(), &exchange.SetMarginModeRequest{})
+	assert.ErrorIs(t, err, exchange.ErrMarginModePerOrder)
+}
@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// PriceLevel 订单簿档位，RawPrice、RawSize 为交易所推送的原始字符串，用于计算校验和
+type PriceLevel struct {
+	Price    decimal.Decimal
+	Size     decimal.Decimal
+	RawPrice string
+	RawSize  string
+}
+
+// OrderBook 本地订单簿，档位格式为 [价格, 数量, ...]，数量为零时删除该档；非并发安全
+type OrderBook struct {
+	asks map[string]PriceLevel
+	bids map[string]PriceLevel
+}
+
+func NewOrderBook() *OrderBook {
+	return &OrderBook{
+		asks: make(map[string]PriceLevel),
+		bids: make(map[string]PriceLevel),
+	}
+}
+
+// Reset 使用快照重建订单簿
+func (b *OrderBook) Reset(asks, bids [][]string) error {
+	b.asks = make(map[string]PriceLevel, len(asks))
+	b.bids = make(map[string]PriceLevel, len(bids))
+	return b.Update(asks, bids)
+}
+
+// Update 按档位更新订单簿
+func (b *OrderBook) Update(asks, bids [][]string) error {
+	if err := updateLevels(b.asks, asks); err != nil {
+		return err
+	}
+	return updateLevels(b.bids, bids)
+}
+
+// Levels 获取前 n 档，卖盘按价格升序，买盘按价格降序，n 小于等于零时返回全部档位
+func (b *OrderBook) Levels(n int) (asks, bids []PriceLevel) {
+	asks = sortLevels(b.asks, func(a, c decimal.Decimal) bool { return a.LessThan(c) }, n)
+	bids = sortLevels(b.bids, func(a, c decimal.Decimal) bool { return a.GreaterThan(c) }, n)
+	return asks, bids
+}
+
+// Depth 获取前 n 档的 [价格, 数量]，格式与 GetDepthResponse 相同
+func (b *OrderBook) Depth(n int) (asks, bids [][]decimal.Decimal) {
+	askLevels, bidLevels := b.Levels(n)
+	return ToDepthLevels(askLevels), ToDepthLevels(bidLevels)
+}
+
+// ToDepthLevels 档位转换为 [价格, 数量]
+func ToDepthLevels(levels []PriceLevel) [][]decimal.Decimal {
+	result := make([][]decimal.Decimal, 0, len(levels))
+	for _, v := range levels {
+		result = append(result, []decimal.Decimal{v.Price, v.Size})
+	}
+	return result
+}
+
+// ParseDepthLevels 解析推送中的档位，保留数量为零的档位
+func ParseDepthLevels(levels [][]string) ([][]decimal.Decimal, error) {
+	result := make([][]decimal.Decimal, 0, len(levels))
+	for _, v := range levels {
+		level, err := parsePriceLevel(v)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, []decimal.Decimal{level.Price, level.Size})
+	}
+	return result, nil
+}
+
+func updateLevels(book map[string]PriceLevel, levels [][]string) error {
+	for _, v := range levels {
+		level, err := parsePriceLevel(v)
+		if err != nil {
+			return err
+		}
+		// 同一价格的字符串可能带有不同的尾零，使用 decimal 的字符串作为键
+		key := level.Price.String()
+		if level.Size.IsZero() {
+			delete(book, key)
+			continue
+		}
+		book[key] = level
+	}
+	return nil
+}
+
+func parsePriceLevel(v []string) (PriceLevel, error) {
+	if len(v) < 2 {
+		return PriceLevel{}, errors.New("invalid depth level")
+	}
+	price, err := decimal.NewFromString(v[0])
+	if err != nil {
+		return PriceLevel{}, err
+	}
+	size, err := decimal.NewFromString(v[1])
+	if err != nil {
+		return PriceLevel{}, err
+	}
+	return PriceLevel{Price: price, Size: size, RawPrice: v[0], RawSize: v[1]}, nil
+}
+
+func sortLevels(book map[string]PriceLevel, less func(a, b decimal.Decimal) bool, n int) []PriceLevel {
+	levels := make([]PriceLevel, 0, len(book))
+	for _, v := range book {
+		levels = append(levels, v)
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return less(levels[i].Price, levels[j].Price)
+	})
+	if n > 0 && len(levels) > n {
+		levels = levels[:n]
+	}
+	return levels
+}
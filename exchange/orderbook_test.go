@@ -0,0 +1,33 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBook(t *testing.T) {
+	book := NewOrderBook()
+	err := book.Reset(
+		[][]string{{"101.0", "1"}, {"100.5", "2"}, {"102", "3"}},
+		[][]string{{"99", "1"}, {"100", "2"}},
+	)
+	assert.Nil(t, err)
+
+	asks, bids := book.Levels(2)
+	assert.Equal(t, []string{"100.5", "101.0"}, []string{asks[0].RawPrice, asks[1].RawPrice})
+	assert.Equal(t, []string{"100", "99"}, []string{bids[0].RawPrice, bids[1].RawPrice})
+
+	// 尾零不同的价格视为同一档，数量为零删除该档
+	err = book.Update([][]string{{"101", "5"}, {"100.50", "0"}}, [][]string{{"100.5", "1", "0", "2"}})
+	assert.Nil(t, err)
+	askDepth, bidDepth := book.Depth(0)
+	assert.Len(t, askDepth, 2)
+	assert.Equal(t, "101", askDepth[0][0].String())
+	assert.Equal(t, "5", askDepth[0][1].String())
+	assert.Len(t, bidDepth, 3)
+	assert.Equal(t, "100.5", bidDepth[0][0].String())
+
+	err = book.Update([][]string{{"abc", "1"}}, nil)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,218 @@
+package paexc
+
+import (
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	walletSpot    = "SPOT"
+	walletFutures = "FUTURES"
+)
+
+// Listener 订单及账户事件回调，MarketType 为空时接收全部账户的事件
+type Listener struct {
+	MarketType   exchange.MarketType
+	OrderEvent   func(evt *exchange.OrderResultEvent)
+	AccountEvent func(evt []*exchange.AccountUpdateEvent)
+}
+
+// Fill 成交记录
+type Fill struct {
+	TradeID       string
+	OrderID       string
+	ClientOrderID string
+	Symbol        string
+	MarketType    exchange.MarketType
+	Side          exchange.SideType
+	PositionSide  exchange.PositionSide
+	Price         decimal.Decimal
+	Size          decimal.Decimal
+	Fee           decimal.Decimal
+	FeeAsset      string
+	RealizedPnl   decimal.Decimal // 平仓或现货卖出的已实现盈亏，不含手续费
+	By            string
+	Time          int64
+}
+
+func (f *Fill) toSearchTradesResponse() *exchange.SearchTradesResponse {
+	return &exchange.SearchTradesResponse{
+		Symbol:        f.Symbol,
+		ID:            f.TradeID,
+		OrderID:       f.OrderID,
+		ClientOrderID: f.ClientOrderID,
+		Side:          f.Side,
+		PositionSide:  f.PositionSide,
+		Price:         f.Price,
+		Volume:        f.Size,
+		FeeCost:       f.Fee,
+		FeeAsset:      f.FeeAsset,
+		RealizedPnl:   f.RealizedPnl,
+		Time:          f.Time,
+		By:            f.By,
+	}
+}
+
+// Funding 资金费结算记录，Amount 为正表示支付，为负表示收取
+type Funding struct {
+	Symbol       string
+	PositionSide exchange.PositionSide
+	Rate         decimal.Decimal
+	MarkPrice    decimal.Decimal
+	Size         decimal.Decimal
+	Amount       decimal.Decimal
+	Asset        string
+	Time         int64
+}
+
+type balance struct {
+	free    decimal.Decimal
+	locked  decimal.Decimal
+	avgCost decimal.Decimal // 现货持仓成本价
+}
+
+type position struct {
+	symbol      string
+	marketType  exchange.MarketType
+	side        exchange.PositionSide
+	size        decimal.Decimal
+	pending     decimal.Decimal // 挂单中的平仓数量
+	avgPrice    decimal.Decimal
+	margin      decimal.Decimal
+	fee         decimal.Decimal
+	fundingFee  decimal.Decimal
+	realizedPnl decimal.Decimal
+	closedSize  decimal.Decimal // 累计平仓数量
+	closedQuote decimal.Decimal // 累计平仓金额
+	createTime  int64
+	updateTime  int64
+}
+
+type order struct {
+	id            string
+	clientOrderID string
+	symbol        string
+	baseAsset     string
+	quoteAsset    string
+	wallet        string
+	marketType    exchange.MarketType
+	side          exchange.SideType
+	positionSide  exchange.PositionSide
+	orderType     exchange.OrderType
+	timeInForce   exchange.TimeInForce
+	expireTime    int64 // GTD 订单过期时间
+	price         decimal.Decimal
+	stopPrice     decimal.Decimal // 触发价格，为零时使用 price
+	activation    decimal.Decimal // 跟踪止损激活价格
+	callbackRate  decimal.Decimal // 跟踪止损回调比例
+	extreme       decimal.Decimal // 跟踪止损激活后的最优价格
+	activated     bool            // 跟踪止损是否已激活
+	size          decimal.Decimal
+	leverage      decimal.Decimal
+	filled        decimal.Decimal
+	filledQuote   decimal.Decimal
+	fee           decimal.Decimal
+	state         exchange.OrderState
+	by            string
+	triggered     bool            // 条件单是否已触发
+	lockAsset     string          // 冻结资产
+	locked        decimal.Decimal // 剩余冻结数量
+	createTime    int64
+	updateTime    int64
+}
+
+func (o *order) remaining() decimal.Decimal {
+	return o.size.Sub(o.filled)
+}
+
+func (o *order) isOpen() bool {
+	return o.state == exchange.OrderStateNew || o.state == exchange.OrderStatePartiallyFilled
+}
+
+// isConditional 是否为条件单（止损/止盈/跟踪止损）
+func (o *order) isConditional() bool {
+	switch o.orderType {
+	case exchange.OrderTypeStop, exchange.OrderTypeStopMarket,
+		exchange.OrderTypeTakeProfit, exchange.OrderTypeTakeProfitMarket,
+		exchange.OrderTypeTrailingStopMarket:
+		return true
+	}
+	return false
+}
+
+// isMarketTrigger 触发后是否按市价成交
+func (o *order) isMarketTrigger() bool {
+	switch o.orderType {
+	case exchange.OrderTypeStopMarket, exchange.OrderTypeTakeProfitMarket, exchange.OrderTypeTrailingStopMarket:
+		return true
+	}
+	return false
+}
+
+// triggerPrice 条件单触发价格，未设置时使用委托价格
+func (o *order) triggerPrice() decimal.Decimal {
+	if o.stopPrice.IsPositive() {
+		return o.stopPrice
+	}
+	return o.price
+}
+
+// isClose 合约订单是否为平仓单
+func (o *order) isClose() bool {
+	if o.wallet != walletFutures {
+		return false
+	}
+	return (o.positionSide == exchange.PositionSideLong && o.side == exchange.SideTypeSell) ||
+		(o.positionSide == exchange.PositionSideShort && o.side == exchange.SideTypeBuy)
+}
+
+func (o *order) avgPrice() decimal.Decimal {
+	if o.filled.IsZero() {
+		return decimal.Zero
+	}
+	return o.filledQuote.Div(o.filled)
+}
+
+func (o *order) toCreateOrderResponse(fills []*Fill) *exchange.CreateOrderResponse {
+	trades := make([]*exchange.SearchTradesResponse, 0, len(fills))
+	for _, f := range fills {
+		trades = append(trades, f.toSearchTradesResponse())
+	}
+	return &exchange.CreateOrderResponse{
+		TransactTime:     o.updateTime,
+		Symbol:           o.symbol,
+		ClientOrderID:    o.clientOrderID,
+		OrderID:          o.id,
+		Side:             o.side,
+		State:            o.state,
+		PositionSide:     o.positionSide,
+		Price:            o.price,
+		AvgPrice:         o.avgPrice(),
+		OriginalQuantity: o.size,
+		ExecutedQuantity: o.filled,
+		Fills:            trades,
+	}
+}
+
+func (o *order) toSearchOrderResponse() *exchange.SearchOrderResponse {
+	return &exchange.SearchOrderResponse{
+		ClientOrderID:     o.clientOrderID,
+		OrderID:           o.id,
+		State:             o.state,
+		Symbol:            o.symbol,
+		AvgPrice:          o.avgPrice(),
+		Volume:            o.size,
+		Price:             o.price,
+		FilledQuoteVolume: o.filledQuote,
+		FilledVolume:      o.filled,
+		FeeCost:           o.fee,
+		FeeAsset:          o.quoteAsset,
+		Side:              o.side,
+		PositionSide:      o.positionSide,
+		TimeInForce:       o.timeInForce,
+		OrderType:         o.orderType,
+		By:                o.by,
+		CreatedTime:       o.createTime,
+		UpdateTime:        o.updateTime,
+	}
+}
@@ -0,0 +1,45 @@
+package paexc
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+type Option func(*options)
+
+type options struct {
+	makerFee        decimal.Decimal // 挂单手续费率
+	takerFee        decimal.Decimal // 吃单手续费率
+	defaultLeverage int64           // 合约默认杠杆倍数
+	quoteAssets     []string        // 计价资产，用于从交易对名称中拆分出基础资产和计价资产
+	fillByTradeSize bool            // 限价单成交数量是否受逐笔成交数量限制
+}
+
+func WithMakerFee(fee decimal.Decimal) Option {
+	return func(o *options) {
+		o.makerFee = fee
+	}
+}
+
+func WithTakerFee(fee decimal.Decimal) Option {
+	return func(o *options) {
+		o.takerFee = fee
+	}
+}
+
+func WithDefaultLeverage(leverage int64) Option {
+	return func(o *options) {
+		o.defaultLeverage = leverage
+	}
+}
+
+func WithQuoteAssets(assets ...string) Option {
+	return func(o *options) {
+		o.quoteAssets = assets
+	}
+}
+
+func WithFillByTradeSize(fillByTradeSize bool) Option {
+	return func(o *options) {
+		o.fillByTradeSize = fillByTradeSize
+	}
+}
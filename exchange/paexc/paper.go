@@ -0,0 +1,1526 @@
+package paexc
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	// ErrOrderTypeNotSupported 不支持的订单类型
+	ErrOrderTypeNotSupported = errors.New("order type not supported")
+	// ErrPositionNotEnough 可平仓位不足
+	ErrPositionNotEnough = errors.New("position not enough")
+	// ErrNoMarketPrice 尚无最新成交价格
+	ErrNoMarketPrice = errors.New("no market price")
+	// ErrInvalidOrder 订单参数错误
+	ErrInvalidOrder = errors.New("invalid order")
+)
+
+var _ exchange.Exchange = (*PaperExchange)(nil)
+
+// NewPaperExchange 本地撮合的模拟交易所，成交由 OnTrade 推送的逐笔成交驱动
+func NewPaperExchange(opts ...Option) *PaperExchange {
+	o := &options{
+		makerFee:        decimal.NewFromFloat(0.0002),
+		takerFee:        decimal.NewFromFloat(0.0005),
+		defaultLeverage: 1,
+		quoteAssets:     []string{"USDT", "USDC", "FDUSD", "BUSD", "BTC", "ETH", "BNB"},
+		fillByTradeSize: true,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return &PaperExchange{
+		opts: o,
+		balances: map[string]map[string]*balance{
+			walletSpot:    {},
+			walletFutures: {},
+		},
+		positions:  make(map[string]*position),
+		leverages:  make(map[string]int64),
+		lastPrices: make(map[string]decimal.Decimal),
+		orders:     make(map[string]*order),
+		clients:    make(map[string]*order),
+		books:      make(map[string][]*order),
+		listeners:  make(map[string]*Listener),
+		changed:    make(map[string]map[string]struct{}),
+	}
+}
+
+type PaperExchange struct {
+	opts *options
+	mu   sync.Mutex
+
+	orderSeq int64
+	tradeSeq int64
+	now      int64 // 最新成交时间，作为模拟时钟
+
+	balances   map[string]map[string]*balance // wallet -> asset -> balance
+	positions  map[string]*position           // symbol:positionSide -> position
+	leverages  map[string]int64               // symbol -> leverage
+	lastPrices map[string]decimal.Decimal     // wallet:symbol -> price
+	orders     map[string]*order              // orderID -> order，包含已完成的订单
+	clients    map[string]*order              // clientOrderID -> 最近使用该 ID 的订单
+	books      map[string][]*order            // wallet:symbol -> 未完成订单，按下单顺序
+	expiring   []*order                       // 未过期的 GTD 订单
+	fills      []*Fill
+	fundings   []*Funding
+	closed     []*exchange.ClosedPosition
+	listeners  map[string]*Listener
+
+	// 待推送事件，在释放锁后统一推送，避免回调中再次下单造成死锁
+	orderEvents []*exchange.OrderResultEvent
+	changed     map[string]map[string]struct{} // wallet -> asset
+}
+
+func (p *PaperExchange) Name() string {
+	return exchange.PaperExchange
+}
+
+// Subscribe 订阅订单及账户事件
+func (p *PaperExchange) Subscribe(id string, l *Listener) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners[id] = l
+}
+
+// Unsubscribe 取消订阅
+func (p *PaperExchange) Unsubscribe(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.listeners, id)
+}
+
+// SetBalance 设置账户可用余额
+func (p *PaperExchange) SetBalance(marketType exchange.MarketType, asset string, amount decimal.Decimal) error {
+	return p.exec(func() error {
+		wallet := walletOf(marketType)
+		if wallet == "" {
+			return exchange.ErrInstrumentTypeNotSupported
+		}
+		p.balance(wallet, asset).free = amount
+		p.touch(wallet, asset)
+		return nil
+	})
+}
+
+// OnTrade 推送逐笔成交，更新最新价格并撮合挂单
+func (p *PaperExchange) OnTrade(te *exchange.TradeEvent) {
+	_ = p.exec(func() error {
+		p.onTrade(te)
+		return nil
+	})
+}
+
+// OnMarkPrice 推送标记价格，IsSettlement 为 true 时按 LastFundingRate 结算资金费
+func (p *PaperExchange) OnMarkPrice(evt *exchange.MarkPriceEvent) {
+	if !evt.IsSettlement {
+		return
+	}
+	_ = p.exec(func() error {
+		p.settleFunding(evt)
+		return nil
+	})
+}
+
+// Symbols 返回已收到行情的交易对
+func (p *PaperExchange) Symbols(ctx context.Context, marketType exchange.MarketType) ([]exchange.Symbol, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wallet := walletOf(marketType)
+	if wallet == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	result := make([]exchange.Symbol, 0)
+	for key := range p.lastPrices {
+		name, ok := strings.CutPrefix(key, wallet+":")
+		if !ok {
+			continue
+		}
+		base, quote, err := p.splitSymbol(exchange.Symbol{OriginalSymbol: name})
+		if err != nil {
+			continue
+		}
+		result = append(result, exchange.Symbol{
+			OriginalSymbol: name,
+			UnifiedSymbol:  exchange.UnifiedSymbolName(base, quote, marketType, 0),
+			OriginalAsset:  base,
+			UnifiedAsset:   base,
+			QuoteAsset:     quote,
+			Exchange:       exchange.PaperExchange,
+			MarketType:     marketType,
+			Status:         exchange.SymbolStatusEnabled,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OriginalSymbol < result[j].OriginalSymbol
+	})
+	return result, nil
+}
+
+func (p *PaperExchange) Assets(ctx context.Context, req *exchange.GetAssetsRequest) ([]exchange.Asset, error) {
+	wallet := walletOf(req.MarketType)
+	if wallet == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	assets := make([]exchange.Asset, 0, len(p.balances[wallet]))
+	for name, b := range p.balances[wallet] {
+		assets = append(assets, exchange.Asset{
+			AssetName:  name,
+			Exchange:   exchange.PaperExchange,
+			MarketType: req.MarketType,
+			Free:       b.free,
+			Locked:     b.locked,
+		})
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].AssetName < assets[j].AssetName
+	})
+	return assets, nil
+}
+
+func (p *PaperExchange) CreateOrder(ctx context.Context, o *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	var res *exchange.CreateOrderResponse
+	err := p.exec(func() error {
+		var err error
+		res, err = p.createOrder(o)
+		return err
+	})
+	return res, err
+}
+
+func (p *PaperExchange) CancelOrder(ctx context.Context, o *exchange.CancelOrderRequest) error {
+	return p.exec(func() error {
+		ord := p.cancelTarget(o)
+		if ord == nil || !ord.isOpen() {
+			return exchange.ErrOrderNotFound
+		}
+		p.finish(ord, exchange.OrderStateCanceled, exchange.ExecutionStateCanceled)
+		return nil
+	})
+}
+
+// BatchCreateOrders 在同一次撮合锁内按顺序逐笔下单
+func (p *PaperExchange) BatchCreateOrders(ctx context.Context, o []*exchange.CreateOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	result := make([]*exchange.BatchOrderResult, 0, len(o))
+	err := p.exec(func() error {
+		for _, v := range o {
+			res := &exchange.BatchOrderResult{ClientOrderID: v.ClientOrderID}
+			ack, err := p.createOrder(v)
+			if err != nil {
+				res.Err = err
+			} else {
+				res.OrderID = ack.OrderID
+			}
+			result = append(result, res)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// BatchCancelOrders 在同一次撮合锁内按顺序逐笔撤单
+func (p *PaperExchange) BatchCancelOrders(ctx context.Context, o []*exchange.CancelOrderRequest) ([]*exchange.BatchOrderResult, error) {
+	result := make([]*exchange.BatchOrderResult, 0, len(o))
+	err := p.exec(func() error {
+		for _, v := range o {
+			res := &exchange.BatchOrderResult{ClientOrderID: v.ClientOrderID}
+			ord := p.cancelTarget(v)
+			if ord == nil || !ord.isOpen() {
+				res.Err = exchange.ErrOrderNotFound
+			} else {
+				res.OrderID = ord.id
+				p.finish(ord, exchange.OrderStateCanceled, exchange.ExecutionStateCanceled)
+			}
+			result = append(result, res)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (p *PaperExchange) GetOpenOrders(ctx context.Context, req *exchange.GetOpenOrdersRequest) ([]*exchange.SearchOrderResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	orders, err := p.openOrders(req.MarketType, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*exchange.SearchOrderResponse, 0, len(orders))
+	for _, o := range orders {
+		result = append(result, o.toSearchOrderResponse())
+	}
+	return result, nil
+}
+
+func (p *PaperExchange) CancelAllOrders(ctx context.Context, req *exchange.CancelAllOrdersRequest) error {
+	return p.exec(func() error {
+		orders, err := p.openOrders(req.MarketType, req.Symbol)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			p.finish(o, exchange.OrderStateCanceled, exchange.ExecutionStateCanceled)
+		}
+		return nil
+	})
+}
+
+// AmendOrder 修改挂单价格或数量，价格变化或数量增加时重新排队
+func (p *PaperExchange) AmendOrder(ctx context.Context, o *exchange.AmendOrderRequest) (*exchange.AmendOrderResponse, error) {
+	var res *exchange.AmendOrderResponse
+	err := p.exec(func() error {
+		ord := p.findOrder(o.ClientOrderID, o.OrderID)
+		if ord == nil || !ord.isOpen() {
+			return exchange.ErrOrderNotFound
+		}
+		if o.NewClientOrderID != "" && o.NewClientOrderID != ord.clientOrderID {
+			if exist, ok := p.clients[o.NewClientOrderID]; ok && exist.isOpen() {
+				return exchange.ErrOrderAlreadyExists
+			}
+		}
+		newSize, newPrice := ord.size, ord.price
+		if o.NewSize.IsPositive() {
+			newSize = o.NewSize
+		}
+		if o.NewPrice.IsPositive() {
+			newPrice = o.NewPrice
+		}
+		if !newSize.GreaterThan(ord.filled) {
+			return ErrInvalidOrder
+		}
+		if ord.orderType == exchange.OrderTypeTrailingStopMarket {
+			return ErrOrderTypeNotSupported
+		}
+
+		key := bookKey(ord.wallet, ord.symbol)
+		last := p.lastPrices[key]
+		oldSize, oldPrice := ord.size, ord.price
+		ord.size, ord.price = newSize, newPrice
+		cross := !ord.isConditional() && last.IsPositive() && crosses(ord, last)
+		ord.size, ord.price = oldSize, oldPrice
+		if cross && (ord.orderType == exchange.OrderTypeLimitMaker || ord.timeInForce == exchange.TimeInForceGTX) {
+			return exchange.ErrPostOnlyRejected
+		}
+
+		// 按新参数重新冻结
+		p.release(ord)
+		ord.size, ord.price = newSize, newPrice
+		if err := p.freeze(ord, freezePrice(ord, newPrice)); err != nil {
+			ord.size, ord.price = oldSize, oldPrice
+			if rerr := p.freeze(ord, freezePrice(ord, oldPrice)); rerr != nil {
+				return rerr
+			}
+			return err
+		}
+
+		if o.NewClientOrderID != "" && o.NewClientOrderID != ord.clientOrderID {
+			if p.clients[ord.clientOrderID] == ord {
+				delete(p.clients, ord.clientOrderID)
+			}
+			ord.clientOrderID = o.NewClientOrderID
+			p.clients[ord.clientOrderID] = ord
+		}
+		if !newPrice.Equal(oldPrice) || newSize.GreaterThan(oldSize) {
+			book := p.books[key]
+			for i, v := range book {
+				if v == ord {
+					p.books[key] = append(append(book[:i:i], book[i+1:]...), ord)
+					break
+				}
+			}
+		}
+		ord.updateTime = p.timestamp()
+		p.pushOrderEvent(ord, exchange.ExecutionStateAmendment, decimal.Zero, decimal.Zero, decimal.Zero)
+		if cross {
+			p.fill(ord, last, ord.remaining(), exchange.ByTaker)
+			p.compact(key)
+		}
+
+		res = &exchange.AmendOrderResponse{
+			Symbol:        ord.symbol,
+			ClientOrderID: ord.clientOrderID,
+			OrderID:       ord.id,
+			State:         ord.state,
+			Price:         ord.price,
+			Size:          ord.size,
+			UpdateTime:    ord.updateTime,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// CreateOcoOrder 模拟盘不支持 OCO 订单
+func (p *PaperExchange) CreateOcoOrder(ctx context.Context, req *exchange.CreateOcoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	return nil, ErrOrderTypeNotSupported
+}
+
+// CancelAlgoOrder 撤销条件单
+func (p *PaperExchange) CancelAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) error {
+	return p.exec(func() error {
+		ord := p.findOrder(req.ClientOrderID, req.OrderID)
+		if ord == nil || !ord.isOpen() || !ord.isConditional() {
+			return exchange.ErrOrderNotFound
+		}
+		p.finish(ord, exchange.OrderStateCanceled, exchange.ExecutionStateCanceled)
+		return nil
+	})
+}
+
+// SearchAlgoOrder 查询条件单
+func (p *PaperExchange) SearchAlgoOrder(ctx context.Context, req *exchange.AlgoOrderRequest) (*exchange.AlgoOrderResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ord := p.findOrder(req.ClientOrderID, req.OrderID)
+	if ord == nil || !ord.isConditional() {
+		return nil, exchange.ErrOrderNotFound
+	}
+	return &exchange.AlgoOrderResponse{
+		OrderID:       ord.id,
+		ClientOrderID: ord.clientOrderID,
+		Symbol:        ord.symbol,
+		OrderType:     ord.orderType,
+		State:         ord.state,
+		Side:          ord.side,
+		PositionSide:  ord.positionSide,
+		Size:          ord.size,
+		Orders:        []*exchange.SearchOrderResponse{ord.toSearchOrderResponse()},
+		CreatedTime:   ord.createTime,
+		UpdateTime:    ord.updateTime,
+	}, nil
+}
+
+func (p *PaperExchange) SearchOrder(ctx context.Context, o *exchange.SearchOrderRequest) (*exchange.SearchOrderResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ord, ok := p.clients[o.ClientOrderID]
+	if !ok {
+		return nil, exchange.ErrOrderNotFound
+	}
+	return ord.toSearchOrderResponse(), nil
+}
+
+func (p *PaperExchange) SearchTrades(ctx context.Context, o *exchange.SearchTradesRequest) ([]*exchange.SearchTradesResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]*exchange.SearchTradesResponse, 0)
+	for _, f := range p.fills {
+		if o.OrderID != "" && f.OrderID != o.OrderID {
+			continue
+		}
+		if o.Symbol != "" && f.Symbol != o.Symbol {
+			continue
+		}
+		result = append(result, f.toSearchTradesResponse())
+	}
+	return result, nil
+}
+
+// OrderHistory 模拟交易所数据都在内存中，一次返回全部结果
+func (p *PaperExchange) OrderHistory(req *exchange.GetOrderHistoryRequest) exchange.Iterator[*exchange.SearchOrderResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.SearchOrderResponse, bool, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		wallet := walletOf(req.MarketType)
+		if wallet == "" {
+			return nil, true, exchange.ErrInstrumentTypeNotSupported
+		}
+		orders := make([]*order, 0)
+		for _, o := range p.orders {
+			if o.wallet != wallet || (req.Symbol != "" && o.symbol != req.Symbol) || !inRange(o.createTime, req.StartTime, req.EndTime) {
+				continue
+			}
+			orders = append(orders, o)
+		}
+		sortOrders(orders)
+		result := make([]*exchange.SearchOrderResponse, 0, len(orders))
+		for _, o := range orders {
+			result = append(result, o.toSearchOrderResponse())
+		}
+		return result, true, nil
+	})
+}
+
+func (p *PaperExchange) TradeHistory(req *exchange.GetTradeHistoryRequest) exchange.Iterator[*exchange.SearchTradesResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.SearchTradesResponse, bool, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		wallet := walletOf(req.MarketType)
+		if wallet == "" {
+			return nil, true, exchange.ErrInstrumentTypeNotSupported
+		}
+		result := make([]*exchange.SearchTradesResponse, 0)
+		for _, f := range p.fills {
+			if walletOf(f.MarketType) != wallet || (req.Symbol != "" && f.Symbol != req.Symbol) || !inRange(f.Time, req.StartTime, req.EndTime) {
+				continue
+			}
+			result = append(result, f.toSearchTradesResponse())
+		}
+		return result, true, nil
+	})
+}
+
+func (p *PaperExchange) ClosedPositions(req *exchange.GetClosedPositionsRequest) exchange.Iterator[*exchange.ClosedPosition] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.ClosedPosition, bool, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		result := make([]*exchange.ClosedPosition, 0)
+		for _, c := range p.closed {
+			if (req.Symbol != "" && c.Symbol != req.Symbol) || !inRange(c.CloseTime, req.StartTime, req.EndTime) {
+				continue
+			}
+			v := *c
+			result = append(result, &v)
+		}
+		return result, true, nil
+	})
+}
+
+// Ledger 由成交和资金费记录生成账单，只包含手续费、合约平仓盈亏和资金费
+func (p *PaperExchange) Ledger(req *exchange.GetLedgerRequest) exchange.Iterator[*exchange.LedgerEntry] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.LedgerEntry, bool, error) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		result := make([]*exchange.LedgerEntry, 0)
+		add := func(e *exchange.LedgerEntry) {
+			if (req.Symbol != "" && e.Symbol != req.Symbol) || (req.MarketType != "" && e.MarketType != req.MarketType) ||
+				(req.Type != "" && e.Type != req.Type) || !inRange(e.Time, req.StartTime, req.EndTime) {
+				return
+			}
+			result = append(result, e)
+		}
+		for _, f := range p.fills {
+			add(&exchange.LedgerEntry{
+				ID:         f.TradeID,
+				Symbol:     f.Symbol,
+				MarketType: f.MarketType,
+				Type:       exchange.LedgerTypeFee,
+				Asset:      f.FeeAsset,
+				Amount:     f.Fee.Neg(),
+				OrderID:    f.OrderID,
+				TradeID:    f.TradeID,
+				Time:       f.Time,
+			})
+			if walletOf(f.MarketType) == walletFutures && !f.RealizedPnl.IsZero() {
+				add(&exchange.LedgerEntry{
+					ID:         f.TradeID,
+					Symbol:     f.Symbol,
+					MarketType: f.MarketType,
+					Type:       exchange.LedgerTypeRealizedPnl,
+					Asset:      f.FeeAsset,
+					Amount:     f.RealizedPnl,
+					OrderID:    f.OrderID,
+					TradeID:    f.TradeID,
+					Time:       f.Time,
+				})
+			}
+		}
+		for i, f := range p.fundings {
+			add(&exchange.LedgerEntry{
+				ID:         "funding-" + strconv.Itoa(i+1),
+				Symbol:     f.Symbol,
+				MarketType: exchange.MarketTypePerpetualUSDMargined,
+				Type:       exchange.LedgerTypeFundingFee,
+				Asset:      f.Asset,
+				Amount:     f.Amount.Neg(),
+				Time:       f.Time,
+			})
+		}
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Time < result[j].Time
+		})
+		return result, true, nil
+	})
+}
+
+// Fills 全部成交记录
+func (p *PaperExchange) Fills() []Fill {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]Fill, 0, len(p.fills))
+	for _, f := range p.fills {
+		result = append(result, *f)
+	}
+	return result
+}
+
+// Fundings 全部资金费结算记录
+func (p *PaperExchange) Fundings() []Funding {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]Funding, 0, len(p.fundings))
+	for _, f := range p.fundings {
+		result = append(result, *f)
+	}
+	return result
+}
+
+// Equity 以计价资产估算账户权益，现货资产按最新价折算，合约计入未实现盈亏
+func (p *PaperExchange) Equity(quote string) decimal.Decimal {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	equity := decimal.Zero
+	for asset, b := range p.balances[walletSpot] {
+		total := b.free.Add(b.locked)
+		if asset == quote {
+			equity = equity.Add(total)
+			continue
+		}
+		for _, symbol := range []string{asset + quote, asset + "-" + quote} {
+			if price, ok := p.lastPrices[bookKey(walletSpot, symbol)]; ok {
+				equity = equity.Add(total.Mul(price))
+				break
+			}
+		}
+	}
+	if b, ok := p.balances[walletFutures][quote]; ok {
+		equity = equity.Add(b.free).Add(b.locked)
+	}
+	for _, pos := range p.positions {
+		equity = equity.Add(p.unrealizedPnl(pos))
+	}
+	return equity
+}
+
+func (p *PaperExchange) GetFundingRate(ctx context.Context, req *exchange.GetFundingRate) ([]*exchange.GetFundingRateResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) KlineHistory(req *exchange.GetKlineHistoryRequest) exchange.Iterator[exchange.GetKlineResponse] {
+	return exchange.NewIterator(func(ctx context.Context) ([]exchange.GetKlineResponse, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (p *PaperExchange) FundingRateHistory(req *exchange.GetFundingRateHistoryRequest) exchange.Iterator[*exchange.FundingRate] {
+	return exchange.NewIterator(func(ctx context.Context) ([]*exchange.FundingRate, bool, error) {
+		return nil, false, errors.New("not implemented")
+	})
+}
+
+func (p *PaperExchange) GetMarginInterestRate(ctx context.Context, req *exchange.GetMarginInterestRateRequest) ([]*exchange.GetMarginInterestRateResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) MarginBorrowOrRepay(ctx context.Context, req *exchange.MarginBorrowOrRepayRequest) error {
+	return errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetMarginInventory(ctx context.Context, req *exchange.MarginInventoryRequest) (*exchange.MarginInventory, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) ConvertContractCoin(typ string, symbol exchange.Symbol, sz string, opTyp string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetPosition(ctx context.Context, req *exchange.GetPositionRequest) ([]*exchange.GetPositionResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]*exchange.GetPositionResponse, 0)
+	for _, pos := range p.positions {
+		if req.Symbol != "" && pos.symbol != req.Symbol {
+			continue
+		}
+		upl := p.unrealizedPnl(pos)
+		result = append(result, &exchange.GetPositionResponse{
+			Symbol:       pos.symbol,
+			MarketType:   pos.marketType,
+			AvgPrice:     pos.avgPrice,
+			Fee:          pos.fee,
+			FundingFee:   pos.fundingFee,
+			PositionSide: pos.side,
+			Size:         pos.size,
+			Upl:          upl,
+			RealizedPnl:  pos.realizedPnl,
+			Lever:        strconv.FormatInt(p.leverage(pos.symbol), 10),
+			Margin:       pos.margin,
+			CreateTime:   pos.createTime,
+			UpdateTime:   pos.updateTime,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Symbol == result[j].Symbol {
+			return result[i].PositionSide < result[j].PositionSide
+		}
+		return result[i].Symbol < result[j].Symbol
+	})
+	return result, nil
+}
+
+func (p *PaperExchange) GetHistoryPosition(ctx context.Context, req *exchange.GetPositionHistoryRequest) error {
+	return errors.New("not implemented")
+}
+
+func (p *PaperExchange) SetLeverage(ctx context.Context, req *exchange.SetLeverageRequest) error {
+	lever, err := strconv.ParseInt(req.Lever, 10, 64)
+	if err != nil || lever <= 0 {
+		return errors.New("invalid leverage")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.leverages[req.Symbol] = lever
+	return nil
+}
+
+func (p *PaperExchange) GetLeverage(ctx context.Context, req *exchange.GetLeverageRequest) (exchange.GetLeverageResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return exchange.GetLeverageResponse{
+		Symbol:     req.Symbol,
+		Leverage:   strconv.FormatInt(p.leverage(req.Symbol), 10),
+		MarginType: string(exchange.PosModeCross),
+	}, nil
+}
+
+// SetPositionMode 模拟盘只支持双向持仓
+func (p *PaperExchange) SetPositionMode(ctx context.Context, req *exchange.SetPositionModeRequest) error {
+	if req.Mode != exchange.PositionModeHedge {
+		return errors.New("paper exchange only supports hedge position mode")
+	}
+	return nil
+}
+
+// SetMarginMode 模拟盘只支持全仓
+func (p *PaperExchange) SetMarginMode(ctx context.Context, req *exchange.SetMarginModeRequest) error {
+	if req.Mode != exchange.PosModeCross {
+		return errors.New("paper exchange only supports cross margin mode")
+	}
+	return nil
+}
+
+func (p *PaperExchange) AdjustPositionMargin(ctx context.Context, req *exchange.AdjustPositionMarginRequest) error {
+	return errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetAccountConfig(ctx context.Context, req *exchange.GetAccountConfigRequest) (exchange.GetAccountConfigResponse, error) {
+	return exchange.GetAccountConfigResponse{}, errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetMaxSize(ctx context.Context, req *exchange.GetMaxSizeRequest) ([]exchange.GetMaxSizeResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetMarkPriceKline(ctx context.Context, req *exchange.GetMarkPriceKlineRequest) ([]exchange.GetMarkPriceKlineResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetKline(ctx context.Context, req *exchange.GetKlineRequest) ([]exchange.GetKlineResponse, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetDepth(ctx context.Context, req *exchange.GetDepthRequest) (exchange.GetDepthResponse, error) {
+	return exchange.GetDepthResponse{}, errors.New("not implemented")
+}
+
+func (p *PaperExchange) GetTickerPrice(ctx context.Context, symbol string, marketType exchange.MarketType) (decimal.Decimal, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	price, ok := p.lastPrices[bookKey(walletOf(marketType), symbol)]
+	if !ok {
+		return decimal.Zero, ErrNoMarketPrice
+	}
+	return price, nil
+}
+
+// TransferAsset 现货与合约账户之间划转，支持 MAIN_UMFUTURE, UMFUTURE_MAIN
+func (p *PaperExchange) TransferAsset(ctx context.Context, req *exchange.TransferAssetRequest) error {
+	var from, to string
+	switch req.Type {
+	case "MAIN_UMFUTURE":
+		from, to = walletSpot, walletFutures
+	case "UMFUTURE_MAIN":
+		from, to = walletFutures, walletSpot
+	default:
+		return errors.New("unsupported transfer type")
+	}
+	if !req.Amount.IsPositive() {
+		return errors.New("invalid transfer amount")
+	}
+
+	return p.exec(func() error {
+		src := p.balance(from, req.Asset)
+		if src.free.LessThan(req.Amount) {
+			return exchange.ErrOrderNotEnoughBalance
+		}
+		src.free = src.free.Sub(req.Amount)
+		dst := p.balance(to, req.Asset)
+		dst.free = dst.free.Add(req.Amount)
+		p.touch(from, req.Asset)
+		p.touch(to, req.Asset)
+		return nil
+	})
+}
+
+// exec 加锁执行，释放锁后推送期间产生的事件
+func (p *PaperExchange) exec(fn func() error) error {
+	p.mu.Lock()
+	err := fn()
+	orderEvents := p.orderEvents
+	p.orderEvents = nil
+	accountEvents := p.accountEvents()
+	listeners := make([]*Listener, 0, len(p.listeners))
+	for _, l := range p.listeners {
+		listeners = append(listeners, l)
+	}
+	p.mu.Unlock()
+
+	for _, l := range listeners {
+		wallet := walletOf(l.MarketType)
+		if l.OrderEvent != nil {
+			for _, evt := range orderEvents {
+				if wallet != "" && walletOf(evt.MarketType) != wallet {
+					continue
+				}
+				e := *evt
+				l.OrderEvent(&e)
+			}
+		}
+		if l.AccountEvent != nil {
+			for w, evts := range accountEvents {
+				if wallet != "" && w != wallet {
+					continue
+				}
+				l.AccountEvent(evts)
+			}
+		}
+	}
+	return err
+}
+
+// settleFunding 结算资金费，多头在费率为正时支付，空头收取
+func (p *PaperExchange) settleFunding(evt *exchange.MarkPriceEvent) {
+	if evt.Time > p.now {
+		p.now = evt.Time
+		p.expireOrders()
+	}
+	ts := p.timestamp()
+	for _, pos := range p.positions {
+		if pos.symbol != evt.Symbol {
+			continue
+		}
+		price := evt.MarkPrice
+		if !price.IsPositive() {
+			price = p.lastPrices[bookKey(walletFutures, pos.symbol)]
+		}
+		amount := pos.size.Mul(price).Mul(evt.LastFundingRate)
+		if pos.side == exchange.PositionSideShort {
+			amount = amount.Neg()
+		}
+		quote := p.quoteOf(pos.symbol)
+		b := p.balance(walletFutures, quote)
+		b.free = b.free.Sub(amount)
+		pos.fundingFee = pos.fundingFee.Add(amount)
+		pos.updateTime = ts
+		p.touch(walletFutures, quote)
+		p.fundings = append(p.fundings, &Funding{
+			Symbol:       pos.symbol,
+			PositionSide: pos.side,
+			Rate:         evt.LastFundingRate,
+			MarkPrice:    price,
+			Size:         pos.size,
+			Amount:       amount,
+			Asset:        quote,
+			Time:         ts,
+		})
+	}
+}
+
+func (p *PaperExchange) unrealizedPnl(pos *position) decimal.Decimal {
+	last := p.lastPrices[bookKey(walletFutures, pos.symbol)]
+	if !last.IsPositive() {
+		return decimal.Zero
+	}
+	upl := last.Sub(pos.avgPrice).Mul(pos.size)
+	if pos.side == exchange.PositionSideShort {
+		upl = upl.Neg()
+	}
+	return upl
+}
+
+func (p *PaperExchange) quoteOf(symbol string) string {
+	_, quote, err := p.splitSymbol(exchange.Symbol{OriginalSymbol: symbol})
+	if err != nil {
+		return ""
+	}
+	return quote
+}
+
+// createOrder 下单并立即撮合，返回下单后的订单状态和立即成交明细
+func (p *PaperExchange) createOrder(req *exchange.CreateOrderRequest) (*exchange.CreateOrderResponse, error) {
+	if !req.Size.IsPositive() {
+		return nil, ErrInvalidOrder
+	}
+	// 客户端订单号只要求在未完成订单中唯一，为空时由交易所生成
+	if ord, ok := p.clients[req.ClientOrderID]; ok && req.ClientOrderID != "" && ord.isOpen() {
+		return nil, exchange.ErrOrderAlreadyExists
+	}
+	wallet := walletOf(req.MarketType)
+	if wallet == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	if req.OrderType == exchange.OrderTypeTrailingStopMarket && !req.CallbackRate.IsPositive() {
+		return nil, ErrInvalidOrder
+	}
+	// 模拟盘只支持双向持仓，平仓单本身只减仓；不支持平仓条件单和盘口价格下单
+	if req.ClosePosition || req.PriceMatch != "" {
+		return nil, exchange.ErrOrderOptionNotSupported
+	}
+	if wallet == walletFutures && req.PositionSide != exchange.PositionSideLong && req.PositionSide != exchange.PositionSideShort {
+		return nil, errors.New("position side is required")
+	}
+	base, quote, err := p.splitSymbol(req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	symbol := req.Symbol.OriginalSymbol
+	key := bookKey(wallet, symbol)
+	last := p.lastPrices[key]
+	ts := p.timestamp()
+	if req.OrderTime > 0 {
+		ts = req.OrderTime
+	}
+	tif := req.TimeInForce
+	if tif == "" && req.OrderType != exchange.OrderTypeMarket {
+		tif = exchange.TimeInForceGTC
+	}
+	if tif == exchange.TimeInForceGTD && req.ExpireTime <= ts {
+		return nil, ErrInvalidOrder
+	}
+
+	o := &order{
+		clientOrderID: req.ClientOrderID,
+		symbol:        symbol,
+		baseAsset:     base,
+		quoteAsset:    quote,
+		wallet:        wallet,
+		marketType:    req.MarketType,
+		side:          req.Side,
+		positionSide:  req.PositionSide,
+		orderType:     req.OrderType,
+		timeInForce:   tif,
+		expireTime:    req.ExpireTime,
+		price:         req.Price,
+		stopPrice:     req.StopPrice,
+		activation:    req.ActivationPrice,
+		callbackRate:  req.CallbackRate,
+		size:          req.Size,
+		leverage:      decimal.NewFromInt(p.leverage(symbol)),
+		state:         exchange.OrderStateNew,
+		createTime:    ts,
+		updateTime:    ts,
+	}
+
+	// 冻结资金所用参考价格
+	refPrice := req.Price
+	if req.OrderType == exchange.OrderTypeMarket {
+		if !last.IsPositive() {
+			return nil, ErrNoMarketPrice
+		}
+		refPrice = last
+		o.price = decimal.Zero
+	}
+	// 市价条件单依次使用触发价格、激活价格和最新价格冻结
+	for _, v := range []decimal.Decimal{req.StopPrice, req.ActivationPrice, last} {
+		if refPrice.IsPositive() || !o.isMarketTrigger() {
+			break
+		}
+		refPrice = v
+	}
+	if !refPrice.IsPositive() {
+		return nil, ErrInvalidOrder
+	}
+
+	cross := last.IsPositive() && crosses(o, last)
+	// 只做 Maker 的限价单会立即成交则直接拒绝
+	if o.orderType == exchange.OrderTypeLimitMaker && cross {
+		o.state = exchange.OrderStateRejected
+		p.addOrder(o)
+		p.pushOrderEvent(o, exchange.ExecutionStateRejected, decimal.Zero, decimal.Zero, decimal.Zero)
+		return o.toCreateOrderResponse(nil), nil
+	}
+
+	if err := p.freeze(o, refPrice); err != nil {
+		return nil, err
+	}
+
+	p.addOrder(o)
+	p.pushOrderEvent(o, exchange.ExecutionStateNew, decimal.Zero, decimal.Zero, decimal.Zero)
+
+	fillStart := len(p.fills)
+	switch {
+	case o.orderType == exchange.OrderTypeMarket:
+		p.fill(o, last, o.remaining(), exchange.ByTaker)
+	case o.isConditional():
+		if o.orderType == exchange.OrderTypeTrailingStopMarket && o.activation.IsZero() && last.IsPositive() {
+			o.activated, o.extreme = true, last
+		}
+		p.books[key] = append(p.books[key], o)
+	case o.timeInForce == exchange.TimeInForceGTX && cross:
+		p.finish(o, exchange.OrderStateExpired, exchange.ExecutionStateExpired)
+	case cross:
+		p.fill(o, last, o.remaining(), exchange.ByTaker)
+	case o.timeInForce == exchange.TimeInForceIOC || o.timeInForce == exchange.TimeInForceFOK:
+		p.finish(o, exchange.OrderStateExpired, exchange.ExecutionStateExpired)
+	default:
+		p.books[key] = append(p.books[key], o)
+	}
+	if o.isOpen() && o.timeInForce == exchange.TimeInForceGTD {
+		p.expiring = append(p.expiring, o)
+	}
+	return o.toCreateOrderResponse(p.fills[fillStart:]), nil
+}
+
+func (p *PaperExchange) onTrade(te *exchange.TradeEvent) {
+	wallet := walletOf(te.MarketType)
+	if wallet == "" || !te.Price.IsPositive() {
+		return
+	}
+	if te.TradedAt > p.now {
+		p.now = te.TradedAt
+		p.expireOrders()
+	}
+	key := bookKey(wallet, te.Symbol)
+	p.lastPrices[key] = te.Price
+
+	book := p.books[key]
+	if len(book) == 0 {
+		return
+	}
+
+	// 触发条件单，市价条件单以触发价格吃单成交
+	for _, o := range book {
+		if !o.isOpen() || !o.isConditional() || o.triggered || !triggered(o, te.Price) {
+			continue
+		}
+		o.triggered = true
+		if o.isMarketTrigger() {
+			p.fill(o, te.Price, o.remaining(), exchange.ByTaker)
+		}
+	}
+
+	// 撮合挂单，价格优先、时间优先
+	resting := make([]*order, 0, len(book))
+	for _, o := range book {
+		if o.isOpen() && (!o.isConditional() || o.triggered) && crosses(o, te.Price) {
+			resting = append(resting, o)
+		}
+	}
+	sort.SliceStable(resting, func(i, j int) bool {
+		if resting[i].side != resting[j].side {
+			return resting[i].side == exchange.SideTypeBuy
+		}
+		if resting[i].side == exchange.SideTypeBuy {
+			return resting[i].price.GreaterThan(resting[j].price)
+		}
+		return resting[i].price.LessThan(resting[j].price)
+	})
+	liquidity := map[exchange.SideType]decimal.Decimal{
+		exchange.SideTypeBuy:  te.Size,
+		exchange.SideTypeSell: te.Size,
+	}
+	for _, o := range resting {
+		qty := o.remaining()
+		if p.opts.fillByTradeSize {
+			qty = decimal.Min(qty, liquidity[o.side])
+			if !qty.IsPositive() {
+				continue
+			}
+			liquidity[o.side] = liquidity[o.side].Sub(qty)
+		}
+		p.fill(o, o.price, qty, exchange.ByMaker)
+	}
+
+	p.compact(key)
+}
+
+// freeze 下单冻结资金或仓位
+func (p *PaperExchange) freeze(o *order, refPrice decimal.Decimal) error {
+	maxFee := decimal.Max(p.opts.makerFee, p.opts.takerFee)
+	size := o.remaining()
+	var amount decimal.Decimal
+	switch {
+	case o.wallet == walletSpot && o.side == exchange.SideTypeBuy:
+		o.lockAsset = o.quoteAsset
+		amount = refPrice.Mul(size).Mul(decimal.NewFromInt(1).Add(maxFee))
+	case o.wallet == walletSpot:
+		o.lockAsset = o.baseAsset
+		amount = size
+	case o.isClose():
+		pos, ok := p.positions[positionKey(o.symbol, o.positionSide)]
+		if !ok || pos.size.Sub(pos.pending).LessThan(size) {
+			return ErrPositionNotEnough
+		}
+		pos.pending = pos.pending.Add(size)
+		return nil
+	default:
+		o.lockAsset = o.quoteAsset
+		notional := refPrice.Mul(size)
+		amount = notional.Div(o.leverage).Add(notional.Mul(maxFee))
+	}
+
+	b := p.balance(o.wallet, o.lockAsset)
+	if b.free.LessThan(amount) {
+		if o.wallet == walletFutures {
+			return exchange.ErrOrderNotEnoughMargin
+		}
+		return exchange.ErrOrderNotEnoughBalance
+	}
+	b.free = b.free.Sub(amount)
+	b.locked = b.locked.Add(amount)
+	o.locked = amount
+	p.touch(o.wallet, o.lockAsset)
+	return nil
+}
+
+// release 撤单或过期时释放剩余冻结
+func (p *PaperExchange) release(o *order) {
+	if o.isClose() {
+		if pos, ok := p.positions[positionKey(o.symbol, o.positionSide)]; ok {
+			pos.pending = decimal.Max(pos.pending.Sub(o.remaining()), decimal.Zero)
+		}
+		return
+	}
+	if o.lockAsset == "" || o.locked.IsZero() {
+		return
+	}
+	b := p.balance(o.wallet, o.lockAsset)
+	b.free = b.free.Add(o.locked)
+	b.locked = b.locked.Sub(o.locked)
+	o.locked = decimal.Zero
+	p.touch(o.wallet, o.lockAsset)
+}
+
+// fill 按价格成交指定数量，结算资金、仓位并推送成交事件
+func (p *PaperExchange) fill(o *order, price, qty decimal.Decimal, by string) {
+	rate := p.opts.takerFee
+	if by == exchange.ByMaker {
+		rate = p.opts.makerFee
+	}
+	cost := price.Mul(qty)
+	fee := cost.Mul(rate)
+
+	// 按成交比例释放冻结
+	release := decimal.Zero
+	if remaining := o.remaining(); remaining.IsPositive() && o.locked.IsPositive() {
+		release = o.locked.Mul(qty).Div(remaining)
+		if qty.Equal(remaining) {
+			release = o.locked
+		}
+		o.locked = o.locked.Sub(release)
+	}
+
+	ts := p.timestamp()
+	pnl := decimal.Zero
+	quote := p.balance(o.wallet, o.quoteAsset)
+	switch {
+	case o.wallet == walletSpot && o.side == exchange.SideTypeBuy:
+		quote.locked = quote.locked.Sub(release)
+		quote.free = quote.free.Add(release).Sub(cost).Sub(fee)
+		base := p.balance(o.wallet, o.baseAsset)
+		held := base.free.Add(base.locked)
+		base.avgCost = base.avgCost.Mul(held).Add(cost).Div(held.Add(qty))
+		base.free = base.free.Add(qty)
+		p.touch(o.wallet, o.baseAsset)
+	case o.wallet == walletSpot:
+		base := p.balance(o.wallet, o.baseAsset)
+		base.locked = base.locked.Sub(release)
+		quote.free = quote.free.Add(cost).Sub(fee)
+		if base.avgCost.IsPositive() {
+			pnl = price.Sub(base.avgCost).Mul(qty)
+		}
+		p.touch(o.wallet, o.baseAsset)
+	case o.isClose():
+		key := positionKey(o.symbol, o.positionSide)
+		pos := p.positions[key]
+		pnl = price.Sub(pos.avgPrice).Mul(qty)
+		if pos.side == exchange.PositionSideShort {
+			pnl = pnl.Neg()
+		}
+		margin := pos.margin.Mul(qty).Div(pos.size)
+		pos.margin = pos.margin.Sub(margin)
+		pos.size = pos.size.Sub(qty)
+		pos.pending = decimal.Max(pos.pending.Sub(qty), decimal.Zero)
+		pos.realizedPnl = pos.realizedPnl.Add(pnl)
+		pos.fee = pos.fee.Add(fee)
+		pos.closedSize = pos.closedSize.Add(qty)
+		pos.closedQuote = pos.closedQuote.Add(cost)
+		pos.updateTime = ts
+		quote.locked = quote.locked.Sub(margin)
+		quote.free = quote.free.Add(margin).Add(pnl).Sub(fee)
+		if !pos.size.IsPositive() {
+			delete(p.positions, key)
+			p.closed = append(p.closed, &exchange.ClosedPosition{
+				Symbol:        pos.symbol,
+				MarketType:    pos.marketType,
+				PositionSide:  pos.side,
+				OpenAvgPrice:  pos.avgPrice,
+				CloseAvgPrice: pos.closedQuote.Div(pos.closedSize),
+				Size:          pos.closedSize,
+				Pnl:           pos.realizedPnl,
+				Fee:           pos.fee,
+				FundingFee:    pos.fundingFee,
+				RealizedPnl:   pos.realizedPnl.Sub(pos.fee).Sub(pos.fundingFee),
+				OpenTime:      pos.createTime,
+				CloseTime:     ts,
+			})
+		}
+	default:
+		margin := cost.Div(o.leverage)
+		quote.locked = quote.locked.Sub(release).Add(margin)
+		quote.free = quote.free.Add(release).Sub(margin).Sub(fee)
+		key := positionKey(o.symbol, o.positionSide)
+		pos, ok := p.positions[key]
+		if !ok {
+			pos = &position{
+				symbol:     o.symbol,
+				marketType: o.marketType,
+				side:       o.positionSide,
+				createTime: ts,
+			}
+			p.positions[key] = pos
+		}
+		pos.avgPrice = pos.avgPrice.Mul(pos.size).Add(cost).Div(pos.size.Add(qty))
+		pos.size = pos.size.Add(qty)
+		pos.margin = pos.margin.Add(margin)
+		pos.fee = pos.fee.Add(fee)
+		pos.updateTime = ts
+	}
+	p.touch(o.wallet, o.quoteAsset)
+
+	o.filled = o.filled.Add(qty)
+	o.filledQuote = o.filledQuote.Add(cost)
+	o.fee = o.fee.Add(fee)
+	o.by = by
+	o.updateTime = ts
+	o.state = exchange.OrderStatePartiallyFilled
+	if !o.remaining().IsPositive() {
+		o.state = exchange.OrderStateFilled
+		// 释放多冻结的部分
+		p.release(o)
+	}
+
+	p.tradeSeq++
+	p.fills = append(p.fills, &Fill{
+		TradeID:       strconv.FormatInt(p.tradeSeq, 10),
+		OrderID:       o.id,
+		ClientOrderID: o.clientOrderID,
+		Symbol:        o.symbol,
+		MarketType:    o.marketType,
+		Side:          o.side,
+		PositionSide:  o.positionSide,
+		Price:         price,
+		Size:          qty,
+		Fee:           fee,
+		FeeAsset:      o.quoteAsset,
+		RealizedPnl:   pnl,
+		By:            by,
+		Time:          ts,
+	})
+	p.pushOrderEvent(o, exchange.ExecutionStateTrade, price, qty, fee)
+}
+
+// finish 结束订单（撤销、过期）
+func (p *PaperExchange) finish(o *order, state exchange.OrderState, et exchange.ExecutionState) {
+	p.release(o)
+	o.state = state
+	o.updateTime = p.timestamp()
+	p.compact(bookKey(o.wallet, o.symbol))
+	p.pushOrderEvent(o, et, decimal.Zero, decimal.Zero, decimal.Zero)
+}
+
+// expireOrders 模拟时钟推进后撤销到期的 GTD 订单，推送 EXPIRED 订单事件
+func (p *PaperExchange) expireOrders() {
+	pending := p.expiring[:0]
+	for _, o := range p.expiring {
+		if !o.isOpen() {
+			continue
+		}
+		if o.expireTime > p.now {
+			pending = append(pending, o)
+			continue
+		}
+		p.finish(o, exchange.OrderStateExpired, exchange.ExecutionStateExpired)
+	}
+	for i := len(pending); i < len(p.expiring); i++ {
+		p.expiring[i] = nil
+	}
+	p.expiring = pending
+}
+
+// addOrder 分配订单号并记录订单，客户端订单号为空时使用 paper- 加订单号
+func (p *PaperExchange) addOrder(o *order) {
+	p.orderSeq++
+	o.id = strconv.FormatInt(p.orderSeq, 10)
+	if o.clientOrderID == "" {
+		o.clientOrderID = "paper-" + o.id
+	}
+	p.orders[o.id] = o
+	p.clients[o.clientOrderID] = o
+}
+
+func (p *PaperExchange) findOrder(clientOrderID, orderID string) *order {
+	if clientOrderID != "" {
+		return p.clients[clientOrderID]
+	}
+	return p.orders[orderID]
+}
+
+// cancelTarget 撤单时优先按订单ID查找
+func (p *PaperExchange) cancelTarget(o *exchange.CancelOrderRequest) *order {
+	if o.OrderID != "" {
+		return p.orders[o.OrderID]
+	}
+	return p.clients[o.ClientOrderID]
+}
+
+func (p *PaperExchange) compact(key string) {
+	book := p.books[key]
+	open := book[:0]
+	for _, o := range book {
+		if o.isOpen() {
+			open = append(open, o)
+		}
+	}
+	for i := len(open); i < len(book); i++ {
+		book[i] = nil
+	}
+	if len(open) == 0 {
+		delete(p.books, key)
+		return
+	}
+	p.books[key] = open
+}
+
+func (p *PaperExchange) pushOrderEvent(o *order, et exchange.ExecutionState, latestPrice, latestVolume, fee decimal.Decimal) {
+	p.orderEvents = append(p.orderEvents, &exchange.OrderResultEvent{
+		Exchange:          exchange.PaperExchange,
+		ClientOrderID:     o.clientOrderID,
+		Symbol:            o.symbol,
+		OrderID:           o.id,
+		FeeAsset:          o.quoteAsset,
+		TransactionTime:   o.updateTime,
+		By:                o.by,
+		MarketType:        o.marketType,
+		ExecutionType:     et,
+		State:             o.state,
+		PositionSide:      o.positionSide,
+		Side:              o.side,
+		Type:              o.orderType,
+		Volume:            o.size,
+		Price:             o.price,
+		LatestVolume:      latestVolume,
+		FilledVolume:      o.filled,
+		LatestPrice:       latestPrice,
+		FeeCost:           fee,
+		FilledQuoteVolume: o.filledQuote,
+		LatestQuoteVolume: latestPrice.Mul(latestVolume),
+		QuoteVolume:       o.price.Mul(o.size),
+		AvgPrice:          o.avgPrice(),
+	})
+}
+
+func (p *PaperExchange) accountEvents() map[string][]*exchange.AccountUpdateEvent {
+	if len(p.changed) == 0 {
+		return nil
+	}
+	result := make(map[string][]*exchange.AccountUpdateEvent, len(p.changed))
+	for wallet, assets := range p.changed {
+		evts := make([]*exchange.AccountUpdateEvent, 0, len(assets))
+		for asset := range assets {
+			b := p.balance(wallet, asset)
+			evts = append(evts, &exchange.AccountUpdateEvent{
+				Asset:   asset,
+				Balance: b.free.Add(b.locked),
+			})
+		}
+		sort.Slice(evts, func(i, j int) bool {
+			return evts[i].Asset < evts[j].Asset
+		})
+		result[wallet] = evts
+	}
+	p.changed = make(map[string]map[string]struct{})
+	return result
+}
+
+func (p *PaperExchange) touch(wallet, asset string) {
+	if _, ok := p.changed[wallet]; !ok {
+		p.changed[wallet] = make(map[string]struct{})
+	}
+	p.changed[wallet][asset] = struct{}{}
+}
+
+func (p *PaperExchange) balance(wallet, asset string) *balance {
+	b, ok := p.balances[wallet][asset]
+	if !ok {
+		b = &balance{}
+		p.balances[wallet][asset] = b
+	}
+	return b
+}
+
+func (p *PaperExchange) leverage(symbol string) int64 {
+	if lever, ok := p.leverages[symbol]; ok {
+		return lever
+	}
+	return p.opts.defaultLeverage
+}
+
+func (p *PaperExchange) timestamp() int64 {
+	if p.now > 0 {
+		return p.now
+	}
+	return time.Now().UnixMilli()
+}
+
+// splitSymbol 拆分基础资产与计价资产
+func (p *PaperExchange) splitSymbol(symbol exchange.Symbol) (string, string, error) {
+	name := strings.ToUpper(symbol.OriginalSymbol)
+	if parts := strings.Split(name, "-"); len(parts) >= 2 {
+		return parts[0], parts[1], nil
+	}
+	asset := strings.ToUpper(symbol.OriginalAsset)
+	if asset != "" && asset != name && strings.HasPrefix(name, asset) {
+		return asset, strings.TrimPrefix(name, asset), nil
+	}
+	for _, quote := range p.opts.quoteAssets {
+		if quote != name && strings.HasSuffix(name, quote) {
+			return strings.TrimSuffix(name, quote), quote, nil
+		}
+	}
+	return "", "", errors.New("unknown quote asset: " + symbol.OriginalSymbol)
+}
+
+// openOrders 按下单顺序返回未完成订单，symbol 为空时返回该账户全部未完成订单
+func (p *PaperExchange) openOrders(marketType exchange.MarketType, symbol string) ([]*order, error) {
+	wallet := walletOf(marketType)
+	if wallet == "" {
+		return nil, exchange.ErrInstrumentTypeNotSupported
+	}
+	result := make([]*order, 0)
+	for _, o := range p.orders {
+		if o.wallet != wallet || !o.isOpen() {
+			continue
+		}
+		if symbol != "" && o.symbol != symbol {
+			continue
+		}
+		result = append(result, o)
+	}
+	sortOrders(result)
+	return result, nil
+}
+
+// sortOrders 按订单号即下单顺序排序
+func sortOrders(orders []*order) {
+	sort.Slice(orders, func(i, j int) bool {
+		if len(orders[i].id) != len(orders[j].id) {
+			return len(orders[i].id) < len(orders[j].id)
+		}
+		return orders[i].id < orders[j].id
+	})
+}
+
+// inRange 判断时间是否在 [start, end] 内，为 0 表示不限制
+func inRange(ts, start, end int64) bool {
+	return (start <= 0 || ts >= start) && (end <= 0 || ts <= end)
+}
+
+func walletOf(marketType exchange.MarketType) string {
+	switch marketType {
+	case exchange.MarketTypeSpot, exchange.MarketTypeMargin:
+		return walletSpot
+	case exchange.MarketTypeFuturesUSDMargined, exchange.MarketTypePerpetualUSDMargined:
+		return walletFutures
+	}
+	return ""
+}
+
+func bookKey(wallet, symbol string) string {
+	return wallet + ":" + symbol
+}
+
+func positionKey(symbol string, side exchange.PositionSide) string {
+	return symbol + ":" + string(side)
+}
+
+// freezePrice 市价条件单没有委托价格时使用触发价格冻结
+func freezePrice(o *order, price decimal.Decimal) decimal.Decimal {
+	if price.IsPositive() {
+		return price
+	}
+	return o.triggerPrice()
+}
+
+// crosses 价格是否满足限价单成交条件
+func crosses(o *order, price decimal.Decimal) bool {
+	if o.side == exchange.SideTypeBuy {
+		return price.LessThanOrEqual(o.price)
+	}
+	return price.GreaterThanOrEqual(o.price)
+}
+
+// triggered 价格是否触发条件单
+func triggered(o *order, price decimal.Decimal) bool {
+	if o.orderType == exchange.OrderTypeTrailingStopMarket {
+		return trailingTriggered(o, price)
+	}
+	stop := o.orderType == exchange.OrderTypeStop || o.orderType == exchange.OrderTypeStopMarket
+	if (o.side == exchange.SideTypeBuy) == stop {
+		return price.GreaterThanOrEqual(o.triggerPrice())
+	}
+	return price.LessThanOrEqual(o.triggerPrice())
+}
+
+// trailingTriggered 跟踪止损到达激活价格后记录最优价格，自最优价格回调超过回调比例时触发
+func trailingTriggered(o *order, price decimal.Decimal) bool {
+	buy := o.side == exchange.SideTypeBuy
+	if !o.activated {
+		if !o.activation.IsZero() && (buy && price.GreaterThan(o.activation) || !buy && price.LessThan(o.activation)) {
+			return false
+		}
+		o.activated, o.extreme = true, price
+	}
+	one := decimal.NewFromInt(1)
+	if buy {
+		o.extreme = decimal.Min(o.extreme, price)
+		return price.GreaterThanOrEqual(o.extreme.Mul(one.Add(o.callbackRate)))
+	}
+	o.extreme = decimal.Max(o.extreme, price)
+	return price.LessThanOrEqual(o.extreme.Mul(one.Sub(o.callbackRate)))
+}
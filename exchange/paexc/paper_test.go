@@ -0,0 +1,496 @@
+package paexc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestExchange(t *testing.T) (*PaperExchange, *[]*exchange.OrderResultEvent) {
+	p := NewPaperExchange(
+		WithMakerFee(decimal.NewFromFloat(0.001)),
+		WithTakerFee(decimal.NewFromFloat(0.001)),
+	)
+	events := make([]*exchange.OrderResultEvent, 0)
+	p.Subscribe("test", &Listener{
+		OrderEvent: func(evt *exchange.OrderResultEvent) {
+			events = append(events, evt)
+		},
+	})
+	assert.NoError(t, p.SetBalance(exchange.MarketTypeSpot, "USDT", decimal.NewFromInt(1000)))
+	assert.NoError(t, p.SetBalance(exchange.MarketTypePerpetualUSDMargined, "USDT", decimal.NewFromInt(1000)))
+	return p, &events
+}
+
+func trade(symbol string, mt exchange.MarketType, price, size int64) *exchange.TradeEvent {
+	return &exchange.TradeEvent{
+		TradedAt:   1000,
+		Symbol:     symbol,
+		Price:      decimal.NewFromInt(price),
+		Size:       decimal.NewFromInt(size),
+		MarketType: mt,
+	}
+}
+
+func TestSpotLimitOrderPartialFill(t *testing.T) {
+	p, events := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 110, 1))
+	_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "1",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimit,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(2),
+		Price:         decimal.NewFromInt(100),
+	})
+	assert.NoError(t, err)
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 99, 5))
+
+	assert.Len(t, *events, 3)
+	assert.Equal(t, exchange.ExecutionStateNew, (*events)[0].ExecutionType)
+	assert.Equal(t, exchange.OrderStatePartiallyFilled, (*events)[1].State)
+	assert.Equal(t, exchange.OrderStateFilled, (*events)[2].State)
+	assert.Equal(t, exchange.ByMaker, (*events)[2].By)
+	assert.True(t, decimal.NewFromInt(100).Equal((*events)[2].AvgPrice))
+
+	assets, err := p.Assets(ctx, &exchange.GetAssetsRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.Len(t, assets, 2)
+	assert.Equal(t, "BTC", assets[0].AssetName)
+	assert.True(t, decimal.NewFromInt(2).Equal(assets[0].Free))
+	assert.True(t, decimal.NewFromFloat(799.8).Equal(assets[1].Free))
+	assert.True(t, assets[1].Locked.IsZero())
+}
+
+func TestFuturesOpenAndClose(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+	symbol := exchange.Symbol{OriginalSymbol: "BTCUSDT"}
+
+	assert.NoError(t, p.SetLeverage(ctx, &exchange.SetLeverageRequest{Symbol: "BTCUSDT", Lever: "10"}))
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypePerpetualUSDMargined, 100, 1))
+	_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        symbol,
+		ClientOrderID: "open",
+		Side:          exchange.SideTypeBuy,
+		PositionSide:  exchange.PositionSideLong,
+		OrderType:     exchange.OrderTypeMarket,
+		MarketType:    exchange.MarketTypePerpetualUSDMargined,
+		Size:          decimal.NewFromInt(10),
+	})
+	assert.NoError(t, err)
+
+	_, err = p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        symbol,
+		ClientOrderID: "close",
+		Side:          exchange.SideTypeSell,
+		PositionSide:  exchange.PositionSideLong,
+		OrderType:     exchange.OrderTypeTakeProfitMarket,
+		MarketType:    exchange.MarketTypePerpetualUSDMargined,
+		Size:          decimal.NewFromInt(10),
+		Price:         decimal.NewFromInt(110),
+	})
+	assert.NoError(t, err)
+
+	positions, err := p.GetPosition(ctx, &exchange.GetPositionRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.True(t, decimal.NewFromInt(100).Equal(positions[0].Margin))
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypePerpetualUSDMargined, 111, 1))
+
+	positions, err = p.GetPosition(ctx, &exchange.GetPositionRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, positions, 0)
+
+	order, err := p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "close"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateFilled, order.State)
+	assert.Equal(t, exchange.ByTaker, order.By)
+
+	// 1000 + 110 盈利 - 1 开仓手续费 - 1.11 平仓手续费
+	assets, err := p.Assets(ctx, &exchange.GetAssetsRequest{MarketType: exchange.MarketTypePerpetualUSDMargined})
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(1107.89).Equal(assets[0].Free))
+	assert.True(t, assets[0].Locked.IsZero())
+
+	ledger, err := exchange.Collect(ctx, p.Ledger(&exchange.GetLedgerRequest{MarketType: exchange.MarketTypePerpetualUSDMargined}))
+	assert.NoError(t, err)
+	total := decimal.Zero
+	for _, v := range ledger {
+		total = total.Add(v.Amount)
+	}
+	assert.Len(t, ledger, 3)
+	assert.True(t, decimal.NewFromFloat(107.89).Equal(total))
+}
+
+func TestLimitMakerRejected(t *testing.T) {
+	p, events := newTestExchange(t)
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	_, err := p.CreateOrder(context.Background(), &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "1",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimitMaker,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+		Price:         decimal.NewFromInt(101),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *events, 1)
+	assert.Equal(t, exchange.OrderStateRejected, (*events)[0].State)
+}
+
+func TestCreateOrderAck(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	ack, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "1",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeMarket,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(2),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateFilled, ack.State)
+	assert.True(t, decimal.NewFromInt(2).Equal(ack.ExecutedQuantity))
+	assert.True(t, decimal.NewFromInt(100).Equal(ack.AvgPrice))
+	assert.Len(t, ack.Fills, 1)
+	assert.Equal(t, ack.OrderID, ack.Fills[0].OrderID)
+
+	ack, err = p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "2",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceIOC,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+		Price:         decimal.NewFromInt(90),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateExpired, ack.State)
+	assert.True(t, ack.ExecutedQuantity.IsZero())
+	assert.Empty(t, ack.Fills)
+}
+
+func TestTrailingStopOrder(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+	symbol := exchange.Symbol{OriginalSymbol: "BTCUSDT"}
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        symbol,
+		ClientOrderID: "buy",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeMarket,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(5),
+	})
+	assert.NoError(t, err)
+
+	// 价格到达 110 后激活，自最高价回调 5% 触发
+	_, err = p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:          symbol,
+		ClientOrderID:   "trailing",
+		Side:            exchange.SideTypeSell,
+		OrderType:       exchange.OrderTypeTrailingStopMarket,
+		MarketType:      exchange.MarketTypeSpot,
+		Size:            decimal.NewFromInt(2),
+		ActivationPrice: decimal.NewFromInt(110),
+		CallbackRate:    decimal.NewFromFloat(0.05),
+	})
+	assert.NoError(t, err)
+
+	for _, price := range []int64{105, 112, 120, 115} {
+		p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, price, 1))
+	}
+	algo, err := p.SearchAlgoOrder(ctx, &exchange.AlgoOrderRequest{ClientOrderID: "trailing"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateNew, algo.State)
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 113, 1))
+	algo, err = p.SearchAlgoOrder(ctx, &exchange.AlgoOrderRequest{ClientOrderID: "trailing"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateFilled, algo.State)
+	assert.True(t, decimal.NewFromInt(113).Equal(algo.Orders[0].AvgPrice))
+
+	// 按触发价格触发的止损单
+	_, err = p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        symbol,
+		ClientOrderID: "stop",
+		Side:          exchange.SideTypeSell,
+		OrderType:     exchange.OrderTypeStopMarket,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+		StopPrice:     decimal.NewFromInt(100),
+	})
+	assert.NoError(t, err)
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 101, 1))
+	assert.NoError(t, p.CancelAlgoOrder(ctx, &exchange.AlgoOrderRequest{ClientOrderID: "stop"}))
+	order, err := p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "stop"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateCanceled, order.State)
+}
+
+func TestGTDOrderExpired(t *testing.T) {
+	p, events := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "gtd",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimit,
+		TimeInForce:   exchange.TimeInForceGTD,
+		ExpireTime:    2000,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+		Price:         decimal.NewFromInt(90),
+	})
+	assert.NoError(t, err)
+
+	te := trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1)
+	te.TradedAt = 2000
+	p.OnTrade(te)
+
+	order, err := p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "gtd"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateExpired, order.State)
+	last := (*events)[len(*events)-1]
+	assert.Equal(t, exchange.ExecutionStateExpired, last.ExecutionType)
+
+	assets, err := p.Assets(ctx, &exchange.GetAssetsRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.True(t, assets[0].Locked.IsZero())
+}
+
+func TestAmendOrder(t *testing.T) {
+	p, events := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "1",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimit,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(2),
+		Price:         decimal.NewFromInt(90),
+	})
+	assert.NoError(t, err)
+
+	res, err := p.AmendOrder(ctx, &exchange.AmendOrderRequest{
+		ClientOrderID:    "1",
+		NewClientOrderID: "2",
+		NewPrice:         decimal.NewFromInt(95),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2", res.ClientOrderID)
+	assert.Equal(t, exchange.ExecutionStateAmendment, (*events)[1].ExecutionType)
+
+	_, err = p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "1"})
+	assert.ErrorIs(t, err, exchange.ErrOrderNotFound)
+
+	assets, err := p.Assets(ctx, &exchange.GetAssetsRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(190.19).Equal(assets[0].Locked))
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 95, 5))
+	order, err := p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, exchange.OrderStateFilled, order.State)
+
+	// 只做 maker 的订单改价后会立即成交时拒绝
+	_, err = p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+		Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+		ClientOrderID: "3",
+		Side:          exchange.SideTypeBuy,
+		OrderType:     exchange.OrderTypeLimitMaker,
+		MarketType:    exchange.MarketTypeSpot,
+		Size:          decimal.NewFromInt(1),
+		Price:         decimal.NewFromInt(90),
+	})
+	assert.NoError(t, err)
+	_, err = p.AmendOrder(ctx, &exchange.AmendOrderRequest{
+		ClientOrderID: "3",
+		NewPrice:      decimal.NewFromInt(100),
+	})
+	assert.ErrorIs(t, err, exchange.ErrPostOnlyRejected)
+}
+
+func TestBatchOrders(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+	symbol := exchange.Symbol{OriginalSymbol: "BTCUSDT"}
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	res, err := p.BatchCreateOrders(ctx, []*exchange.CreateOrderRequest{
+		{Symbol: symbol, ClientOrderID: "1", Side: exchange.SideTypeBuy, OrderType: exchange.OrderTypeLimit, MarketType: exchange.MarketTypeSpot, Size: decimal.NewFromInt(1), Price: decimal.NewFromInt(90)},
+		{Symbol: symbol, ClientOrderID: "2", Side: exchange.SideTypeBuy, OrderType: exchange.OrderTypeLimit, MarketType: exchange.MarketTypeSpot, Size: decimal.Zero, Price: decimal.NewFromInt(90)},
+		{Symbol: symbol, ClientOrderID: "3", Side: exchange.SideTypeBuy, OrderType: exchange.OrderTypeLimit, MarketType: exchange.MarketTypeSpot, Size: decimal.NewFromInt(1), Price: decimal.NewFromInt(80)},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, res, 3)
+	assert.NoError(t, res[0].Err)
+	assert.NotEmpty(t, res[0].OrderID)
+	assert.ErrorIs(t, res[1].Err, ErrInvalidOrder)
+	assert.NoError(t, res[2].Err)
+
+	res, err = p.BatchCancelOrders(ctx, []*exchange.CancelOrderRequest{
+		{Symbol: "BTCUSDT", ClientOrderID: "1", MarketType: exchange.MarketTypeSpot},
+		{Symbol: "BTCUSDT", ClientOrderID: "2", MarketType: exchange.MarketTypeSpot},
+		{Symbol: "BTCUSDT", ClientOrderID: "3", MarketType: exchange.MarketTypeSpot},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, res[0].Err)
+	assert.ErrorIs(t, res[1].Err, exchange.ErrOrderNotFound)
+	assert.NoError(t, res[2].Err)
+}
+
+func TestCancelAllOrders(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	p.OnTrade(trade("ETHUSDT", exchange.MarketTypeSpot, 10, 1))
+	for i, symbol := range []string{"BTCUSDT", "ETHUSDT", "BTCUSDT"} {
+		_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+			Symbol:        exchange.Symbol{OriginalSymbol: symbol},
+			ClientOrderID: string(rune('a' + i)),
+			Side:          exchange.SideTypeBuy,
+			OrderType:     exchange.OrderTypeLimit,
+			MarketType:    exchange.MarketTypeSpot,
+			Size:          decimal.NewFromInt(1),
+			Price:         decimal.NewFromInt(5),
+		})
+		assert.NoError(t, err)
+	}
+
+	orders, err := p.GetOpenOrders(ctx, &exchange.GetOpenOrdersRequest{MarketType: exchange.MarketTypeSpot, Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+	assert.Equal(t, "a", orders[0].ClientOrderID)
+
+	assert.NoError(t, p.CancelAllOrders(ctx, &exchange.CancelAllOrdersRequest{MarketType: exchange.MarketTypeSpot, Symbol: "BTCUSDT"}))
+	orders, err = p.GetOpenOrders(ctx, &exchange.GetOpenOrdersRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Equal(t, "ETHUSDT", orders[0].Symbol)
+
+	assert.NoError(t, p.CancelAllOrders(ctx, &exchange.CancelAllOrdersRequest{MarketType: exchange.MarketTypeSpot}))
+	orders, err = p.GetOpenOrders(ctx, &exchange.GetOpenOrdersRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.Len(t, orders, 0)
+}
+
+func TestHistoryIterators(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+	symbol := exchange.Symbol{OriginalSymbol: "BTCUSDT"}
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypePerpetualUSDMargined, 100, 1))
+	for _, side := range []exchange.SideType{exchange.SideTypeBuy, exchange.SideTypeSell} {
+		_, err := p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+			Symbol:        symbol,
+			ClientOrderID: string(side),
+			Side:          side,
+			PositionSide:  exchange.PositionSideLong,
+			OrderType:     exchange.OrderTypeMarket,
+			MarketType:    exchange.MarketTypePerpetualUSDMargined,
+			Size:          decimal.NewFromInt(1),
+		})
+		assert.NoError(t, err)
+	}
+
+	orders, err := exchange.Collect(ctx, p.OrderHistory(&exchange.GetOrderHistoryRequest{MarketType: exchange.MarketTypePerpetualUSDMargined}))
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+	assert.Equal(t, string(exchange.SideTypeBuy), orders[0].ClientOrderID)
+
+	trades, err := exchange.Collect(ctx, p.TradeHistory(&exchange.GetTradeHistoryRequest{MarketType: exchange.MarketTypePerpetualUSDMargined}))
+	assert.NoError(t, err)
+	assert.Len(t, trades, 2)
+
+	// 市场类型为空时返回错误，而不是空结果
+	_, err = exchange.Collect(ctx, p.OrderHistory(&exchange.GetOrderHistoryRequest{}))
+	assert.ErrorIs(t, err, exchange.ErrInstrumentTypeNotSupported)
+	_, err = exchange.Collect(ctx, p.TradeHistory(&exchange.GetTradeHistoryRequest{}))
+	assert.ErrorIs(t, err, exchange.ErrInstrumentTypeNotSupported)
+
+	positions, err := exchange.Collect(ctx, p.ClosedPositions(&exchange.GetClosedPositionsRequest{}))
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.True(t, decimal.NewFromInt(1).Equal(positions[0].Size))
+	assert.True(t, decimal.NewFromFloat(-0.2).Equal(positions[0].RealizedPnl))
+}
+
+func TestClientOrderID(t *testing.T) {
+	p, _ := newTestExchange(t)
+	ctx := context.Background()
+
+	p.OnTrade(trade("BTCUSDT", exchange.MarketTypeSpot, 100, 1))
+	create := func(clientOrderID string) (*exchange.CreateOrderResponse, error) {
+		return p.CreateOrder(ctx, &exchange.CreateOrderRequest{
+			Symbol:        exchange.Symbol{OriginalSymbol: "BTCUSDT"},
+			ClientOrderID: clientOrderID,
+			Side:          exchange.SideTypeBuy,
+			OrderType:     exchange.OrderTypeLimit,
+			MarketType:    exchange.MarketTypeSpot,
+			Size:          decimal.NewFromInt(1),
+			Price:         decimal.NewFromInt(90),
+		})
+	}
+
+	// 客户端订单号为空时自动生成，可以同时挂多个订单
+	first, err := create("")
+	assert.NoError(t, err)
+	second, err := create("")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.ClientOrderID)
+	assert.NotEqual(t, first.ClientOrderID, second.ClientOrderID)
+
+	// 未完成订单的客户端订单号不能重复，订单完成后可以复用，原订单保留在历史中
+	_, err = create("a")
+	assert.NoError(t, err)
+	_, err = create("a")
+	assert.ErrorIs(t, err, exchange.ErrOrderAlreadyExists)
+	assert.NoError(t, p.CancelOrder(ctx, &exchange.CancelOrderRequest{ClientOrderID: "a"}))
+	reused, err := create("a")
+	assert.NoError(t, err)
+
+	order, err := p.SearchOrder(ctx, &exchange.SearchOrderRequest{ClientOrderID: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, reused.OrderID, order.OrderID)
+	assert.Equal(t, exchange.OrderStateNew, order.State)
+
+	orders, err := exchange.Collect(ctx, p.OrderHistory(&exchange.GetOrderHistoryRequest{MarketType: exchange.MarketTypeSpot}))
+	assert.NoError(t, err)
+	assert.Len(t, orders, 4)
+	assert.Equal(t, "a", orders[2].ClientOrderID)
+	assert.Equal(t, exchange.OrderStateCanceled, orders[2].State)
+
+	// 按订单ID撤单
+	assert.NoError(t, p.CancelOrder(ctx, &exchange.CancelOrderRequest{OrderID: first.OrderID}))
+	open, err := p.GetOpenOrders(ctx, &exchange.GetOpenOrdersRequest{MarketType: exchange.MarketTypeSpot})
+	assert.NoError(t, err)
+	assert.Len(t, open, 2)
+	for _, v := range open {
+		assert.NotEqual(t, first.OrderID, v.OrderID)
+	}
+}
@@ -49,6 +49,8 @@ func NewBinanceLimiter(redisClient *redis.Client, opts ...limiter.Option) *Binan
 		BorrowOrRepayWeights:      1200,
 		CreateMarginOrderWeights:  6,
 		GetMarginInventoryWeights: 50,
+		DepthSpotWeights:          50, // limit 1000 的深度快照
+		DepthFutureWeights:        20,
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -109,12 +111,16 @@ func (b *BinanceLimiter) SpotAllow(t *limiter.LimiterReq) bool {
 		return b.allowCreateOcoOrder(Exchange + "_" + limiter.SpotCreateOrderLimit + "_" + t.AccountId)
 	case limiter.CreateOrderLimit:
 		return b.allowCreateSpotOrder(Exchange + "_" + limiter.SpotCreateOrderLimit + "_" + t.AccountId)
+	case limiter.UpdateOrderLimit:
+		return b.allowUpdateSpotOrder(Exchange + "_" + limiter.SpotCreateOrderLimit + "_" + t.AccountId)
 	case limiter.CancelOrderLimit:
 		return b.allowCancelSpotOrder(Exchange + "_" + limiter.SpotNormalRequestLimit + "_" + b.ip)
 	case limiter.SearchOrderLimit:
 		return b.allowSearchSpotOrder(Exchange + "_" + limiter.SpotNormalRequestLimit + "_" + b.ip)
 	case limiter.NormalRequestLimit:
 		return b.allowSpotNormalRequest(Exchange + "_" + limiter.SpotNormalRequestLimit + "_" + b.ip)
+	case limiter.DepthSnapshotLimit:
+		return b.allowSpotDepthSnapshot(Exchange + "_" + limiter.SpotNormalRequestLimit + "_" + b.ip)
 	default:
 		return true
 	}
@@ -125,12 +131,16 @@ func (b *BinanceLimiter) FutureAllow(t *limiter.LimiterReq) bool {
 	switch t.LimiterType {
 	case limiter.CreateOrderLimit:
 		return b.allowCreateFutureOrder(Exchange + "_" + limiter.FutureCreateOrderLimit + "_" + t.AccountId)
+	case limiter.UpdateOrderLimit:
+		return b.allowUpdateFutureOrder(Exchange + "_" + limiter.FutureCreateOrderLimit + "_" + t.AccountId)
 	case limiter.CancelOrderLimit:
 		return b.allCancelFutureOrder()
 	case limiter.SearchOrderLimit:
 		return b.allSearchFutureOrder()
 	case limiter.NormalRequestLimit:
 		return b.allFutureNormalRequest()
+	case limiter.DepthSnapshotLimit:
+		return b.allowFutureWeights(b.opts.DepthFutureWeights)
 	default:
 		return true
 	}
@@ -160,6 +170,11 @@ func (b *BinanceLimiter) allowCreateSpotOrder(uniq string) bool {
 	return limiter.LimiterAllow(b.limiterMap[limiter.SpotCreateOrderLimit], uniq) && b.allowSpotWeights(b.opts.CreateSpotOrderWeights)
 }
 
+// 允许修改现货订单（cancelReplace 计入下单次数）
+func (b *BinanceLimiter) allowUpdateSpotOrder(uniq string) bool {
+	return limiter.LimiterAllow(b.limiterMap[limiter.SpotCreateOrderLimit], uniq) && b.allowSpotWeights(b.opts.UpdateSpotOrderWeights)
+}
+
 // 允许取消现货订单
 func (b *BinanceLimiter) allowCancelSpotOrder(uniq string) bool {
 	return limiter.LimiterAllow(b.limiterMap[limiter.SpotNormalRequestLimit], uniq) && b.allowSpotWeights(b.opts.CancelSpotOrderWeights)
@@ -175,11 +190,21 @@ func (b *BinanceLimiter) allowSpotNormalRequest(uniq string) bool {
 	return limiter.LimiterAllow(b.limiterMap[limiter.SpotNormalRequestLimit], uniq) && b.allowSpotWeights(b.opts.OtherWeights)
 }
 
+// 允许获取现货深度快照
+func (b *BinanceLimiter) allowSpotDepthSnapshot(uniq string) bool {
+	return limiter.LimiterAllow(b.limiterMap[limiter.SpotNormalRequestLimit], uniq) && b.allowSpotWeights(b.opts.DepthSpotWeights)
+}
+
 // 允许创建合约订单
 func (b *BinanceLimiter) allowCreateFutureOrder(uniq string) bool {
 	return limiter.LimiterAllow(b.limiterMap[limiter.FutureCreateOrderLimit], uniq) && b.allowFutureWeights(b.opts.CreateFutureOrderWeights)
 }
 
+// 允许修改合约订单（改单计入下单次数）
+func (b *BinanceLimiter) allowUpdateFutureOrder(uniq string) bool {
+	return limiter.LimiterAllow(b.limiterMap[limiter.FutureCreateOrderLimit], uniq) && b.allowFutureWeights(b.opts.UpdateFutureOrderWeights)
+}
+
 // 允许取消合约订单
 func (b *BinanceLimiter) allCancelFutureOrder() bool {
 	return b.allowFutureWeights(b.opts.CancelFutureOrderWeights)
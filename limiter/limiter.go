@@ -11,6 +11,7 @@ const (
 	NormalRequestLimit  LimitType = "NORMAL_REQUEST"         // 普通请求
 	BorrowOrRepayLimit  LimitType = "BORROW_OR_REPAY"        // 借贷或还款
 	GetMarginInventory  LimitType = "GET_MARGIN_INVENTORY"   // 获取可借贷库存
+	DepthSnapshotLimit  LimitType = "DEPTH_SNAPSHOT"         // 获取深度快照
 )
 
 type LimiterReq struct {
@@ -53,6 +53,8 @@ type Options struct {
 	UpdateSpotOrderWeights    WeightType
 	UpdateFutureOrderWeights  WeightType
 	BorrowOrRepayWeights      WeightType
+	DepthSpotWeights          WeightType
+	DepthFutureWeights        WeightType
 	OtherWeights              WeightType
 }
 
@@ -128,6 +130,18 @@ func WithCreateMarginOrderWeights(w WeightType) Option {
 	}
 }
 
+func WithDepthSpotWeights(w WeightType) Option {
+	return func(o *Options) {
+		o.DepthSpotWeights = w
+	}
+}
+
+func WithDepthFutureWeights(w WeightType) Option {
+	return func(o *Options) {
+		o.DepthFutureWeights = w
+	}
+}
+
 func WithOtherWeights(w WeightType) Option {
 	return func(o *Options) {
 		o.OtherWeights = w
@@ -71,15 +71,15 @@ func NewClient(ops ...Option) *Client {
 	}
 }
 
-// APIError define API error when response status is 4xx or 5xx
+// APIError define API error when response status is 4xx or 5xx, okx returns code as string
 type APIError struct {
-	Code    int64  `json:"code"`
+	Code    string `json:"code"`
 	Message string `json:"msg"`
 }
 
 // Error return error code and message
 func (e APIError) Error() string {
-	return fmt.Sprintf("<APIError> code=%d, msg=%s", e.Code, e.Message)
+	return fmt.Sprintf("<APIError> code=%s, msg=%s", e.Code, e.Message)
 }
 
 // IsAPIError check if e is an API error
@@ -39,10 +39,12 @@ var (
 const (
 	bnSpotWsEndpoint            = "wss://stream.binance.com:9443/ws"
 	bnFuturesWsEndpoint         = "wss://fstream.binance.com/ws"
+	bnDeliveryWsEndpoint        = "wss://dstream.binance.com/ws"
 	bnMarginWsEndpoint          = "wss://stream.binance.com:9443/ws"
 	bnPortfolioMarginWsEndpoint = "wss://fstream.binance.com/pm/ws"
 	bnSpotEndpoint              = "https://api.binance.com"
 	bnFuturesEndpoint           = "https://fapi.binance.com"
+	bnDeliveryEndpoint          = "https://dapi.binance.com"
 	bnPortfolioMarginEndpoint   = "https://papi.binance.com"
 
 	redisKeyPrefix = "binance_listenkey:"
@@ -141,6 +143,8 @@ func (o *of) AddStream(req *streammanager.StreamRequest) ([]string, error) {
 		endpoint = fmt.Sprintf("%s/%s", bnSpotWsEndpoint, key)
 		if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
 			endpoint = fmt.Sprintf("%s/%s", bnFuturesWsEndpoint, key)
+		} else if req.MarketType.IsCoinMargined() {
+			endpoint = fmt.Sprintf("%s/%s", bnDeliveryWsEndpoint, key)
 		} else if req.MarketType == exchange.MarketTypeMargin {
 			endpoint = fmt.Sprintf("%s/%s", bnMarginWsEndpoint, key)
 		}
@@ -293,7 +297,7 @@ func (o *of) createWebsocketHandler(req *streammanager.StreamRequest, rcli *redi
 		switch j.Get("e").MustString() {
 		// 现货杠杠订单更新 | 统一账户杠杆订单更新
 		case "executionReport":
-			if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
+			if req.MarketType.IsContract() {
 				return
 			}
 			o.opts.logger.Debugf("Binance WS订单事件: %s", string(message))
@@ -357,6 +361,10 @@ func (o *of) createWebsocketHandler(req *streammanager.StreamRequest, rcli *redi
 				o.opts.logger.Error("order to order event error", err)
 				return
 			}
+			// 币本位合约订单数量单位为张
+			if req.MarketType.IsCoinMargined() {
+				oe.MarketType = req.MarketType
+			}
 			if req.OrderEvent != nil {
 				req.OrderEvent(oe)
 			}
@@ -455,6 +463,9 @@ func (o *of) generateListenKey(req *streammanager.StreamRequest) (string, error)
 		if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
 			r.Endpoint = "/fapi/v1/listenKey"
 			o.client.SetApiEndpoint(bnFuturesEndpoint)
+		} else if req.MarketType.IsCoinMargined() {
+			r.Endpoint = "/dapi/v1/listenKey"
+			o.client.SetApiEndpoint(bnDeliveryEndpoint)
 		} else if req.MarketType == exchange.MarketTypeSpot {
 			r.Endpoint = "/api/v3/userDataStream"
 			o.client.SetApiEndpoint(bnSpotEndpoint)
@@ -500,6 +511,9 @@ func (o *of) updateListenKey(lk *listenKey) error {
 		} else if lk.MarketType == exchange.MarketTypeFuturesUSDMargined || lk.MarketType == exchange.MarketTypePerpetualUSDMargined {
 			r.Endpoint = "/fapi/v1/listenKey"
 			o.client.SetApiEndpoint(bnFuturesEndpoint)
+		} else if lk.MarketType.IsCoinMargined() {
+			r.Endpoint = "/dapi/v1/listenKey"
+			o.client.SetApiEndpoint(bnDeliveryEndpoint)
 		} else if lk.MarketType == exchange.MarketTypeMargin {
 			r.Endpoint = "/sapi/v1/userDataStream"
 			r.SetFormParam("listenKey", lk.Key)
@@ -196,8 +196,8 @@ func (o *of) login(req *streammanager.StreamRequest, conn websocket.WebSocketCon
 
 func (o *of) subscribe(uuid string, req *streammanager.StreamRequest) error {
 	subList := make([]string, 0)
-	if req.MarketType == exchange.MarketTypeFuturesUSDMargined || req.MarketType == exchange.MarketTypePerpetualUSDMargined {
-		// 如果是合约类型，则添加永续和交割合约
+	if req.MarketType.IsContract() {
+		// 如果是合约类型，则添加永续和交割合约，U 本位和币本位在收到推送时区分
 		subList = append(subList, "SWAP")
 		subList = append(subList, "FUTURES")
 	} else {
@@ -342,15 +342,23 @@ func (o *of) toOrderEvent(message []byte, marketType exchange.MarketType) ([]*ex
 	}
 
 	// 如果是合约，则判断 instType 是否为 FUTURES 或 SWAP
-	if (marketType == exchange.MarketTypeFuturesUSDMargined || marketType == exchange.MarketTypePerpetualUSDMargined) && (event.Arg.InstType != "FUTURES" && event.Arg.InstType != "SWAP") {
+	if marketType.IsContract() && (event.Arg.InstType != "FUTURES" && event.Arg.InstType != "SWAP") {
 		return nil, nil
-	} else if marketType != exchange.MarketTypeFuturesUSDMargined && marketType != exchange.MarketTypePerpetualUSDMargined && string(marketType) != event.Arg.InstType {
+	} else if !marketType.IsContract() && string(marketType) != event.Arg.InstType {
 		// 其他直接判断 instType 是否与 instrument 相等
 		return nil, nil
 	}
 
 	orderResultEvents := make([]*exchange.OrderResultEvent, 0)
 	for _, d := range event.Data {
+		mk := marketType
+		if marketType.IsContract() {
+			mk = okexc.OkxMarketType(event.Arg.InstType, d.InstID)
+			// 只推送与订阅相同本位的合约订单
+			if mk.IsCoinMargined() != marketType.IsCoinMargined() {
+				continue
+			}
+		}
 		price, err := decimal.NewFromString(d.FillPx)
 		if err != nil {
 			price = decimal.Zero
@@ -429,13 +437,6 @@ func (o *of) toOrderEvent(message []byte, marketType exchange.MarketType) ([]*ex
 			filledVolume = decimal.Zero
 		}
 
-		mk := marketType
-		if event.Arg.InstType == "FUTURES" {
-			mk = exchange.MarketTypeFuturesUSDMargined
-		} else if event.Arg.InstType == "SWAP" {
-			mk = exchange.MarketTypePerpetualUSDMargined
-		}
-
 		ore := &exchange.OrderResultEvent{
 			PositionSide:      okexc.OkxTPositionSide(d.PosSide),
 			Exchange:          exchange.OkxExchange,
@@ -0,0 +1,108 @@
+package streampaper
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/paexc"
+	"github.com/go-gotop/kit/streammanager"
+	"github.com/google/uuid"
+)
+
+var _ streammanager.StreamManager = (*of)(nil)
+
+// NewPaperStream 将模拟交易所的订单及账户事件以 StreamManager 的形式推送
+func NewPaperStream(ex *paexc.PaperExchange) streammanager.StreamManager {
+	return &of{
+		name:    exchange.PaperExchange,
+		ex:      ex,
+		streams: make(map[string]*stream),
+	}
+}
+
+type stream struct {
+	uuid       string
+	accountID  string
+	apiKey     string
+	marketType exchange.MarketType
+}
+
+type of struct {
+	name    string
+	ex      *paexc.PaperExchange
+	streams map[string]*stream
+	mux     sync.Mutex
+}
+
+func (o *of) Name() string {
+	return o.name
+}
+
+func (o *of) AddStream(req *streammanager.StreamRequest) ([]string, error) {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	id := uuid.New().String()
+	accountID := req.AccountId
+	o.ex.Subscribe(id, &paexc.Listener{
+		MarketType: req.MarketType,
+		OrderEvent: func(evt *exchange.OrderResultEvent) {
+			if req.OrderEvent == nil {
+				return
+			}
+			evt.AccountID = accountID
+			req.OrderEvent(evt)
+		},
+		AccountEvent: req.AccountEvent,
+	})
+
+	o.streams[id] = &stream{
+		uuid:       id,
+		accountID:  req.AccountId,
+		apiKey:     req.APIKey,
+		marketType: req.MarketType,
+	}
+	return []string{id}, nil
+}
+
+func (o *of) CloseStream(accountId string, marketType exchange.MarketType, uuid string) error {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	if _, ok := o.streams[uuid]; !ok {
+		return errors.New("stream not found")
+	}
+	o.ex.Unsubscribe(uuid)
+	delete(o.streams, uuid)
+	return nil
+}
+
+func (o *of) StreamList() []streammanager.Stream {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	list := make([]streammanager.Stream, 0, len(o.streams))
+	for _, v := range o.streams {
+		list = append(list, streammanager.Stream{
+			UUID:        v.uuid,
+			AccountId:   v.accountID,
+			APIKey:      v.apiKey,
+			Exchange:    o.name,
+			MarketType:  v.marketType,
+			IsConnected: true,
+		})
+	}
+	return list
+}
+
+func (o *of) Shutdown() error {
+	o.mux.Lock()
+	defer o.mux.Unlock()
+
+	for id := range o.streams {
+		o.ex.Unsubscribe(id)
+	}
+	o.streams = make(map[string]*stream)
+	return nil
+}
@@ -0,0 +1,24 @@
+package symbolmanager
+
+import (
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+type Option func(*options)
+
+type options struct {
+	logger      *log.Helper
+	quoteAssets []string // 计价资产，产品更新推送中出现新交易对时用于拆分基础资产和计价资产
+}
+
+func WithLogger(logger *log.Helper) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func WithQuoteAssets(assets ...string) Option {
+	return func(o *options) {
+		o.quoteAssets = assets
+	}
+}
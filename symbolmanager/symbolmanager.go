@@ -0,0 +1,275 @@
+package symbolmanager
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-gotop/kit/dfmanager"
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/google/uuid"
+)
+
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// SymbolManager 交易对缓存，负责交易所原始名称与统一名称之间的转换
+type SymbolManager interface {
+	// Load 从交易所拉取交易对，覆盖对应市场类型的缓存
+	Load(ctx context.Context, ex exchange.Exchange, marketTypes ...exchange.MarketType) error
+	// Watch 订阅 dfmanager 产品更新推送，保持缓存最新
+	Watch(exchangeName string, df dfmanager.DataFeedManager, marketTypes ...exchange.MarketType) error
+	// Update 应用产品更新事件
+	Update(exchangeName string, marketType exchange.MarketType, events []*exchange.SymbolUpdateEvent)
+	// Get 按交易所原始名称获取交易对
+	Get(exchangeName string, marketType exchange.MarketType, symbol string) (exchange.Symbol, error)
+	// GetByUnified 按统一名称获取交易对
+	GetByUnified(exchangeName string, marketType exchange.MarketType, unifiedSymbol string) (exchange.Symbol, error)
+	// ToUnified 原始名称转换为统一名称
+	ToUnified(exchangeName string, marketType exchange.MarketType, symbol string) (string, error)
+	// ToOriginal 统一名称转换为原始名称
+	ToOriginal(exchangeName string, marketType exchange.MarketType, unifiedSymbol string) (string, error)
+	// Symbols 获取缓存中的全部交易对，按原始名称排序
+	Symbols(exchangeName string, marketType exchange.MarketType) []exchange.Symbol
+	// Close 关闭 Watch 创建的数据流
+	Close() error
+}
+
+func NewSymbolManager(opts ...Option) SymbolManager {
+	o := &options{
+		logger:      log.NewHelper(log.DefaultLogger),
+		quoteAssets: []string{"USDT", "USDC", "FDUSD", "BUSD", "BTC", "ETH", "BNB"},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &symbolManager{
+		opts:      o,
+		bySymbol:  make(map[string]*exchange.Symbol),
+		byUnified: make(map[string]*exchange.Symbol),
+		feeds:     make(map[string]dfmanager.DataFeedManager),
+	}
+}
+
+type symbolManager struct {
+	opts *options
+	mux  sync.RWMutex
+
+	bySymbol  map[string]*exchange.Symbol // exchange:marketType:symbol
+	byUnified map[string]*exchange.Symbol // exchange:marketType:unifiedSymbol
+	feeds     map[string]dfmanager.DataFeedManager
+}
+
+func (m *symbolManager) Load(ctx context.Context, ex exchange.Exchange, marketTypes ...exchange.MarketType) error {
+	for _, mt := range marketTypes {
+		symbols, err := ex.Symbols(ctx, mt)
+		if err != nil {
+			return err
+		}
+		m.mux.Lock()
+		m.removeLocked(ex.Name(), mt)
+		for i := range symbols {
+			s := symbols[i]
+			s.Exchange = ex.Name()
+			s.MarketType = mt
+			m.putLocked(&s)
+		}
+		m.mux.Unlock()
+	}
+	return nil
+}
+
+func (m *symbolManager) Watch(exchangeName string, df dfmanager.DataFeedManager, marketTypes ...exchange.MarketType) error {
+	for _, mt := range marketTypes {
+		id := uuid.New().String()
+		marketType := mt
+		err := df.AddSymbolUpdateDataFeed(&dfmanager.SymbolUpdateRequest{
+			ID:         id,
+			MarketType: marketType,
+			Event: func(data []*exchange.SymbolUpdateEvent) {
+				m.Update(exchangeName, marketType, data)
+			},
+			ErrorHandler: func(err error) {
+				m.opts.logger.Errorf("symbol update data feed error: %v", err)
+			},
+		})
+		if err != nil {
+			return err
+		}
+		m.mux.Lock()
+		m.feeds[id] = df
+		m.mux.Unlock()
+	}
+	return nil
+}
+
+func (m *symbolManager) Update(exchangeName string, marketType exchange.MarketType, events []*exchange.SymbolUpdateEvent) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, e := range events {
+		marketType := marketType
+		if e.MarketType != "" {
+			marketType = e.MarketType
+		}
+		s, ok := m.bySymbol[symbolKey(exchangeName, marketType, e.OriginalSymbol)]
+		if !ok {
+			base, quote, err := m.splitSymbol(e.OriginalSymbol, e.OriginalAsset)
+			if err != nil {
+				m.opts.logger.Warnf("skip symbol update %s: %v", e.OriginalSymbol, err)
+				continue
+			}
+			s = &exchange.Symbol{
+				OriginalSymbol: e.OriginalSymbol,
+				OriginalAsset:  base,
+				UnifiedAsset:   base,
+				QuoteAsset:     quote,
+				Exchange:       exchangeName,
+				MarketType:     marketType,
+			}
+		}
+		updated := *s
+		updated.UnifiedSymbol = exchange.UnifiedSymbolName(updated.OriginalAsset, updated.QuoteAsset, marketType, e.ExpTime)
+		updated.MinSize = e.MinSize
+		updated.MaxSize = e.MaxSize
+		if !e.MinPrice.IsZero() {
+			updated.MinPrice = e.MinPrice
+		}
+		if !e.MaxPrice.IsZero() {
+			updated.MaxPrice = e.MaxPrice
+		}
+		updated.PricePrecision = e.PricePrecision
+		updated.SizePrecision = e.SizePrecision
+		updated.TickSize = e.TickSize
+		updated.StepSize = e.StepSize
+		updated.CtVal = e.CtVal
+		updated.CtMult = e.CtMult
+		updated.ListTime = e.ListTime
+		updated.ExpTime = e.ExpTime
+		updated.Status = exchange.SymbolStatusDisabled
+		if e.State == "live" {
+			updated.Status = exchange.SymbolStatusEnabled
+		}
+		if ok {
+			delete(m.byUnified, symbolKey(exchangeName, marketType, s.UnifiedSymbol))
+		}
+		m.putLocked(&updated)
+	}
+}
+
+func (m *symbolManager) Get(exchangeName string, marketType exchange.MarketType, symbol string) (exchange.Symbol, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	s, ok := m.bySymbol[symbolKey(exchangeName, marketType, symbol)]
+	if !ok {
+		return exchange.Symbol{}, ErrSymbolNotFound
+	}
+	return *s, nil
+}
+
+func (m *symbolManager) GetByUnified(exchangeName string, marketType exchange.MarketType, unifiedSymbol string) (exchange.Symbol, error) {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	s, ok := m.byUnified[symbolKey(exchangeName, marketType, strings.ToUpper(unifiedSymbol))]
+	if !ok {
+		return exchange.Symbol{}, ErrSymbolNotFound
+	}
+	return *s, nil
+}
+
+func (m *symbolManager) ToUnified(exchangeName string, marketType exchange.MarketType, symbol string) (string, error) {
+	s, err := m.Get(exchangeName, marketType, symbol)
+	if err != nil {
+		return "", err
+	}
+	return s.UnifiedSymbol, nil
+}
+
+func (m *symbolManager) ToOriginal(exchangeName string, marketType exchange.MarketType, unifiedSymbol string) (string, error) {
+	s, err := m.GetByUnified(exchangeName, marketType, unifiedSymbol)
+	if err != nil {
+		return "", err
+	}
+	return s.OriginalSymbol, nil
+}
+
+func (m *symbolManager) Symbols(exchangeName string, marketType exchange.MarketType) []exchange.Symbol {
+	m.mux.RLock()
+	defer m.mux.RUnlock()
+
+	prefix := symbolKey(exchangeName, marketType, "")
+	result := make([]exchange.Symbol, 0)
+	for k, s := range m.bySymbol {
+		if strings.HasPrefix(k, prefix) {
+			result = append(result, *s)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].OriginalSymbol < result[j].OriginalSymbol
+	})
+	return result
+}
+
+func (m *symbolManager) Close() error {
+	m.mux.Lock()
+	feeds := m.feeds
+	m.feeds = make(map[string]dfmanager.DataFeedManager)
+	m.mux.Unlock()
+
+	var errs []error
+	for id, df := range feeds {
+		if err := df.CloseDataFeed(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *symbolManager) putLocked(s *exchange.Symbol) {
+	m.bySymbol[symbolKey(s.Exchange, s.MarketType, s.OriginalSymbol)] = s
+	if s.UnifiedSymbol != "" {
+		m.byUnified[symbolKey(s.Exchange, s.MarketType, s.UnifiedSymbol)] = s
+	}
+}
+
+func (m *symbolManager) removeLocked(exchangeName string, marketType exchange.MarketType) {
+	prefix := symbolKey(exchangeName, marketType, "")
+	for k := range m.bySymbol {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.bySymbol, k)
+		}
+	}
+	for k := range m.byUnified {
+		if strings.HasPrefix(k, prefix) {
+			delete(m.byUnified, k)
+		}
+	}
+}
+
+// splitSymbol 拆分新交易对的基础资产和计价资产，支持 BTC-USDT-SWAP 和 BTCUSDT 两种格式
+func (m *symbolManager) splitSymbol(symbol, asset string) (string, string, error) {
+	name := strings.ToUpper(symbol)
+	if parts := strings.Split(name, "-"); len(parts) >= 2 {
+		return parts[0], parts[1], nil
+	}
+	// 币安交割合约如 BTCUSDT_250328
+	name, _, _ = strings.Cut(name, "_")
+	asset = strings.ToUpper(asset)
+	if asset != "" && asset != name && strings.HasPrefix(name, asset) {
+		return asset, strings.TrimPrefix(name, asset), nil
+	}
+	for _, quote := range m.opts.quoteAssets {
+		if quote != name && strings.HasSuffix(name, quote) {
+			return strings.TrimSuffix(name, quote), quote, nil
+		}
+	}
+	return "", "", errors.New("unknown quote asset")
+}
+
+func symbolKey(exchangeName string, marketType exchange.MarketType, symbol string) string {
+	return exchangeName + ":" + string(marketType) + ":" + symbol
+}
@@ -0,0 +1,36 @@
+package symbolmanager
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-gotop/kit/exchange"
+	"github.com/go-gotop/kit/exchange/paexc"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAndUpdate(t *testing.T) {
+	ex := paexc.NewPaperExchange()
+	ex.OnTrade(&exchange.TradeEvent{Symbol: "BTCUSDT", Price: decimal.NewFromInt(100), Size: decimal.NewFromInt(1), MarketType: exchange.MarketTypePerpetualUSDMargined})
+
+	m := NewSymbolManager()
+	assert.NoError(t, m.Load(context.Background(), ex, exchange.MarketTypePerpetualUSDMargined))
+
+	unified, err := m.ToUnified(exchange.PaperExchange, exchange.MarketTypePerpetualUSDMargined, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, "BTC-USDT-PERP", unified)
+
+	m.Update(exchange.OkxExchange, exchange.MarketTypePerpetualUSDMargined, []*exchange.SymbolUpdateEvent{
+		{OriginalSymbol: "ETH-USDT-SWAP", OriginalAsset: "ETH", CtVal: decimal.NewFromFloat(0.1), SizePrecision: 2, State: "live"},
+	})
+	s, err := m.GetByUnified(exchange.OkxExchange, exchange.MarketTypePerpetualUSDMargined, "eth-usdt-perp")
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH-USDT-SWAP", s.OriginalSymbol)
+	assert.Equal(t, exchange.SymbolStatusEnabled, s.Status)
+	assert.True(t, decimal.NewFromFloat(0.1).Equal(s.CtVal))
+
+	_, err = m.ToOriginal(exchange.OkxExchange, exchange.MarketTypeSpot, "ETH-USDT")
+	assert.ErrorIs(t, err, ErrSymbolNotFound)
+	assert.Len(t, m.Symbols(exchange.OkxExchange, exchange.MarketTypePerpetualUSDMargined), 1)
+}